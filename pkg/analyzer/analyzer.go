@@ -50,6 +50,9 @@ func (a *Analyzer) Analyze(stmt parser.Statement) QueryAnalysis {
 	case *parser.SelectStatement:
 		a.analyzeSelectStatement(s)
 		a.analysis.QueryType = "SELECT"
+	case *parser.SetOperation:
+		a.analyzeSetOperation(s)
+		a.analysis.QueryType = "SELECT"
 	case *parser.InsertStatement:
 		a.analyzeInsertStatement(s)
 		a.analysis.QueryType = "INSERT"
@@ -59,6 +62,27 @@ func (a *Analyzer) Analyze(stmt parser.Statement) QueryAnalysis {
 	case *parser.DeleteStatement:
 		a.analyzeDeleteStatement(s)
 		a.analysis.QueryType = "DELETE"
+	case *parser.MergeStatement:
+		a.analyzeMergeStatement(s)
+		a.analysis.QueryType = "MERGE"
+	case *parser.CreateTableStatement:
+		a.analyzeCreateTableStatement(s)
+		a.analysis.QueryType = "CREATE_TABLE"
+	case *parser.AlterTableStatement:
+		a.analyzeAlterTableStatement(s)
+		a.analysis.QueryType = "ALTER_TABLE"
+	case *parser.CreateViewStatement:
+		a.analyzeCreateViewStatement(s)
+		a.analysis.QueryType = "CREATE_VIEW"
+	case *parser.CreateIndexStatement:
+		a.analyzeCreateIndexStatement(s)
+		a.analysis.QueryType = "CREATE_INDEX"
+	case *parser.DropStatement:
+		a.analyzeDropStatement(s)
+		a.analysis.QueryType = "DROP"
+	case *parser.PermissionStatement:
+		a.analyzePermissionStatement(s)
+		a.analysis.QueryType = s.Action
 	}
 
 	a.analysis.Complexity = a.calculateComplexity()
@@ -87,52 +111,102 @@ func (a *Analyzer) AnalyzeWithCache(stmt parser.Statement, cacheKey string) Quer
 }
 
 func (a *Analyzer) analyzeSelectStatement(stmt *parser.SelectStatement) {
-	if stmt.From != nil {
-		for _, table := range stmt.From.Tables {
+	a.analyzeFromAndJoins(stmt.From, stmt.Joins)
+
+	for _, col := range stmt.Columns {
+		a.analyzeExpression(col, "SELECT")
+	}
+
+	// SELECT ... INTO creates and fills a new table
+	if stmt.Into != nil {
+		a.analysis.Tables = append(a.analysis.Tables, TableInfo{
+			Schema: stmt.Into.Schema,
+			Name:   stmt.Into.Name,
+			Usage:  "INSERT",
+		})
+	}
+
+	if stmt.Where != nil {
+		a.analyzeExpression(stmt.Where, "WHERE")
+	}
+
+	if stmt.GroupBy != nil {
+		for _, expr := range stmt.GroupBy.Items {
+			a.analyzeExpression(expr, "GROUP_BY")
+		}
+	}
+
+	if stmt.Having != nil {
+		a.analyzeExpression(stmt.Having, "HAVING")
+	}
+
+	for _, orderBy := range stmt.OrderBy {
+		a.analyzeExpression(orderBy.Expression, "ORDER_BY")
+	}
+}
+
+func (a *Analyzer) analyzeSetOperation(stmt *parser.SetOperation) {
+	for _, side := range []parser.Statement{stmt.Left, stmt.Right} {
+		switch s := side.(type) {
+		case *parser.SelectStatement:
+			a.analyzeSelectStatement(s)
+		case *parser.SetOperation:
+			a.analyzeSetOperation(s)
+		}
+	}
+}
+
+// analyzeFromAndJoins records the tables and join conditions of a FROM clause
+func (a *Analyzer) analyzeFromAndJoins(from *parser.FromClause, joins []*parser.JoinClause) {
+	if from != nil {
+		for _, table := range from.Tables {
 			a.analysis.Tables = append(a.analysis.Tables, TableInfo{
 				Schema: table.Schema,
 				Name:   table.Name,
 				Alias:  table.Alias,
 				Usage:  "SELECT",
 			})
+			a.analyzePivot(&table)
 		}
 	}
 
-	for _, join := range stmt.Joins {
+	for _, join := range joins {
 		a.analysis.Tables = append(a.analysis.Tables, TableInfo{
 			Schema: join.Table.Schema,
 			Name:   join.Table.Name,
 			Alias:  join.Table.Alias,
 			Usage:  "SELECT",
 		})
+		a.analyzePivot(&join.Table)
 
-		a.analysis.Joins = append(a.analysis.Joins, JoinInfo{
+		joinInfo := JoinInfo{
 			Type:       join.JoinType,
 			RightTable: join.Table.Name,
-			Condition:  join.Condition.String(),
-		})
-
-		a.analyzeExpression(join.Condition, "JOIN")
-	}
-
-	for _, col := range stmt.Columns {
-		a.analyzeExpression(col, "SELECT")
-	}
+		}
 
-	if stmt.Where != nil {
-		a.analyzeExpression(stmt.Where, "WHERE")
-	}
+		// CROSS JOIN has no condition
+		if join.Condition != nil {
+			joinInfo.Condition = join.Condition.String()
+			a.analyzeExpression(join.Condition, "JOIN")
+		}
 
-	for _, expr := range stmt.GroupBy {
-		a.analyzeExpression(expr, "GROUP_BY")
+		a.analysis.Joins = append(a.analysis.Joins, joinInfo)
 	}
+}
 
-	if stmt.Having != nil {
-		a.analyzeExpression(stmt.Having, "HAVING")
+// analyzePivot records the source columns read by a PIVOT or UNPIVOT
+func (a *Analyzer) analyzePivot(table *parser.TableReference) {
+	if table.Pivot != nil {
+		a.analyzeExpression(table.Pivot.Aggregate, "PIVOT")
+		a.analyzeExpression(table.Pivot.Column, "PIVOT")
 	}
-
-	for _, orderBy := range stmt.OrderBy {
-		a.analyzeExpression(orderBy.Expression, "ORDER_BY")
+	if table.Unpivot != nil {
+		for _, col := range table.Unpivot.Columns {
+			a.analysis.Columns = append(a.analysis.Columns, ColumnInfo{
+				Name:  col,
+				Usage: "UNPIVOT",
+			})
+		}
 	}
 }
 
@@ -156,6 +230,14 @@ func (a *Analyzer) analyzeExpression(expr parser.Expression, usage string) {
 		for _, arg := range e.Arguments {
 			a.analyzeExpression(arg, usage)
 		}
+		if e.Over != nil {
+			for _, expr := range e.Over.PartitionBy {
+				a.analyzeExpression(expr, usage)
+			}
+			for _, orderBy := range e.Over.OrderBy {
+				a.analyzeExpression(orderBy.Expression, usage)
+			}
+		}
 	case *parser.StarExpression:
 		a.analysis.Columns = append(a.analysis.Columns, ColumnInfo{
 			Table: e.Table,
@@ -164,17 +246,94 @@ func (a *Analyzer) analyzeExpression(expr parser.Expression, usage string) {
 		})
 	case *parser.UnaryExpression:
 		a.analyzeExpression(e.Operand, usage)
+	case *parser.AliasedExpression:
+		a.analyzeExpression(e.Expression, usage)
+	case *parser.CastExpression:
+		a.analyzeExpression(e.Expression, usage)
+	case *parser.RollupExpression:
+		a.analyzeGroupings(e.Groups, usage)
+	case *parser.CubeExpression:
+		a.analyzeGroupings(e.Groups, usage)
+	case *parser.GroupingSetsExpression:
+		a.analyzeGroupings(e.Sets, usage)
 	case *parser.InExpression:
 		a.analyzeExpression(e.Expression, usage)
 		for _, val := range e.Values {
 			a.analyzeExpression(val, usage)
 		}
+	case *parser.RowValueExpression:
+		for _, val := range e.Values {
+			a.analyzeExpression(val, usage)
+		}
+	case *parser.CaseExpression:
+		if e.Operand != nil {
+			a.analyzeExpression(e.Operand, usage)
+		}
+		for _, when := range e.WhenClauses {
+			a.analyzeExpression(when.Condition, usage)
+			a.analyzeExpression(when.Result, usage)
+		}
+		if e.Else != nil {
+			a.analyzeExpression(e.Else, usage)
+		}
+	case *parser.IsNullExpression:
+		a.analyzeExpression(e.Expr, usage)
+	case *parser.BetweenExpression:
+		a.analyzeExpression(e.Expr, usage)
+		a.analyzeExpression(e.Lower, usage)
+		a.analyzeExpression(e.Upper, usage)
+	case *parser.LikeExpression:
+		a.analyzeExpression(e.Expr, usage)
+		a.analyzeExpression(e.Pattern, usage)
+		if e.Escape != nil {
+			a.analyzeExpression(e.Escape, usage)
+		}
+
+		if usage == "WHERE" || usage == "HAVING" || usage == "JOIN" {
+			a.extractLikeCondition(e)
+		}
+	case *parser.CollateExpression:
+		a.analyzeExpression(e.Expr, usage)
+	case *parser.FullTextPredicate:
+		for _, col := range e.Columns {
+			a.analyzeExpression(col, usage)
+		}
+		a.analyzeExpression(e.Search, usage)
+	}
+}
+
+func (a *Analyzer) extractLikeCondition(expr *parser.LikeExpression) {
+	operator := "LIKE"
+	if expr.Not {
+		operator = "NOT LIKE"
+	}
+	if col, ok := expr.Expr.(*parser.ColumnReference); ok {
+		if pattern, ok := expr.Pattern.(*parser.Literal); ok {
+			a.analysis.Conditions = append(a.analysis.Conditions, ConditionInfo{
+				Table:    col.Table,
+				Column:   col.Column,
+				Operator: operator,
+				Value:    fmt.Sprintf("%v", pattern.Value),
+			})
+		}
+	}
+}
+
+func (a *Analyzer) analyzeGroupings(groups [][]parser.Expression, usage string) {
+	for _, group := range groups {
+		for _, expr := range group {
+			a.analyzeExpression(expr, usage)
+		}
 	}
 }
 
 func (a *Analyzer) extractCondition(expr *parser.BinaryExpression, _ string) {
 	// Try to extract simple conditions like column = value
-	if leftCol, ok := expr.Left.(*parser.ColumnReference); ok {
+	left := expr.Left
+	if collate, ok := left.(*parser.CollateExpression); ok {
+		left = collate.Expr
+	}
+	if leftCol, ok := left.(*parser.ColumnReference); ok {
 		if rightLit, ok := expr.Right.(*parser.Literal); ok {
 			a.analysis.Conditions = append(a.analysis.Conditions, ConditionInfo{
 				Table:    leftCol.Table,
@@ -201,6 +360,13 @@ func (a *Analyzer) analyzeInsertStatement(stmt *parser.InsertStatement) {
 			Usage: "INSERT",
 		})
 	}
+
+	a.analyzeOutputClause(stmt.Output)
+
+	// Analyze the source query of INSERT ... SELECT
+	if stmt.Source != nil {
+		a.analyzeSelectStatement(stmt.Source)
+	}
 }
 
 func (a *Analyzer) analyzeUpdateStatement(stmt *parser.UpdateStatement) {
@@ -214,12 +380,18 @@ func (a *Analyzer) analyzeUpdateStatement(stmt *parser.UpdateStatement) {
 	// Analyze SET clause
 	for _, assignment := range stmt.Set {
 		a.analysis.Columns = append(a.analysis.Columns, ColumnInfo{
-			Name:  assignment.Column,
+			Table: assignment.Column.Table,
+			Name:  assignment.Column.Column,
 			Usage: "UPDATE",
 		})
 		a.analyzeExpression(assignment.Value, "UPDATE")
 	}
 
+	a.analyzeOutputClause(stmt.Output)
+
+	// Analyze UPDATE ... FROM source tables
+	a.analyzeFromAndJoins(stmt.From, stmt.Joins)
+
 	// Analyze WHERE clause
 	if stmt.Where != nil {
 		a.analyzeExpression(stmt.Where, "WHERE")
@@ -234,12 +406,201 @@ func (a *Analyzer) analyzeDeleteStatement(stmt *parser.DeleteStatement) {
 		Usage:  "DELETE",
 	})
 
+	a.analyzeOutputClause(stmt.Output)
+
 	// Analyze WHERE clause
 	if stmt.Where != nil {
 		a.analyzeExpression(stmt.Where, "WHERE")
 	}
 }
 
+// analyzeOutputClause records the columns returned by an OUTPUT clause and
+// the table it inserts them into
+func (a *Analyzer) analyzeOutputClause(output *parser.OutputClause) {
+	if output == nil {
+		return
+	}
+
+	for _, col := range output.Columns {
+		a.analyzeExpression(col, "OUTPUT")
+	}
+
+	if output.Into != nil {
+		a.analysis.Tables = append(a.analysis.Tables, TableInfo{
+			Schema: output.Into.Schema,
+			Name:   output.Into.Name,
+			Usage:  "INSERT",
+		})
+		for _, col := range output.IntoColumns {
+			a.analysis.Columns = append(a.analysis.Columns, ColumnInfo{
+				Table: output.Into.Name,
+				Name:  col,
+				Usage: "INSERT",
+			})
+		}
+	}
+}
+
+func (a *Analyzer) analyzeMergeStatement(stmt *parser.MergeStatement) {
+	a.analysis.Tables = append(a.analysis.Tables, TableInfo{
+		Schema: stmt.Target.Schema,
+		Name:   stmt.Target.Name,
+		Alias:  stmt.Target.Alias,
+		Usage:  "MERGE",
+	})
+
+	// The USING source is read like a joined table
+	if stmt.Source.Subquery != nil {
+		a.analyzeSelectStatement(stmt.Source.Subquery)
+	} else {
+		a.analysis.Tables = append(a.analysis.Tables, TableInfo{
+			Schema: stmt.Source.Schema,
+			Name:   stmt.Source.Name,
+			Alias:  stmt.Source.Alias,
+			Usage:  "SELECT",
+		})
+	}
+	a.analyzeExpression(stmt.Condition, "JOIN")
+
+	for _, clause := range stmt.Clauses {
+		if clause.Condition != nil {
+			a.analyzeExpression(clause.Condition, "WHERE")
+		}
+		for _, assignment := range clause.Set {
+			a.analysis.Columns = append(a.analysis.Columns, ColumnInfo{
+				Table: assignment.Column.Table,
+				Name:  assignment.Column.Column,
+				Usage: "UPDATE",
+			})
+			a.analyzeExpression(assignment.Value, "UPDATE")
+		}
+		for _, col := range clause.Columns {
+			a.analysis.Columns = append(a.analysis.Columns, ColumnInfo{
+				Name:  col,
+				Usage: "INSERT",
+			})
+		}
+		for _, value := range clause.Values {
+			a.analyzeExpression(value, "INSERT")
+		}
+	}
+}
+
+func (a *Analyzer) analyzeCreateTableStatement(stmt *parser.CreateTableStatement) {
+	a.analysis.Tables = append(a.analysis.Tables, TableInfo{
+		Schema: stmt.Table.Schema,
+		Name:   stmt.Table.Name,
+		Usage:  "CREATE",
+	})
+
+	// Tables referenced by foreign keys
+	constraints := append([]*parser.TableConstraint{}, stmt.Constraints...)
+	for _, column := range stmt.Columns {
+		constraints = append(constraints, column.Constraints...)
+	}
+	for _, constraint := range constraints {
+		if constraint.References != nil {
+			a.analysis.Tables = append(a.analysis.Tables, TableInfo{
+				Schema: constraint.References.Schema,
+				Name:   constraint.References.Name,
+				Usage:  "REFERENCES",
+			})
+		}
+	}
+}
+
+func (a *Analyzer) analyzeAlterTableStatement(stmt *parser.AlterTableStatement) {
+	a.analysis.Tables = append(a.analysis.Tables, TableInfo{
+		Schema: stmt.Table.Schema,
+		Name:   stmt.Table.Name,
+		Usage:  "ALTER",
+	})
+
+	if stmt.Constraint != nil && stmt.Constraint.References != nil {
+		a.analysis.Tables = append(a.analysis.Tables, TableInfo{
+			Schema: stmt.Constraint.References.Schema,
+			Name:   stmt.Constraint.References.Name,
+			Usage:  "REFERENCES",
+		})
+	}
+}
+
+func (a *Analyzer) analyzeCreateViewStatement(stmt *parser.CreateViewStatement) {
+	a.analysis.Tables = append(a.analysis.Tables, TableInfo{
+		Schema: stmt.View.Schema,
+		Name:   stmt.View.Name,
+		Usage:  "CREATE",
+	})
+
+	switch query := stmt.Query.(type) {
+	case *parser.SelectStatement:
+		a.analyzeSelectStatement(query)
+	case *parser.SetOperation:
+		a.analyzeSetOperation(query)
+	}
+}
+
+func (a *Analyzer) analyzeCreateIndexStatement(stmt *parser.CreateIndexStatement) {
+	a.analysis.Tables = append(a.analysis.Tables, TableInfo{
+		Schema: stmt.Table.Schema,
+		Name:   stmt.Table.Name,
+		Usage:  "INDEX",
+	})
+
+	for _, column := range stmt.Columns {
+		a.analysis.Columns = append(a.analysis.Columns, ColumnInfo{
+			Table: stmt.Table.Name,
+			Name:  column.Name,
+			Usage: "INDEX",
+		})
+	}
+	for _, column := range stmt.Include {
+		a.analysis.Columns = append(a.analysis.Columns, ColumnInfo{
+			Table: stmt.Table.Name,
+			Name:  column,
+			Usage: "INCLUDE",
+		})
+	}
+	if stmt.Where != nil {
+		a.analyzeExpression(stmt.Where, "WHERE")
+	}
+}
+
+func (a *Analyzer) analyzeDropStatement(stmt *parser.DropStatement) {
+	// Only tables and views are reported; index and procedure names are not tables
+	switch stmt.ObjectType {
+	case "TABLE", "VIEW":
+		for _, object := range stmt.Objects {
+			a.analysis.Tables = append(a.analysis.Tables, TableInfo{
+				Schema: object.Schema,
+				Name:   object.Name,
+				Usage:  "DROP",
+			})
+		}
+	case "INDEX":
+		if stmt.Table != nil {
+			a.analysis.Tables = append(a.analysis.Tables, TableInfo{
+				Schema: stmt.Table.Schema,
+				Name:   stmt.Table.Name,
+				Usage:  "DROP",
+			})
+		}
+	}
+}
+
+// analyzePermissionStatement reports the object of a GRANT, DENY or REVOKE.
+// Schemas and other securable classes are not tables.
+func (a *Analyzer) analyzePermissionStatement(stmt *parser.PermissionStatement) {
+	if stmt.Object == nil || (stmt.ObjectClass != "" && stmt.ObjectClass != "OBJECT") {
+		return
+	}
+	a.analysis.Tables = append(a.analysis.Tables, TableInfo{
+		Schema: stmt.Object.Schema,
+		Name:   stmt.Object.Name,
+		Usage:  stmt.Action,
+	})
+}
+
 func (a *Analyzer) calculateComplexity() int {
 	complexity := 0
 
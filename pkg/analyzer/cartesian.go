@@ -0,0 +1,163 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+// Warning is a likely mistake found in a query
+type Warning struct {
+	Type      string   `json:"type"`
+	Message   string   `json:"message"`
+	Tables    []string `json:"tables,omitempty"`
+	Predicate string   `json:"predicate,omitempty"` // the predicate at fault, if any
+}
+
+// WarningCartesianProduct is the Type of warnings from DetectCartesianProducts
+const WarningCartesianProduct = "CARTESIAN_PRODUCT"
+
+// DetectCartesianProducts flags accidental cross joins in stmt and the queries
+// nested in it:
+//
+//   - a comma-separated FROM table that no WHERE or ON predicate relates to
+//     the tables before it
+//   - an INNER JOIN whose ON condition doesn't reference both the joined
+//     table and a table before it
+//
+// Explicit CROSS JOINs and APPLYs are intentional and not flagged. When a
+// query has more than one table, only qualified columns (t.col) can be
+// attributed to a table. Tables are named by alias where one is given.
+func DetectCartesianProducts(stmt *parser.SelectStatement) []Warning {
+	if stmt == nil {
+		return nil
+	}
+
+	var warnings []Warning
+	parser.Inspect(stmt, func(node parser.Node) bool {
+		if query, ok := node.(*parser.SelectStatement); ok {
+			warnings = append(warnings, cartesianProducts(query)...)
+		}
+		return true
+	})
+	return warnings
+}
+
+// cartesianProducts checks the FROM clause and joins of a single query
+func cartesianProducts(stmt *parser.SelectStatement) []Warning {
+	names := sourceNames(stmt)
+	if len(names) < 2 {
+		return nil
+	}
+
+	// Sources are numbered FROM tables first, then joins, as sourceNames lists them
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[strings.ToLower(name)] = i
+	}
+	// Also accept the bare table name as a qualifier of an aliased table
+	addName := func(name string, i int) {
+		if _, ok := index[strings.ToLower(name)]; !ok && name != "" {
+			index[strings.ToLower(name)] = i
+		}
+	}
+	fromCount := 0
+	if stmt.From != nil {
+		fromCount = len(stmt.From.Tables)
+		for i := range stmt.From.Tables {
+			addName(stmt.From.Tables[i].Name, i)
+		}
+	}
+	for i, join := range stmt.Joins {
+		addName(join.Table.Name, fromCount+i)
+	}
+
+	parent := make([]int, len(names))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	link := func(sources []int) {
+		for i := 1; i < len(sources); i++ {
+			parent[find(sources[i])] = find(sources[0])
+		}
+	}
+
+	var warnings []Warning
+	for i, join := range stmt.Joins {
+		right := fromCount + i
+		if join.Condition == nil {
+			continue
+		}
+		sources := referencedSources(join.Condition, index)
+		link(sources)
+		if join.JoinType == "INNER" && !referencesBothSides(sources, right) {
+			warnings = append(warnings, Warning{
+				Type:    WarningCartesianProduct,
+				Message: fmt.Sprintf("ON condition of join to %s doesn't relate it to the preceding tables", names[right]),
+				Tables:  append([]string(nil), names[:right+1]...),
+			})
+		}
+	}
+	for _, conjunct := range parser.ToConjunctiveList(stmt.Where) {
+		link(referencedSources(conjunct, index))
+	}
+
+	// A comma-separated table must be connected to one of the tables before it
+	for i := 1; i < fromCount; i++ {
+		connected := false
+		for j := 0; j < i && !connected; j++ {
+			connected = find(i) == find(j)
+		}
+		if !connected {
+			warnings = append(warnings, Warning{
+				Type:    WarningCartesianProduct,
+				Message: fmt.Sprintf("no join predicate relates %s to the preceding tables", names[i]),
+				Tables:  append([]string(nil), names[:i+1]...),
+			})
+		}
+	}
+	return warnings
+}
+
+// referencedSources returns the distinct sources whose columns expr references,
+// not counting columns inside subqueries
+func referencedSources(expr parser.Expression, index map[string]int) []int {
+	var sources []int
+	seen := make(map[int]bool)
+	parser.Inspect(expr, func(node parser.Node) bool {
+		switch n := node.(type) {
+		case *parser.SelectStatement, *parser.SetOperation:
+			return false
+		case *parser.ColumnReference:
+			if i, ok := index[strings.ToLower(n.Table)]; ok && n.Table != "" && !seen[i] {
+				seen[i] = true
+				sources = append(sources, i)
+			}
+		}
+		return true
+	})
+	return sources
+}
+
+// referencesBothSides reports whether sources include the join's own table and
+// a table before it
+func referencesBothSides(sources []int, right int) bool {
+	hasRight, hasLeft := false, false
+	for _, s := range sources {
+		switch {
+		case s == right:
+			hasRight = true
+		case s < right:
+			hasLeft = true
+		}
+	}
+	return hasRight && hasLeft
+}
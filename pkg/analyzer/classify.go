@@ -0,0 +1,163 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+// StatementInfo summarizes what a statement does, e.g. to route read-only
+// queries to a replica
+type StatementInfo struct {
+	// Kind is SELECT, INSERT, UPDATE, DELETE, MERGE, DDL (CREATE, ALTER,
+	// DROP) or DCL (GRANT, DENY, REVOKE)
+	Kind     string            `json:"kind"`
+	ReadOnly bool              `json:"read_only"`
+	Reads    []TableDependency `json:"reads,omitempty"`
+	Writes   []TableDependency `json:"writes,omitempty"`
+}
+
+// Classify returns the kind of stmt, whether it only reads data, and the
+// tables it reads from and writes to. The target of an INSERT, UPDATE,
+// DELETE or MERGE, the table of SELECT ... INTO or OUTPUT ... INTO and the
+// objects of DDL statements are written; every other table is read. A table
+// is listed under both when it is also read elsewhere in the statement, e.g.
+// in a subquery. CTEs, derived tables and table-valued functions are not
+// tables.
+func Classify(stmt parser.Statement) StatementInfo {
+	var info StatementInfo
+	if stmt == nil {
+		return info
+	}
+
+	var targets []*parser.TableReference
+	switch s := stmt.(type) {
+	case *parser.SelectStatement, *parser.SetOperation:
+		info.Kind = "SELECT"
+	case *parser.InsertStatement:
+		info.Kind = "INSERT"
+		targets = append(targets, &s.Table)
+	case *parser.UpdateStatement:
+		info.Kind = "UPDATE"
+		// The UPDATE target may name an alias from the FROM clause
+		targets = append(targets, &s.Table)
+		if from := updateTargetSource(s); from != nil {
+			targets = append(targets, from)
+		}
+	case *parser.DeleteStatement:
+		info.Kind = "DELETE"
+		targets = append(targets, &s.From)
+	case *parser.MergeStatement:
+		info.Kind = "MERGE"
+		targets = append(targets, &s.Target)
+	case *parser.CreateTableStatement:
+		info.Kind = "DDL"
+		targets = append(targets, &s.Table)
+	case *parser.AlterTableStatement:
+		info.Kind = "DDL"
+		targets = append(targets, &s.Table)
+	case *parser.CreateViewStatement:
+		info.Kind = "DDL"
+		targets = append(targets, &s.View)
+	case *parser.CreateIndexStatement:
+		info.Kind = "DDL"
+		targets = append(targets, &s.Table)
+	case *parser.DropStatement:
+		info.Kind = "DDL"
+		// Index and procedure names are not tables
+		switch s.ObjectType {
+		case "TABLE", "VIEW":
+			for i := range s.Objects {
+				targets = append(targets, &s.Objects[i])
+			}
+		case "INDEX":
+			if s.Table != nil {
+				targets = append(targets, s.Table)
+			}
+		}
+	case *parser.PermissionStatement:
+		info.Kind = "DCL"
+	}
+
+	parser.Inspect(stmt, func(node parser.Node) bool {
+		switch n := node.(type) {
+		case *parser.SelectStatement:
+			if n.Into != nil {
+				targets = append(targets, n.Into)
+			}
+		case *parser.OutputClause:
+			if n.Into != nil {
+				targets = append(targets, n.Into)
+			}
+		}
+		return true
+	})
+
+	isTarget := make(map[*parser.TableReference]bool, len(targets))
+	for _, table := range targets {
+		isTarget[table] = true
+	}
+
+	// Only tables the dependency extraction resolved are reported, which
+	// leaves out CTE references. The objects of DDL statements are always
+	// tables or views.
+	deps := ExtractDependencies(stmt)
+	physical := make(map[TableDependency]bool, len(deps.Tables))
+	for _, dep := range deps.Tables {
+		physical[dep] = true
+	}
+
+	writes := make(map[TableDependency]bool)
+	reads := make(map[TableDependency]bool)
+	parser.Inspect(stmt, func(node parser.Node) bool {
+		table, ok := node.(*parser.TableReference)
+		if !ok || table.Subquery != nil || table.Function != nil {
+			return true
+		}
+		dep := TableDependency{Server: table.Server, Database: table.Database, Schema: table.Schema, Name: table.Name}
+		if !physical[dep] && !(isTarget[table] && info.Kind == "DDL") {
+			return true
+		}
+		if isTarget[table] {
+			if !writes[dep] {
+				writes[dep] = true
+				info.Writes = append(info.Writes, dep)
+			}
+		} else if !reads[dep] {
+			reads[dep] = true
+			info.Reads = append(info.Reads, dep)
+		}
+		return true
+	})
+
+	info.ReadOnly = info.Kind == "SELECT" && len(info.Writes) == 0
+	return info
+}
+
+// updateTargetSource returns the FROM clause table an UPDATE target names by
+// alias, as in UPDATE u SET ... FROM users u, or nil if there is none
+func updateTargetSource(stmt *parser.UpdateStatement) *parser.TableReference {
+	if stmt.Table.Schema != "" || stmt.Table.Database != "" || stmt.Table.Server != "" {
+		return nil
+	}
+
+	var sources []*parser.TableReference
+	if stmt.From != nil {
+		for i := range stmt.From.Tables {
+			sources = append(sources, &stmt.From.Tables[i])
+		}
+	}
+	for _, join := range stmt.Joins {
+		sources = append(sources, &join.Table)
+	}
+	for _, source := range sources {
+		name := source.Alias
+		if name == "" {
+			name = source.Name
+		}
+		if strings.EqualFold(name, stmt.Table.Name) {
+			return source
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+// Complexity breakdown keys returned by ComplexityScore
+const (
+	ComplexityJoins          = "joins"
+	ComplexitySubqueries     = "subqueries"
+	ComplexitySetOperations  = "set_operations"
+	ComplexityCaseExpression = "case_expressions"
+	ComplexityFunctionCalls  = "function_calls"
+	ComplexityPredicateDepth = "predicate_depth"
+)
+
+// Complexity weights
+const (
+	joinWeight         = 2
+	subqueryWeight     = 3
+	setOperationWeight = 2
+	caseWeight         = 1
+	functionWeight     = 1
+)
+
+// ComplexityScore rates how hard a statement is to read, for flagging overly
+// complex queries. It returns the total score and the points per category:
+//
+//	joins             2 per JOIN or APPLY
+//	subqueries        3 per nested query: derived tables, CTE bodies,
+//	                  IN/EXISTS/scalar subqueries
+//	set_operations    2 per UNION, INTERSECT or EXCEPT
+//	case_expressions  1 per CASE, plus 1 for each CASE it is nested in
+//	function_calls    1 per function call, including aggregates and casts
+//	predicate_depth   the nesting depth of each WHERE and HAVING condition,
+//	                  where a single comparison is 1 and each enclosing
+//	                  AND, OR or NOT adds 1
+//
+// Every category is present in the breakdown, with zero when unused.
+func ComplexityScore(stmt parser.Statement) (int, map[string]int) {
+	breakdown := map[string]int{
+		ComplexityJoins:          0,
+		ComplexitySubqueries:     0,
+		ComplexitySetOperations:  0,
+		ComplexityCaseExpression: 0,
+		ComplexityFunctionCalls:  0,
+		ComplexityPredicateDepth: 0,
+	}
+	if stmt == nil {
+		return 0, breakdown
+	}
+
+	var stack []parser.Node
+	parser.Inspect(stmt, func(node parser.Node) bool {
+		if node == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+
+		switch n := node.(type) {
+		case *parser.JoinClause:
+			breakdown[ComplexityJoins] += joinWeight
+		case *parser.SelectStatement:
+			// Queries directly under a statement (set operation branches,
+			// INSERT ... SELECT, view bodies) are not subqueries
+			if len(stack) > 0 {
+				if _, ok := stack[len(stack)-1].(parser.Statement); !ok {
+					breakdown[ComplexitySubqueries] += subqueryWeight
+				}
+			}
+			breakdown[ComplexityPredicateDepth] += predicateDepth(n.Where) + predicateDepth(n.Having)
+		case *parser.UpdateStatement:
+			breakdown[ComplexityPredicateDepth] += predicateDepth(n.Where)
+		case *parser.DeleteStatement:
+			breakdown[ComplexityPredicateDepth] += predicateDepth(n.Where)
+		case *parser.SetOperation:
+			breakdown[ComplexitySetOperations] += setOperationWeight
+		case *parser.CaseExpression:
+			nesting := 0
+			for _, ancestor := range stack {
+				if _, ok := ancestor.(*parser.CaseExpression); ok {
+					nesting++
+				}
+			}
+			breakdown[ComplexityCaseExpression] += caseWeight + nesting
+		case *parser.FunctionCall, *parser.CastExpression:
+			breakdown[ComplexityFunctionCalls] += functionWeight
+		}
+
+		stack = append(stack, node)
+		return true
+	})
+
+	total := 0
+	for _, points := range breakdown {
+		total += points
+	}
+	return total, breakdown
+}
+
+// predicateDepth returns the nesting depth of the AND/OR/NOT tree of a condition
+func predicateDepth(expr parser.Expression) int {
+	switch e := expr.(type) {
+	case nil:
+		return 0
+	case *parser.BinaryExpression:
+		if strings.EqualFold(e.Operator, "AND") || strings.EqualFold(e.Operator, "OR") {
+			return 1 + max(predicateDepth(e.Left), predicateDepth(e.Right))
+		}
+	case *parser.UnaryExpression:
+		if strings.EqualFold(e.Operator, "NOT") {
+			return 1 + predicateDepth(e.Operand)
+		}
+	}
+	return 1
+}
@@ -0,0 +1,385 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+// SourceKind describes what a table name in a query refers to
+type SourceKind string
+
+const (
+	SourceTable   SourceKind = "TABLE"   // physical table or view
+	SourceCTE     SourceKind = "CTE"     // common table expression
+	SourceDerived SourceKind = "DERIVED" // derived table (subquery in FROM)
+)
+
+// Dependencies lists the tables and columns a statement reads or writes
+type Dependencies struct {
+	Tables        []TableDependency  `json:"tables"`
+	Columns       []ColumnDependency `json:"columns"`
+	CTEs          []string           `json:"ctes,omitempty"`
+	DerivedTables []string           `json:"derived_tables,omitempty"`
+}
+
+// TableDependency is a physical table referenced by a statement
+type TableDependency struct {
+	Server   string `json:"server,omitempty"`
+	Database string `json:"database,omitempty"`
+	Schema   string `json:"schema,omitempty"`
+	Name     string `json:"name"`
+}
+
+// ColumnDependency is a column resolved to the source it belongs to.
+// Table and Kind are empty when the source cannot be determined, e.g. an
+// unqualified column in a query over several tables.
+type ColumnDependency struct {
+	Schema string     `json:"schema,omitempty"`
+	Table  string     `json:"table,omitempty"`
+	Column string     `json:"column"`
+	Kind   SourceKind `json:"kind,omitempty"`
+}
+
+// ExtractDependencies returns the tables and columns referenced by a statement,
+// including those in joins, subqueries and predicates. Aliases are resolved to
+// their source tables; CTEs and derived tables are reported separately from
+// physical tables.
+func ExtractDependencies(stmt parser.Statement) Dependencies {
+	e := &dependencyExtractor{
+		tableSeen:  make(map[TableDependency]bool),
+		columnSeen: make(map[ColumnDependency]bool),
+	}
+	e.statement(stmt, nil)
+	return e.deps
+}
+
+type dependencySource struct {
+	kind   SourceKind
+	schema string
+	name   string
+}
+
+// dependencyScope holds the sources visible to one query level
+type dependencyScope struct {
+	parent  *dependencyScope
+	ctes    map[string]bool
+	sources map[string]dependencySource // keyed by lower-cased alias or name
+	order   []dependencySource
+}
+
+func newDependencyScope(parent *dependencyScope) *dependencyScope {
+	return &dependencyScope{
+		parent:  parent,
+		ctes:    make(map[string]bool),
+		sources: make(map[string]dependencySource),
+	}
+}
+
+func (s *dependencyScope) isCTE(name string) bool {
+	for scope := s; scope != nil; scope = scope.parent {
+		if scope.ctes[strings.ToLower(name)] {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *dependencyScope) lookup(qualifier string) (dependencySource, bool) {
+	for scope := s; scope != nil; scope = scope.parent {
+		if src, ok := scope.sources[strings.ToLower(qualifier)]; ok {
+			return src, true
+		}
+	}
+	return dependencySource{}, false
+}
+
+// outer returns a scope seeing what s sees except the sources s holds itself,
+// for derived tables that cannot refer to their sibling FROM items but do see
+// the CTEs of their query
+func (s *dependencyScope) outer() *dependencyScope {
+	scope := newDependencyScope(s.parent)
+	scope.ctes = s.ctes
+	return scope
+}
+
+func (s *dependencyScope) add(alias string, src dependencySource) {
+	s.sources[strings.ToLower(alias)] = src
+	s.order = append(s.order, src)
+}
+
+type dependencyExtractor struct {
+	deps       Dependencies
+	tableSeen  map[TableDependency]bool
+	columnSeen map[ColumnDependency]bool
+}
+
+func (e *dependencyExtractor) statement(stmt parser.Statement, parent *dependencyScope) {
+	switch s := stmt.(type) {
+	case *parser.SelectStatement:
+		e.selectStatement(s, parent)
+	case *parser.SetOperation:
+		scope := e.withClause(s.With, parent)
+		e.statement(s.Left, scope)
+		e.statement(s.Right, scope)
+	case *parser.InsertStatement:
+		scope := e.withClause(s.With, parent)
+		target := e.tableSource(&s.Table, scope)
+		for _, column := range s.Columns {
+			e.addColumn(target, column)
+		}
+		e.outputClause(s.Output, target, scope)
+		for _, row := range s.Values {
+			e.expressions(row, scope)
+		}
+		if s.Source != nil {
+			e.selectStatement(s.Source, scope)
+		}
+	case *parser.UpdateStatement:
+		scope := e.withClause(s.With, parent)
+		e.fromAndJoins(s.From, s.Joins, scope)
+		// The UPDATE target may name an alias from the FROM clause
+		target, ok := scope.lookup(s.Table.Name)
+		if !ok || s.Table.Schema != "" || s.Table.Database != "" || s.Table.Server != "" {
+			target = e.tableSource(&s.Table, scope)
+			scope.add(s.Table.Name, target)
+		}
+		for _, assignment := range s.Set {
+			e.addColumn(target, assignment.Column.Column)
+			e.expression(assignment.Value, scope)
+		}
+		e.outputClause(s.Output, target, scope)
+		for _, join := range s.Joins {
+			e.expression(join.Condition, scope)
+		}
+		e.expression(s.Where, scope)
+	case *parser.DeleteStatement:
+		scope := e.withClause(s.With, parent)
+		e.addSource(&s.From, scope)
+		name := s.From.Alias
+		if name == "" {
+			name = s.From.Name
+		}
+		if target, ok := scope.lookup(name); ok {
+			e.outputClause(s.Output, target, scope)
+		}
+		e.expression(s.Where, scope)
+	case *parser.MergeStatement:
+		scope := e.withClause(s.With, parent)
+		target := e.tableSource(&s.Target, scope)
+		if s.Target.Alias != "" {
+			scope.add(s.Target.Alias, target)
+		} else {
+			scope.add(s.Target.Name, target)
+		}
+		e.addSource(&s.Source, scope)
+		e.expression(s.Condition, scope)
+		for _, clause := range s.Clauses {
+			e.expression(clause.Condition, scope)
+			for _, assignment := range clause.Set {
+				e.addColumn(target, assignment.Column.Column)
+				e.expression(assignment.Value, scope)
+			}
+			for _, column := range clause.Columns {
+				e.addColumn(target, column)
+			}
+			e.expressions(clause.Values, scope)
+		}
+	case *parser.CreateIndexStatement:
+		scope := newDependencyScope(parent)
+		target := e.tableSource(&s.Table, scope)
+		scope.add(s.Table.Name, target)
+		for _, column := range s.Columns {
+			e.addColumn(target, column.Name)
+		}
+		for _, column := range s.Include {
+			e.addColumn(target, column)
+		}
+		e.expression(s.Where, scope)
+	case *parser.CreateViewStatement:
+		// A view depends on whatever its defining query reads
+		e.statement(s.Query, parent)
+	}
+}
+
+// outputClause records the columns of an OUTPUT clause, resolving the
+// inserted and deleted pseudo-tables to the statement's target, and the
+// table the rows are output into
+func (e *dependencyExtractor) outputClause(output *parser.OutputClause, target dependencySource, parent *dependencyScope) {
+	if output == nil {
+		return
+	}
+
+	scope := newDependencyScope(parent)
+	scope.add("inserted", target)
+	scope.add("deleted", target)
+	e.expressions(output.Columns, scope)
+
+	if output.Into != nil {
+		into := e.tableSource(output.Into, scope)
+		for _, column := range output.IntoColumns {
+			e.addColumn(into, column)
+		}
+	}
+}
+
+func (e *dependencyExtractor) selectStatement(stmt *parser.SelectStatement, parent *dependencyScope) {
+	scope := e.withClause(stmt.With, parent)
+	e.fromAndJoins(stmt.From, stmt.Joins, scope)
+	if stmt.Into != nil {
+		e.tableSource(stmt.Into, scope)
+	}
+
+	e.expressions(stmt.Columns, scope)
+	for _, join := range stmt.Joins {
+		e.expression(join.Condition, scope)
+	}
+	e.expression(stmt.Where, scope)
+	if stmt.GroupBy != nil {
+		e.expressions(stmt.GroupBy.Items, scope)
+	}
+	e.expression(stmt.Having, scope)
+	for _, item := range stmt.OrderBy {
+		e.expression(item.Expression, scope)
+	}
+}
+
+// withClause opens a new scope holding the CTE names, and collects each CTE query
+func (e *dependencyExtractor) withClause(with *parser.WithClause, parent *dependencyScope) *dependencyScope {
+	scope := newDependencyScope(parent)
+	if with == nil {
+		return scope
+	}
+
+	for _, cte := range with.CTEs {
+		scope.ctes[strings.ToLower(cte.Name)] = true
+		e.deps.CTEs = append(e.deps.CTEs, cte.Name)
+		e.statement(cte.Query, scope)
+	}
+	return scope
+}
+
+func (e *dependencyExtractor) fromAndJoins(from *parser.FromClause, joins []*parser.JoinClause, scope *dependencyScope) {
+	if from != nil {
+		for i := range from.Tables {
+			e.addSource(&from.Tables[i], scope)
+		}
+	}
+	for _, join := range joins {
+		e.addSource(&join.Table, scope)
+	}
+}
+
+// addSource registers a FROM/JOIN item in the scope under its alias or name
+func (e *dependencyExtractor) addSource(table *parser.TableReference, scope *dependencyScope) {
+	var name string
+	var src dependencySource
+	switch {
+	case table.Subquery != nil:
+		e.selectStatement(table.Subquery, scope.outer())
+		e.deps.DerivedTables = append(e.deps.DerivedTables, table.Alias)
+		name, src = table.Alias, dependencySource{kind: SourceDerived, name: table.Alias}
+	case table.Values != nil:
+		// Like a derived table, the rows cannot see the other sources
+		rowScope := scope.outer()
+		for _, row := range table.Values.Rows {
+			e.expressions(row, rowScope)
+		}
+		e.deps.DerivedTables = append(e.deps.DerivedTables, table.Alias)
+		name, src = table.Alias, dependencySource{kind: SourceDerived, name: table.Alias}
+	case table.Function != nil:
+		// Table-valued functions may reference columns of preceding sources (APPLY)
+		e.expressions(table.Function.Arguments, scope)
+		return
+	default:
+		src = e.tableSource(table, scope)
+		name = table.Alias
+		if name == "" {
+			name = table.Name
+		}
+	}
+
+	if table.Pivot == nil && table.Unpivot == nil {
+		scope.add(name, src)
+		return
+	}
+
+	// PIVOT and UNPIVOT read columns of the source and hide it behind a
+	// result of their own, known by their alias
+	inner := scope.outer()
+	inner.add(name, src)
+	var alias string
+	if table.Pivot != nil {
+		e.expression(table.Pivot.Aggregate, inner)
+		e.expression(table.Pivot.Column, inner)
+		alias = table.Pivot.Alias
+	} else {
+		for _, column := range table.Unpivot.Columns {
+			e.addColumn(src, column)
+		}
+		alias = table.Unpivot.Alias
+	}
+	e.deps.DerivedTables = append(e.deps.DerivedTables, alias)
+	scope.add(alias, dependencySource{kind: SourceDerived, name: alias})
+}
+
+// tableSource classifies a named table reference, recording physical tables
+func (e *dependencyExtractor) tableSource(table *parser.TableReference, scope *dependencyScope) dependencySource {
+	if table.Schema == "" && table.Database == "" && table.Server == "" && scope.isCTE(table.Name) {
+		return dependencySource{kind: SourceCTE, name: table.Name}
+	}
+
+	dep := TableDependency{Server: table.Server, Database: table.Database, Schema: table.Schema, Name: table.Name}
+	if !e.tableSeen[dep] {
+		e.tableSeen[dep] = true
+		e.deps.Tables = append(e.deps.Tables, dep)
+	}
+	return dependencySource{kind: SourceTable, schema: table.Schema, name: table.Name}
+}
+
+func (e *dependencyExtractor) addColumn(src dependencySource, column string) {
+	dep := ColumnDependency{Schema: src.schema, Table: src.name, Column: column, Kind: src.kind}
+	if !e.columnSeen[dep] {
+		e.columnSeen[dep] = true
+		e.deps.Columns = append(e.deps.Columns, dep)
+	}
+}
+
+func (e *dependencyExtractor) expressions(exprs []parser.Expression, scope *dependencyScope) {
+	for _, expr := range exprs {
+		e.expression(expr, scope)
+	}
+}
+
+func (e *dependencyExtractor) expression(expr parser.Expression, scope *dependencyScope) {
+	if expr == nil {
+		return
+	}
+
+	parser.Inspect(expr, func(node parser.Node) bool {
+		switch n := node.(type) {
+		case *parser.SelectStatement:
+			// Subqueries get their own scope, which can see the outer sources
+			e.selectStatement(n, scope)
+			return false
+		case *parser.ColumnReference:
+			e.addColumn(e.resolveColumn(n, scope), n.Column)
+		}
+		return true
+	})
+}
+
+// resolveColumn finds the source a column belongs to via its qualifier, or the
+// single source of the innermost query for unqualified columns
+func (e *dependencyExtractor) resolveColumn(col *parser.ColumnReference, scope *dependencyScope) dependencySource {
+	if col.Table != "" {
+		if src, ok := scope.lookup(col.Table); ok {
+			return src
+		}
+		return dependencySource{name: col.Table}
+	}
+	if len(scope.order) == 1 {
+		return scope.order[0]
+	}
+	return dependencySource{}
+}
@@ -25,7 +25,7 @@ type TableInfo struct {
 type ColumnInfo struct {
 	Table string `json:"table,omitempty"`
 	Name  string `json:"name"`
-	Usage string `json:"usage"` // SELECT, WHERE, JOIN, ORDER_BY, GROUP_BY
+	Usage string `json:"usage"` // SELECT, WHERE, JOIN, ORDER_BY, GROUP_BY, INDEX, INCLUDE, OUTPUT, PIVOT, UNPIVOT
 }
 
 type JoinInfo struct {
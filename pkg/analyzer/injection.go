@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+// WarningDynamicSQL is the Type of warnings from DetectInjectionRisks
+const WarningDynamicSQL = "DYNAMIC_SQL"
+
+// sqlKeywordPattern matches the keywords that mark a string as SQL text.
+// Words that are as common in prose as in SQL (AND, OR, IN, BY, SET) are left
+// out.
+var sqlKeywordPattern = regexp.MustCompile(`(?i)\b(SELECT|INSERT|UPDATE|DELETE|MERGE|EXEC|EXECUTE|DROP|ALTER|CREATE|TRUNCATE|UNION|FROM|WHERE|JOIN|INTO|VALUES|HAVING)\b`)
+
+// spliceSuffixPattern matches SQL text ending where a value would follow,
+// e.g. "WHERE name = " or "id IN ("
+var spliceSuffixPattern = regexp.MustCompile(`(?i)(=|<|>|\bLIKE|\bIN\s*\()\s*'?$`)
+
+// DetectInjectionRisks flags string concatenations in stmt that look like SQL
+// being built at run time, which is open to SQL injection when the spliced
+// values come from user input. A concatenation with +, || or CONCAT() is
+// flagged when:
+//
+//   - one of its parts is a string literal that looks like SQL text: it
+//     contains two different SQL keywords (SELECT ... FROM, DELETE FROM ...
+//     WHERE), or one keyword and ends with a comparison a value would follow
+//     ("WHERE name = ")
+//   - and another part is not a literal, such as a parameter or a column
+//
+// Concatenating constants only, or plain text with parameters such as
+// '%' + @search + '%', is not flagged.
+func DetectInjectionRisks(stmt parser.Statement) []Warning {
+	if stmt == nil {
+		return nil
+	}
+
+	var warnings []Warning
+	var visit func(node parser.Node) bool
+	visit = func(node parser.Node) bool {
+		parts := concatParts(node)
+		if parts == nil {
+			return true
+		}
+
+		sqlText := false
+		var values []string
+		for _, part := range parts {
+			if lit, ok := part.(*parser.Literal); ok {
+				if text, ok := lit.Value.(string); ok && looksLikeSQL(text) {
+					sqlText = true
+				}
+				continue
+			}
+			values = append(values, part.String())
+		}
+		if sqlText && len(values) > 0 {
+			warnings = append(warnings, Warning{
+				Type:    WarningDynamicSQL,
+				Message: fmt.Sprintf("%s builds SQL text from %s; pass values as parameters instead", node.String(), strings.Join(values, ", ")),
+			})
+		}
+
+		// Parts may hold their own concatenations, e.g. in a subquery
+		for _, part := range parts {
+			parser.Inspect(part, visit)
+		}
+		return false
+	}
+	parser.Inspect(stmt, visit)
+	return warnings
+}
+
+// concatParts returns the operands of a string concatenation, flattening
+// chains such as a + b + c, or nil if node is not a concatenation
+func concatParts(node parser.Node) []parser.Expression {
+	switch n := node.(type) {
+	case *parser.BinaryExpression:
+		if n.Operator != "+" && n.Operator != "||" {
+			return nil
+		}
+		var parts []parser.Expression
+		for _, operand := range []parser.Expression{n.Left, n.Right} {
+			if nested := concatParts(operand); nested != nil {
+				parts = append(parts, nested...)
+			} else {
+				parts = append(parts, operand)
+			}
+		}
+		return parts
+	case *parser.FunctionCall:
+		if strings.EqualFold(n.Name, "CONCAT") || strings.EqualFold(n.Name, "CONCAT_WS") {
+			return n.Arguments
+		}
+	}
+	return nil
+}
+
+// looksLikeSQL reports whether a string literal reads as a fragment of a query
+func looksLikeSQL(text string) bool {
+	keywords := make(map[string]bool)
+	for _, kw := range sqlKeywordPattern.FindAllString(text, -1) {
+		keywords[strings.ToUpper(kw)] = true
+	}
+	switch {
+	case len(keywords) >= 2:
+		return true
+	case len(keywords) == 1:
+		return spliceSuffixPattern.MatchString(strings.TrimSpace(text))
+	}
+	return false
+}
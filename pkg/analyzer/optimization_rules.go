@@ -144,24 +144,18 @@ func (oe *OptimizationEngine) checkInefficientSubquery(stmt parser.Statement) []
 
 	if selectStmt, ok := stmt.(*parser.SelectStatement); ok && selectStmt.Where != nil {
 		// Check for InExpression with subqueries
-		if inExpr, ok := selectStmt.Where.(*parser.InExpression); ok {
-			// Check if any of the values in the IN expression are subqueries
-			for _, value := range inExpr.Values {
-				if _, isSubquery := value.(*parser.SubqueryExpression); isSubquery {
-					suggestions = append(suggestions, EnhancedOptimizationSuggestion{
-						Type:          "INEFFICIENT_SUBQUERY",
-						Description:   "Subquery in WHERE clause may be optimized as a JOIN",
-						Severity:      "INFO",
-						Category:      "PERFORMANCE",
-						Rule:          "INEFFICIENT_SUBQUERY",
-						Suggestion:    "Consider converting subquery to JOIN",
-						Impact:        "MEDIUM",
-						AutoFixable:   false,
-						FixSuggestion: "Replace correlated subqueries with JOINs when possible for better performance",
-					})
-					break // Only need to suggest once per statement
-				}
-			}
+		if inExpr, ok := selectStmt.Where.(*parser.InExpression); ok && inExpr.Subquery != nil {
+			suggestions = append(suggestions, EnhancedOptimizationSuggestion{
+				Type:          "INEFFICIENT_SUBQUERY",
+				Description:   "Subquery in WHERE clause may be optimized as a JOIN",
+				Severity:      "INFO",
+				Category:      "PERFORMANCE",
+				Rule:          "INEFFICIENT_SUBQUERY",
+				Suggestion:    "Consider converting subquery to JOIN",
+				Impact:        "MEDIUM",
+				AutoFixable:   false,
+				FixSuggestion: "Replace correlated subqueries with JOINs when possible for better performance",
+			})
 		}
 
 		// Also check for string patterns as fallback (for cases we might have missed)
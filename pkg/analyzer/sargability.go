@@ -0,0 +1,152 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+// Types of warnings from AnalyzeSargability
+const (
+	WarningFunctionOnColumn   = "FUNCTION_ON_COLUMN"
+	WarningLeadingWildcard    = "LEADING_WILDCARD"
+	WarningImplicitConversion = "IMPLICIT_CONVERSION"
+)
+
+// AnalyzeSargability flags the predicates of stmt, and of the queries nested
+// in it, that keep an index on a column from being used for a seek:
+//
+//   - a function or CAST wrapping the column, as in YEAR(order_date) = 2020
+//     or UPPER(name) = 'X'
+//   - a LIKE pattern starting with a wildcard, as in name LIKE '%x'
+//   - a comparison with an N'...' string, which converts the column if it is
+//     varchar rather than nvarchar
+//
+// Each conjunct of the WHERE clause and of the join conditions is checked,
+// including comparisons nested under OR or NOT. Without a schema the third
+// check can't know the column's type, so it marks a likely rather than a
+// certain conversion. Warnings carry the offending predicate.
+func AnalyzeSargability(stmt *parser.SelectStatement) []Warning {
+	if stmt == nil {
+		return nil
+	}
+
+	var warnings []Warning
+	parser.Inspect(stmt, func(node parser.Node) bool {
+		query, ok := node.(*parser.SelectStatement)
+		if !ok {
+			return true
+		}
+		conditions := parser.ToConjunctiveList(query.Where)
+		for _, join := range query.Joins {
+			conditions = append(conditions, parser.ToConjunctiveList(join.Condition)...)
+		}
+		for _, condition := range conditions {
+			warnings = append(warnings, sargabilityWarnings(condition)...)
+		}
+		return true
+	})
+	return warnings
+}
+
+// sargabilityWarnings checks the comparisons in a condition, leaving nested
+// queries to AnalyzeSargability
+func sargabilityWarnings(condition parser.Expression) []Warning {
+	var warnings []Warning
+	check := func(predicate parser.Expression, operands ...parser.Expression) {
+		for _, operand := range operands {
+			if column, wrapper := wrappedColumn(operand); column != nil {
+				warnings = append(warnings, Warning{
+					Type:      WarningFunctionOnColumn,
+					Message:   fmt.Sprintf("%s is applied to column %s; compare the bare column instead", wrapper, column.String()),
+					Predicate: predicate.String(),
+				})
+			}
+		}
+	}
+
+	parser.Inspect(condition, func(node parser.Node) bool {
+		switch n := node.(type) {
+		case *parser.SelectStatement, *parser.SetOperation:
+			return false
+		case *parser.BinaryExpression:
+			if !isComparison(n.Operator) {
+				return true
+			}
+			check(n, n.Left, n.Right)
+			if column := unicodeComparison(n.Left, n.Right); column != nil {
+				warnings = append(warnings, Warning{
+					Type:      WarningImplicitConversion,
+					Message:   fmt.Sprintf("column %s is compared with an N'...' string and is converted if it is varchar", column.String()),
+					Predicate: n.String(),
+				})
+			}
+		case *parser.BetweenExpression:
+			check(n, n.Expr)
+		case *parser.InExpression:
+			check(n, n.Expression)
+		case *parser.LikeExpression:
+			check(n, n.Expr)
+			if pattern, ok := n.Pattern.(*parser.Literal); ok && !n.Not {
+				if text, ok := pattern.Value.(string); ok && strings.IndexAny(text, "%_") == 0 {
+					warnings = append(warnings, Warning{
+						Type:      WarningLeadingWildcard,
+						Message:   fmt.Sprintf("LIKE pattern %s starts with a wildcard", pattern.String()),
+						Predicate: n.String(),
+					})
+				}
+			}
+		}
+		return true
+	})
+	return warnings
+}
+
+// wrappedColumn returns the column under a function call or CAST operand and
+// the name of the wrapping function, or nil if operand is not one
+func wrappedColumn(operand parser.Expression) (*parser.ColumnReference, string) {
+	var name string
+	switch e := operand.(type) {
+	case *parser.FunctionCall:
+		name = strings.ToUpper(e.Name)
+	case *parser.CastExpression:
+		name = e.Function
+	default:
+		return nil, ""
+	}
+
+	var column *parser.ColumnReference
+	parser.Inspect(operand, func(node parser.Node) bool {
+		switch n := node.(type) {
+		case *parser.SelectStatement, *parser.SetOperation:
+			return false
+		case *parser.ColumnReference:
+			if column == nil {
+				column = n
+			}
+		}
+		return column == nil
+	})
+	return column, name
+}
+
+// unicodeComparison returns the column compared with an N'...' literal
+func unicodeComparison(left, right parser.Expression) *parser.ColumnReference {
+	for _, pair := range [2][2]parser.Expression{{left, right}, {right, left}} {
+		column, ok := pair[0].(*parser.ColumnReference)
+		literal, isLiteral := pair[1].(*parser.Literal)
+		if ok && isLiteral && literal.Unicode {
+			return column
+		}
+	}
+	return nil
+}
+
+func isComparison(operator string) bool {
+	switch operator {
+	case "=", "<>", "!=", "<", ">", "<=", ">=":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package analyzer
+
+import (
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+// StarUsage is a * or t.* in a select list
+type StarUsage struct {
+	Qualified bool     // t.* rather than *
+	Tables    []string // sources the star expands to, by alias where one is given
+}
+
+// UsesSelectStar reports whether a select list anywhere in stmt contains * or
+// t.*, including derived tables, CTEs, subqueries and set operation branches.
+// The select list of an EXISTS subquery is ignored, as it is never returned.
+func UsesSelectStar(stmt parser.Statement) bool {
+	return len(SelectStars(stmt)) > 0
+}
+
+// SelectStars returns every * and t.* in the select lists of stmt, in the
+// order they appear, with the tables each one expands to. Like UsesSelectStar,
+// it skips the select lists of EXISTS subqueries.
+func SelectStars(stmt parser.Statement) []StarUsage {
+	if stmt == nil {
+		return nil
+	}
+
+	var stars []StarUsage
+	exists := make(map[*parser.SelectStatement]bool)
+	parser.Inspect(stmt, func(node parser.Node) bool {
+		switch n := node.(type) {
+		case *parser.ExistsExpression:
+			exists[n.Subquery] = true
+		case *parser.SelectStatement:
+			if exists[n] {
+				break
+			}
+			for _, column := range n.Columns {
+				star, ok := column.(*parser.StarExpression)
+				if !ok {
+					continue
+				}
+				if star.Table != "" {
+					stars = append(stars, StarUsage{Qualified: true, Tables: []string{star.Table}})
+				} else {
+					stars = append(stars, StarUsage{Tables: sourceNames(n)})
+				}
+			}
+		}
+		return true
+	})
+	return stars
+}
+
+// sourceNames lists the tables in the FROM clause and joins of a query
+func sourceNames(stmt *parser.SelectStatement) []string {
+	var names []string
+	add := func(table *parser.TableReference) {
+		switch {
+		case table.Alias != "":
+			names = append(names, table.Alias)
+		case table.Function != nil:
+			names = append(names, table.Function.Name)
+		default:
+			names = append(names, table.QualifiedName())
+		}
+	}
+
+	if stmt.From != nil {
+		for i := range stmt.From.Tables {
+			add(&stmt.From.Tables[i])
+		}
+	}
+	for _, join := range stmt.Joins {
+		add(&join.Table)
+	}
+	return names
+}
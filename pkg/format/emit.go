@@ -0,0 +1,364 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/dialect"
+	"github.com/Chahine-tech/sql-parser-go/pkg/lexer"
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+// Dialect is a database Emit translates SQL Server statements to
+type Dialect int
+
+const (
+	DialectMySQL Dialect = iota
+	DialectPostgreSQL
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case DialectMySQL:
+		return "MySQL"
+	case DialectPostgreSQL:
+		return "PostgreSQL"
+	}
+	return fmt.Sprintf("Dialect(%d)", int(d))
+}
+
+// Emit renders a statement parsed as SQL Server as SQL for another database.
+// TOP n and OFFSET ... FETCH become LIMIT, identifiers that are not plain
+// words or are reserved in the target are quoted with backticks or double
+// quotes, ISNULL becomes IFNULL (MySQL) or COALESCE (PostgreSQL) and GETDATE()
+// becomes NOW(). String concatenation with + becomes || and ^ becomes # in
+// PostgreSQL; backslashes in strings are doubled for MySQL and LIKE patterns
+// containing one get an ESCAPE clause. Emit returns an error naming the first
+// construct with no equivalent in the target, e.g. OUTPUT, table hints, CROSS
+// APPLY or UPDATE ... FROM. stmt itself is not modified.
+func Emit(stmt parser.Statement, d Dialect) (string, error) {
+	if stmt == nil {
+		return "", nil
+	}
+
+	var target dialect.Dialect
+	switch d {
+	case DialectMySQL:
+		target = dialect.GetDialect("mysql")
+	case DialectPostgreSQL:
+		target = dialect.GetDialect("postgresql")
+	default:
+		return "", fmt.Errorf("unknown dialect %s", d)
+	}
+
+	// Placeholders must not clash with a name already in the statement
+	prefix := "emitted_name_"
+	for strings.Contains(stmt.String(), prefix) {
+		prefix = "x" + prefix
+	}
+
+	e := &emitter{dialect: d, target: target, prefix: prefix, quoted: make(map[string]string)}
+	out := stmt.Clone()
+	parser.Inspect(out, e.rewrite)
+	if e.err != nil {
+		return "", e.err
+	}
+	return e.requote(out.String()), nil
+}
+
+type emitter struct {
+	dialect Dialect
+	target  dialect.Dialect
+	err     error
+
+	// quoted maps the placeholders quote put in the copy to the quoted names
+	prefix string
+	quoted map[string]string
+}
+
+// unsupported records that construct has no equivalent in the target
+func (e *emitter) unsupported(construct string) bool {
+	e.err = fmt.Errorf("%s has no %s equivalent", construct, e.dialect)
+	return false
+}
+
+// rewrite translates one node of the copy in place
+func (e *emitter) rewrite(node parser.Node) bool {
+	if e.err != nil {
+		return false
+	}
+
+	switch n := node.(type) {
+	case *parser.SelectStatement:
+		if n.Into != nil && e.dialect == DialectMySQL {
+			return e.unsupported("SELECT ... INTO")
+		}
+		return e.limit(n)
+	case *parser.SetOperation:
+		// A LIMIT at the end of a branch would apply to the whole result
+		if limitedBranch(n) {
+			return e.unsupported("TOP in a branch of " + n.Operator)
+		}
+	case *parser.UpdateStatement:
+		if n.From != nil || len(n.Joins) > 0 {
+			return e.unsupported("UPDATE ... FROM")
+		}
+	case *parser.BinaryExpression:
+		if isConcatenation(n) {
+			if e.dialect == DialectMySQL {
+				return e.unsupported("string concatenation with +")
+			}
+			n.Operator = "||"
+		}
+		// ^ is exponentiation in PostgreSQL, which spells XOR #
+		if n.Operator == "^" && e.dialect == DialectPostgreSQL {
+			n.Operator = "#"
+		}
+	case *parser.Literal:
+		// MySQL reads a backslash in a string as an escape character
+		if s, ok := n.Value.(string); ok && e.dialect == DialectMySQL {
+			n.Value = strings.ReplaceAll(s, `\`, `\\`)
+		}
+	case *parser.LikeExpression:
+		return e.like(n)
+	case *parser.InsertStatement:
+		for i, col := range n.Columns {
+			n.Columns[i] = e.quote(col)
+		}
+	case *parser.DeleteStatement:
+		if n.Top != nil {
+			return e.unsupported("DELETE TOP")
+		}
+	case *parser.GroupByClause:
+		switch {
+		case n.All:
+			return e.unsupported("GROUP BY ALL")
+		case n.WithCube:
+			return e.unsupported("WITH CUBE")
+		case n.WithRollup && e.dialect == DialectPostgreSQL:
+			return e.unsupported("WITH ROLLUP")
+		}
+	case *parser.OutputClause:
+		return e.unsupported("OUTPUT")
+	case *parser.ForClause:
+		return e.unsupported("FOR " + n.Format)
+	case *parser.OptionClause:
+		return e.unsupported("OPTION")
+	case *parser.MergeStatement:
+		if e.dialect == DialectMySQL {
+			return e.unsupported("MERGE")
+		}
+	case *parser.PermissionStatement:
+		if n.Action == "DENY" {
+			return e.unsupported("DENY")
+		}
+	case *parser.JoinClause:
+		if strings.HasSuffix(n.JoinType, "APPLY") {
+			return e.unsupported(n.JoinType)
+		}
+	case *parser.TableReference:
+		return e.table(n)
+	case *parser.ColumnReference:
+		n.Table = e.quote(n.Table)
+		n.Column = e.quote(n.Column)
+	case *parser.StarExpression:
+		n.Table = e.quote(n.Table)
+	case *parser.AliasedExpression:
+		n.Alias = e.quote(n.Alias)
+	case *parser.FunctionCall:
+		return e.function(n)
+	case *parser.CastExpression:
+		if n.Function != "CAST" {
+			return e.unsupported(n.Function)
+		}
+	case *parser.DataType:
+		if n.Max {
+			return e.unsupported(n.String())
+		}
+	case *parser.SystemVariable:
+		return e.unsupported(n.Name)
+	case *parser.FullTextPredicate:
+		return e.unsupported(n.Function)
+	case *parser.CurrentOfClause:
+		if e.dialect == DialectMySQL {
+			return e.unsupported("CURRENT OF")
+		}
+	}
+	return true
+}
+
+// limit turns TOP and, for MySQL, OFFSET ... FETCH into LIMIT
+func (e *emitter) limit(stmt *parser.SelectStatement) bool {
+	if stmt.Top != nil {
+		if stmt.Top.Percent {
+			return e.unsupported("TOP PERCENT")
+		}
+		stmt.Limit = &parser.LimitClause{Count: stmt.Top.Count}
+		stmt.Top = nil
+	}
+
+	// PostgreSQL supports OFFSET ... FETCH as written
+	if stmt.Offset != nil && e.dialect == DialectMySQL {
+		if !stmt.Offset.HasFetch {
+			return e.unsupported("OFFSET without FETCH")
+		}
+		stmt.Limit = &parser.LimitClause{Count: stmt.Offset.Fetch, Offset: stmt.Offset.Offset}
+		stmt.Offset = nil
+	}
+	return true
+}
+
+// limitedBranch reports whether a branch of op, or of the set operations
+// nested in it, has a TOP clause
+func limitedBranch(op *parser.SetOperation) bool {
+	for _, branch := range []parser.Statement{op.Left, op.Right} {
+		switch b := branch.(type) {
+		case *parser.SelectStatement:
+			if b.Top != nil {
+				return true
+			}
+		case *parser.SetOperation:
+			if limitedBranch(b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isConcatenation reports whether expr is a + with a string operand, which
+// SQL Server treats as string concatenation
+func isConcatenation(expr parser.Expression) bool {
+	binary, ok := expr.(*parser.BinaryExpression)
+	if !ok || binary.Operator != "+" {
+		return false
+	}
+	for _, operand := range []parser.Expression{binary.Left, binary.Right} {
+		if literal, ok := operand.(*parser.Literal); ok {
+			if _, ok := literal.Value.(string); ok {
+				return true
+			}
+		}
+		if isConcatenation(operand) {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeCandidates are the characters like picks an ESCAPE character from
+const escapeCandidates = "!#$~^|"
+
+// like gives a pattern containing a backslash an ESCAPE clause, since both
+// targets read a backslash in a LIKE pattern as an escape by default
+func (e *emitter) like(like *parser.LikeExpression) bool {
+	pattern, ok := like.Pattern.(*parser.Literal)
+	if !ok || like.Escape != nil {
+		return true
+	}
+	value, ok := pattern.Value.(string)
+	if !ok || !strings.Contains(value, `\`) {
+		return true
+	}
+	for _, c := range escapeCandidates {
+		if !strings.ContainsRune(value, c) {
+			like.Escape = &parser.Literal{Value: string(c)}
+			return true
+		}
+	}
+	return e.unsupported("LIKE pattern " + pattern.String())
+}
+
+func (e *emitter) table(table *parser.TableReference) bool {
+	switch {
+	case len(table.Hints) > 0:
+		return e.unsupported("table hint " + table.Hints[0])
+	case table.Pivot != nil:
+		return e.unsupported("PIVOT")
+	case table.Unpivot != nil:
+		return e.unsupported("UNPIVOT")
+	case table.Kind != "":
+		return e.unsupported("table " + table.QualifiedName())
+	case table.Values != nil && e.dialect == DialectMySQL:
+		return e.unsupported("VALUES table")
+	case table.Server != "":
+		return e.unsupported("linked server " + table.Server)
+	case table.Database != "" && table.Schema != "" && e.dialect == DialectMySQL:
+		return e.unsupported("three-part name " + table.QualifiedName())
+	}
+
+	table.Database = e.quote(table.Database)
+	table.Schema = e.quote(table.Schema)
+	if table.Function == nil && table.Subquery == nil {
+		table.Name = e.quote(table.Name)
+	}
+	table.Alias = e.quote(table.Alias)
+	for i, col := range table.Columns {
+		table.Columns[i] = e.quote(col)
+	}
+	return true
+}
+
+func (e *emitter) function(call *parser.FunctionCall) bool {
+	for _, arg := range call.Arguments {
+		if _, ok := arg.(*parser.DatePart); ok {
+			return e.unsupported(strings.ToUpper(call.Name))
+		}
+	}
+
+	switch strings.ToUpper(call.Name) {
+	case "ISNULL":
+		call.Name = "IFNULL"
+		if e.dialect == DialectPostgreSQL {
+			call.Name = "COALESCE"
+		}
+	case "GETDATE":
+		call.Name = "NOW"
+	}
+	return true
+}
+
+// quote stands a placeholder in for a name that is a plain word in SQL Server
+// but reserved in the target, which requote replaces with the quoted name.
+// Names SQL Server needs brackets for are left to requote as they are.
+func (e *emitter) quote(name string) string {
+	if name == "" || parser.QuoteIdent(name) != name || !e.target.IsReservedWord(strings.ToUpper(name)) {
+		return name
+	}
+	placeholder := fmt.Sprintf("%s%d", e.prefix, len(e.quoted))
+	e.quoted[placeholder] = e.quoteIdentifier(name)
+	return placeholder
+}
+
+// requote swaps the bracketed identifiers in sql for the target's quoting and
+// the placeholders from quote for the names they stand for. Everything else
+// is copied as it is, including a # operator the lexer does not accept.
+func (e *emitter) requote(sql string) string {
+	tokens, _ := lexer.Tokenize(sql)
+
+	var sb strings.Builder
+	last := 0
+	for _, tok := range tokens {
+		if tok.Type != lexer.IDENT {
+			continue
+		}
+		name, ok := e.quoted[tok.Literal]
+		if !ok {
+			if sql[tok.Position] != '[' {
+				continue
+			}
+			name = e.quoteIdentifier(tok.Literal)
+		}
+		sb.WriteString(sql[last:tok.Position])
+		sb.WriteString(name)
+		last = tok.EndPosition
+	}
+	sb.WriteString(sql[last:])
+	return sb.String()
+}
+
+// quoteIdentifier quotes name for the target, doubling its quote character
+func (e *emitter) quoteIdentifier(name string) string {
+	mark := e.target.QuoteIdentifier("")[:1]
+	return e.target.QuoteIdentifier(strings.ReplaceAll(name, mark, mark+mark))
+}
@@ -0,0 +1,767 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+// KeywordCase controls how SQL keywords are emitted
+type KeywordCase int
+
+const (
+	KeywordCaseUpper KeywordCase = iota // SELECT, FROM, WHERE
+	KeywordCaseLower                    // select, from, where
+)
+
+// FormatOptions controls the layout of formatted SQL
+type FormatOptions struct {
+	// Number of spaces per indentation level
+	IndentWidth int
+
+	// Case used for SQL keywords
+	KeywordCase KeywordCase
+
+	// Place each SELECT column (and UPDATE assignment) on its own line
+	ColumnsOnNewLines bool
+}
+
+// DefaultFormatOptions returns formatting options with sensible defaults
+func DefaultFormatOptions() FormatOptions {
+	return FormatOptions{
+		IndentWidth:       2,
+		KeywordCase:       KeywordCaseUpper,
+		ColumnsOnNewLines: true,
+	}
+}
+
+// Format renders a parsed statement as indented SQL
+func Format(stmt parser.Statement, opts FormatOptions) string {
+	if opts.IndentWidth < 0 {
+		opts.IndentWidth = 0
+	}
+	f := &formatter{opts: opts, indent: strings.Repeat(" ", opts.IndentWidth)}
+	return f.statement(stmt)
+}
+
+type formatter struct {
+	opts   FormatOptions
+	indent string
+}
+
+// kw applies the configured keyword case
+func (f *formatter) kw(keyword string) string {
+	if f.opts.KeywordCase == KeywordCaseLower {
+		return strings.ToLower(keyword)
+	}
+	return strings.ToUpper(keyword)
+}
+
+// indentBlock prefixes every line of a block with one indentation level.
+// Line breaks inside string literals and quoted names are part of the value
+// and are left alone.
+func (f *formatter) indentBlock(block string) string {
+	var sb strings.Builder
+	sb.WriteString(f.indent)
+	var closing byte // quote ending the literal or name being copied
+	for i := 0; i < len(block); i++ {
+		c := block[i]
+		sb.WriteByte(c)
+		switch {
+		case closing == ']' && c == ']' && i+1 < len(block) && block[i+1] == ']':
+			sb.WriteByte(']') // ]] escape inside a bracketed name
+			i++
+		case closing != 0:
+			if c == closing {
+				closing = 0
+			}
+		case c == '\'':
+			closing = '\''
+		case c == '[':
+			closing = ']'
+		case c == '\n':
+			sb.WriteString(f.indent)
+		}
+	}
+	return sb.String()
+}
+
+// nested renders a statement inside parentheses on its own indented lines
+func (f *formatter) nested(stmt parser.Statement) string {
+	return "(\n" + f.indentBlock(f.statement(stmt)) + "\n)"
+}
+
+func (f *formatter) statement(stmt parser.Statement) string {
+	switch s := stmt.(type) {
+	case *parser.SelectStatement:
+		return f.selectStatement(s)
+	case *parser.SetOperation:
+		return f.setOperation(s)
+	case *parser.InsertStatement:
+		return f.insertStatement(s)
+	case *parser.UpdateStatement:
+		return f.updateStatement(s)
+	case *parser.DeleteStatement:
+		return f.deleteStatement(s)
+	case *parser.MergeStatement:
+		return f.mergeStatement(s)
+	case *parser.CreateViewStatement:
+		return f.createViewStatement(s)
+	default:
+		return stmt.String()
+	}
+}
+
+func (f *formatter) withClause(with *parser.WithClause) string {
+	if with == nil {
+		return ""
+	}
+
+	ctes := make([]string, len(with.CTEs))
+	for i, cte := range with.CTEs {
+		name := parser.QuoteIdent(cte.Name)
+		if len(cte.Columns) > 0 {
+			name = fmt.Sprintf("%s (%s)", name, identList(cte.Columns))
+		}
+		ctes[i] = fmt.Sprintf("%s %s %s", name, f.kw("AS"), f.nested(cte.Query))
+	}
+
+	prefix := f.kw("WITH") + " "
+	if with.Recursive {
+		prefix += f.kw("RECURSIVE") + " "
+	}
+	return prefix + strings.Join(ctes, ",\n") + "\n"
+}
+
+func (f *formatter) selectStatement(stmt *parser.SelectStatement) string {
+	var lines []string
+
+	head := f.kw("SELECT")
+	if stmt.Distinct {
+		head += " " + f.kw("DISTINCT")
+	}
+	if stmt.Top != nil {
+		head += " " + f.topClause(stmt.Top)
+	}
+	lines = append(lines, f.list(head, stmt.Columns))
+
+	if stmt.Into != nil {
+		lines = append(lines, f.kw("INTO")+" "+f.tableReference(stmt.Into))
+	}
+	if stmt.From != nil {
+		lines = append(lines, f.fromClause(stmt.From))
+	}
+	for _, join := range stmt.Joins {
+		lines = append(lines, f.joinClause(join))
+	}
+	if stmt.Where != nil {
+		lines = append(lines, f.condition("WHERE", stmt.Where))
+	}
+	if stmt.GroupBy != nil {
+		lines = append(lines, f.groupByClause(stmt.GroupBy))
+	}
+	if stmt.Having != nil {
+		lines = append(lines, f.condition("HAVING", stmt.Having))
+	}
+	if len(stmt.OrderBy) > 0 {
+		lines = append(lines, f.kw("ORDER BY")+" "+f.orderByList(stmt.OrderBy))
+	}
+	if stmt.Offset != nil {
+		offset := fmt.Sprintf("%s %d %s", f.kw("OFFSET"), stmt.Offset.Offset, f.kw("ROWS"))
+		if stmt.Offset.HasFetch {
+			offset += fmt.Sprintf(" %s %d %s", f.kw("FETCH NEXT"), stmt.Offset.Fetch, f.kw("ROWS ONLY"))
+		}
+		lines = append(lines, offset)
+	}
+	if stmt.Limit != nil {
+		limit := fmt.Sprintf("%s %d", f.kw("LIMIT"), stmt.Limit.Count)
+		if stmt.Limit.Offset > 0 {
+			limit += fmt.Sprintf(" %s %d", f.kw("OFFSET"), stmt.Limit.Offset)
+		}
+		lines = append(lines, limit)
+	}
+	if stmt.For != nil {
+		lines = append(lines, f.forClause(stmt.For))
+	}
+	if stmt.Option != nil {
+		lines = append(lines, f.optionClause(stmt.Option))
+	}
+
+	return f.withClause(stmt.With) + strings.Join(lines, "\n")
+}
+
+func (f *formatter) setOperation(op *parser.SetOperation) string {
+	return f.withClause(op.With) +
+		f.statement(op.Left) + "\n" + f.kw(op.Operator) + "\n" + f.statement(op.Right)
+}
+
+func (f *formatter) insertStatement(stmt *parser.InsertStatement) string {
+	head := f.kw("INSERT INTO") + " " + f.tableReference(&stmt.Table)
+	if len(stmt.Columns) > 0 {
+		head += " (" + identList(stmt.Columns) + ")"
+	}
+	if stmt.Output != nil {
+		head += "\n" + f.outputClause(stmt.Output)
+	}
+
+	if stmt.Source != nil {
+		return f.withClause(stmt.With) + head + "\n" + f.selectStatement(stmt.Source)
+	}
+
+	rows := make([]string, len(stmt.Values))
+	for i, row := range stmt.Values {
+		rows[i] = f.indent + "(" + f.expressionList(row) + ")"
+	}
+	text := f.withClause(stmt.With) + head + "\n" + f.kw("VALUES") + "\n" + strings.Join(rows, ",\n")
+	if stmt.Option != nil {
+		text += "\n" + f.optionClause(stmt.Option)
+	}
+	return text
+}
+
+func (f *formatter) updateStatement(stmt *parser.UpdateStatement) string {
+	lines := []string{f.kw("UPDATE") + " " + f.tableReference(&stmt.Table)}
+
+	assignments := make([]parser.Expression, 0, len(stmt.Set))
+	for _, assignment := range stmt.Set {
+		assignments = append(assignments, &parser.BinaryExpression{
+			Left:     assignment.Column,
+			Operator: "=",
+			Right:    assignment.Value,
+		})
+	}
+	lines = append(lines, f.list(f.kw("SET"), assignments))
+
+	if stmt.Output != nil {
+		lines = append(lines, f.outputClause(stmt.Output))
+	}
+	if stmt.From != nil {
+		lines = append(lines, f.fromClause(stmt.From))
+	}
+	for _, join := range stmt.Joins {
+		lines = append(lines, f.joinClause(join))
+	}
+	if stmt.Where != nil {
+		lines = append(lines, f.condition("WHERE", stmt.Where))
+	}
+	if stmt.Option != nil {
+		lines = append(lines, f.optionClause(stmt.Option))
+	}
+
+	return f.withClause(stmt.With) + strings.Join(lines, "\n")
+}
+
+func (f *formatter) deleteStatement(stmt *parser.DeleteStatement) string {
+	head := f.kw("DELETE")
+	if stmt.Top != nil {
+		head += fmt.Sprintf(" %s (%d)", f.kw("TOP"), stmt.Top.Count)
+		if stmt.Top.Percent {
+			head += " " + f.kw("PERCENT")
+		}
+	}
+	lines := []string{head + " " + f.kw("FROM") + " " + f.tableReference(&stmt.From)}
+	if stmt.Output != nil {
+		lines = append(lines, f.outputClause(stmt.Output))
+	}
+	if stmt.Where != nil {
+		lines = append(lines, f.condition("WHERE", stmt.Where))
+	}
+	if stmt.Option != nil {
+		lines = append(lines, f.optionClause(stmt.Option))
+	}
+
+	return f.withClause(stmt.With) + strings.Join(lines, "\n")
+}
+
+func (f *formatter) outputClause(output *parser.OutputClause) string {
+	text := f.list(f.kw("OUTPUT"), output.Columns)
+	if output.Into != nil {
+		text += "\n" + f.kw("INTO") + " " + f.tableReference(output.Into)
+		if len(output.IntoColumns) > 0 {
+			text += " (" + identList(output.IntoColumns) + ")"
+		}
+	}
+	return text
+}
+
+func (f *formatter) groupByClause(groupBy *parser.GroupByClause) string {
+	text := f.kw("GROUP BY") + " "
+	if groupBy.All {
+		text += f.kw("ALL") + " "
+	}
+	text += f.expressionList(groupBy.Items)
+	if groupBy.WithRollup {
+		text += " " + f.kw("WITH ROLLUP")
+	}
+	if groupBy.WithCube {
+		text += " " + f.kw("WITH CUBE")
+	}
+	return text
+}
+
+func (f *formatter) forClause(clause *parser.ForClause) string {
+	text := f.kw("FOR " + clause.Format + " " + clause.Mode)
+	if clause.ElementName != nil {
+		text += "(" + f.expression(clause.ElementName) + ")"
+	}
+	for _, option := range clause.Options {
+		text += ", " + f.kw(option.Name)
+		if option.Argument != nil {
+			text += "(" + f.expression(option.Argument) + ")"
+		}
+	}
+	return text
+}
+
+func (f *formatter) optionClause(option *parser.OptionClause) string {
+	hints := make([]string, len(option.Hints))
+	for i, hint := range option.Hints {
+		text := f.kw(hint.Name)
+		switch {
+		case hint.Value != nil && hint.Equals:
+			text += " = " + f.expression(hint.Value)
+		case hint.Value != nil:
+			text += " " + f.expression(hint.Value)
+		case len(hint.Variables) > 0:
+			variables := make([]string, len(hint.Variables))
+			for j, variable := range hint.Variables {
+				if variable.Value == nil {
+					variables[j] = variable.Name + " " + f.kw("UNKNOWN")
+				} else {
+					variables[j] = variable.Name + " = " + f.expression(variable.Value)
+				}
+			}
+			text += " (" + strings.Join(variables, ", ") + ")"
+		case len(hint.Arguments) > 0:
+			text += " (" + f.expressionList(hint.Arguments) + ")"
+		}
+		hints[i] = text
+	}
+	return f.kw("OPTION") + " (" + strings.Join(hints, ", ") + ")"
+}
+
+func (f *formatter) mergeStatement(stmt *parser.MergeStatement) string {
+	lines := []string{
+		f.kw("MERGE INTO") + " " + f.tableReference(&stmt.Target),
+		f.kw("USING") + " " + f.tableReference(&stmt.Source),
+		f.kw("ON") + " " + f.expression(stmt.Condition),
+	}
+	for _, clause := range stmt.Clauses {
+		lines = append(lines, f.mergeWhenClause(clause))
+	}
+
+	return f.withClause(stmt.With) + strings.Join(lines, "\n")
+}
+
+func (f *formatter) createViewStatement(stmt *parser.CreateViewStatement) string {
+	head := f.kw("CREATE VIEW")
+	if stmt.OrAlter {
+		head = f.kw("CREATE OR ALTER VIEW")
+	}
+	head += " " + f.tableReference(&stmt.View)
+	if len(stmt.Columns) > 0 {
+		head += " (" + identList(stmt.Columns) + ")"
+	}
+	if stmt.SchemaBinding {
+		head += " " + f.kw("WITH SCHEMABINDING")
+	}
+	return head + "\n" + f.kw("AS") + "\n" + f.statement(stmt.Query)
+}
+
+// mergeWhenClause renders the WHEN ... THEN line with its action indented below
+func (f *formatter) mergeWhenClause(clause *parser.MergeWhenClause) string {
+	head := f.kw("WHEN MATCHED")
+	switch {
+	case !clause.Matched && clause.BySource:
+		head = f.kw("WHEN NOT MATCHED BY SOURCE")
+	case !clause.Matched:
+		head = f.kw("WHEN NOT MATCHED")
+	}
+	if clause.Condition != nil {
+		head += " " + f.kw("AND") + " " + f.expression(clause.Condition)
+	}
+	head += " " + f.kw("THEN")
+
+	var action string
+	switch clause.Action {
+	case "UPDATE":
+		assignments := make([]string, len(clause.Set))
+		for i, assignment := range clause.Set {
+			assignments[i] = f.expression(assignment.Column) + " = " + f.expression(assignment.Value)
+		}
+		action = f.kw("UPDATE SET") + " " + strings.Join(assignments, ", ")
+	case "INSERT":
+		action = f.kw("INSERT")
+		if len(clause.Columns) > 0 {
+			action += " (" + identList(clause.Columns) + ")"
+		}
+		action += " " + f.kw("VALUES") + " (" + f.expressionList(clause.Values) + ")"
+	default:
+		action = f.kw(clause.Action)
+	}
+
+	return head + "\n" + f.indentBlock(action)
+}
+
+func (f *formatter) topClause(top *parser.TopClause) string {
+	if top.Percent {
+		return fmt.Sprintf("%s %d %s", f.kw("TOP"), top.Count, f.kw("PERCENT"))
+	}
+	return fmt.Sprintf("%s %d", f.kw("TOP"), top.Count)
+}
+
+// list renders a keyword followed by a comma-separated list, either inline
+// or with one item per indented line depending on ColumnsOnNewLines
+func (f *formatter) list(keyword string, items []parser.Expression) string {
+	if !f.opts.ColumnsOnNewLines {
+		return keyword + " " + f.expressionList(items)
+	}
+
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = f.indentBlock(f.expression(item))
+	}
+	return keyword + "\n" + strings.Join(parts, ",\n")
+}
+
+// condition renders WHERE/HAVING with top-level AND/OR operands on their own lines
+func (f *formatter) condition(keyword string, expr parser.Expression) string {
+	be, ok := expr.(*parser.BinaryExpression)
+	if !ok || !isLogicalOperator(be.Operator) {
+		return f.kw(keyword) + " " + f.expression(expr)
+	}
+
+	operands := flattenLogical(be, be.Operator)
+	prec := operatorPrecedence(be.Operator)
+
+	var sb strings.Builder
+	sb.WriteString(f.kw(keyword))
+	sb.WriteString(" ")
+	sb.WriteString(f.operand(operands[0], prec, false))
+	for _, operand := range operands[1:] {
+		sb.WriteString("\n")
+		sb.WriteString(f.indent)
+		sb.WriteString(f.kw(be.Operator))
+		sb.WriteString(" ")
+		sb.WriteString(f.operand(operand, prec, true))
+	}
+	return sb.String()
+}
+
+func (f *formatter) fromClause(from *parser.FromClause) string {
+	tables := make([]string, len(from.Tables))
+	for i := range from.Tables {
+		tables[i] = f.tableReference(&from.Tables[i])
+	}
+	return f.kw("FROM") + " " + strings.Join(tables, ", ")
+}
+
+func (f *formatter) tableReference(table *parser.TableReference) string {
+	var name string
+	switch {
+	case table.Subquery != nil:
+		name = f.nested(table.Subquery)
+	case table.Values != nil:
+		rows := make([]string, len(table.Values.Rows))
+		for i, row := range table.Values.Rows {
+			rows[i] = "(" + f.expressionList(row) + ")"
+		}
+		name = "(" + f.kw("VALUES") + " " + strings.Join(rows, ", ") + ")"
+	case table.Function != nil:
+		name = f.expression(table.Function)
+	default:
+		name = table.QualifiedName()
+	}
+	if table.Alias != "" {
+		name += " " + f.kw("AS") + " " + parser.QuoteIdent(table.Alias)
+	}
+	if len(table.Columns) > 0 {
+		name += "(" + identList(table.Columns) + ")"
+	}
+	if sample := table.TableSample; sample != nil {
+		name += " " + f.kw("TABLESAMPLE")
+		if sample.System {
+			name += " " + f.kw("SYSTEM")
+		}
+		size := f.expression(sample.Size)
+		if sample.Unit != "" {
+			size += " " + f.kw(sample.Unit)
+		}
+		name += " (" + size + ")"
+		if sample.Repeatable != nil {
+			name += " " + f.kw("REPEATABLE") + " (" + f.expression(sample.Repeatable) + ")"
+		}
+	}
+	if len(table.Hints) > 0 {
+		name += " " + f.kw("WITH") + " (" + strings.Join(table.Hints, ", ") + ")"
+	}
+	if pivot := table.Pivot; pivot != nil {
+		name += fmt.Sprintf(" %s (%s %s %s %s (%s)) %s %s", f.kw("PIVOT"), f.expression(pivot.Aggregate),
+			f.kw("FOR"), f.expression(pivot.Column), f.kw("IN"), identList(pivot.Values), f.kw("AS"), parser.QuoteIdent(pivot.Alias))
+	}
+	if unpivot := table.Unpivot; unpivot != nil {
+		name += fmt.Sprintf(" %s (%s %s %s %s (%s)) %s %s", f.kw("UNPIVOT"), parser.QuoteIdent(unpivot.ValueColumn),
+			f.kw("FOR"), parser.QuoteIdent(unpivot.NameColumn), f.kw("IN"), identList(unpivot.Columns), f.kw("AS"), parser.QuoteIdent(unpivot.Alias))
+	}
+	return name
+}
+
+func (f *formatter) joinClause(join *parser.JoinClause) string {
+	switch join.JoinType {
+	case "CROSS APPLY", "OUTER APPLY":
+		return f.kw(join.JoinType) + " " + f.tableReference(&join.Table)
+	}
+	text := f.kw(join.JoinType+" JOIN") + " " + f.tableReference(&join.Table)
+	if join.Condition != nil {
+		text += " " + f.kw("ON") + " " + f.expression(join.Condition)
+	}
+	return text
+}
+
+func (f *formatter) expressionList(exprs []parser.Expression) string {
+	parts := make([]string, len(exprs))
+	for i, expr := range exprs {
+		parts[i] = f.expression(expr)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// identList renders a comma-separated list of quoted names
+func identList(names []string) string {
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = parser.QuoteIdent(name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (f *formatter) groupings(groups [][]parser.Expression) string {
+	parts := make([]string, len(groups))
+	for i, group := range groups {
+		if len(group) == 1 {
+			parts[i] = f.expression(group[0])
+		} else {
+			parts[i] = "(" + f.expressionList(group) + ")"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (f *formatter) orderByList(items []*parser.OrderByClause) string {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = f.expression(item.Expression)
+		if item.Collation != "" {
+			parts[i] += " " + f.kw("COLLATE") + " " + item.Collation
+		}
+		if item.Direction != "" {
+			parts[i] += " " + f.kw(item.Direction)
+		}
+		if item.Nulls != "" {
+			parts[i] += " " + f.kw("NULLS "+item.Nulls)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (f *formatter) expression(expr parser.Expression) string {
+	switch e := expr.(type) {
+	case *parser.BinaryExpression:
+		prec := operatorPrecedence(e.Operator)
+		return fmt.Sprintf("%s %s %s", f.operand(e.Left, prec, false), f.kw(e.Operator), f.operand(e.Right, prec, true))
+	case *parser.UnaryExpression:
+		if strings.EqualFold(e.Operator, "NOT") {
+			return f.kw("NOT") + " " + f.operand(e.Operand, operatorPrecedence("="), false)
+		}
+		if _, ok := e.Operand.(*parser.UnaryExpression); ok {
+			return e.Operator + "(" + f.expression(e.Operand) + ")"
+		}
+		return e.Operator + f.operand(e.Operand, prefixPrecedence, false)
+	case *parser.AliasedExpression:
+		return f.expression(e.Expression) + " " + f.kw("AS") + " " + parser.QuoteIdent(e.Alias)
+	case *parser.CastExpression:
+		dataType := f.kw(e.DataType.String())
+		switch {
+		case e.Function == "CAST" || e.Function == "TRY_CAST":
+			return fmt.Sprintf("%s(%s %s %s)", f.kw(e.Function), f.expression(e.Expression), f.kw("AS"), dataType)
+		case e.Style != nil:
+			return fmt.Sprintf("%s(%s, %s, %s)", f.kw(e.Function), dataType, f.expression(e.Expression), f.expression(e.Style))
+		default:
+			return fmt.Sprintf("%s(%s, %s)", f.kw(e.Function), dataType, f.expression(e.Expression))
+		}
+	case *parser.FunctionCall:
+		args := f.expressionList(e.Arguments)
+		if e.Distinct {
+			args = f.kw("DISTINCT") + " " + args
+		}
+		call := fmt.Sprintf("%s(%s)", e.Name, args)
+		if e.Over != nil {
+			call += " " + f.windowSpec(e.Over)
+		}
+		return call
+	case *parser.InExpression:
+		operator := f.kw("IN")
+		if e.Not {
+			operator = f.kw("NOT IN")
+		}
+		if e.Subquery != nil {
+			return fmt.Sprintf("%s %s %s", f.expression(e.Expression), operator, f.nested(e.Subquery))
+		}
+		return fmt.Sprintf("%s %s (%s)", f.expression(e.Expression), operator, f.expressionList(e.Values))
+	case *parser.ExistsExpression:
+		if e.Not {
+			return f.kw("NOT EXISTS") + " " + f.nested(e.Subquery)
+		}
+		return f.kw("EXISTS") + " " + f.nested(e.Subquery)
+	case *parser.SubqueryExpression:
+		return f.nested(e.Query)
+	case *parser.RowValueExpression:
+		return "(" + f.expressionList(e.Values) + ")"
+	case *parser.BetweenExpression:
+		operator := f.kw("BETWEEN")
+		if e.Not {
+			operator = f.kw("NOT BETWEEN")
+		}
+		return fmt.Sprintf("%s %s %s %s %s", f.expression(e.Expr), operator, f.expression(e.Lower), f.kw("AND"), f.expression(e.Upper))
+	case *parser.LikeExpression:
+		operator := f.kw("LIKE")
+		if e.Not {
+			operator = f.kw("NOT LIKE")
+		}
+		like := fmt.Sprintf("%s %s %s", f.expression(e.Expr), operator, f.expression(e.Pattern))
+		if e.Escape != nil {
+			like += " " + f.kw("ESCAPE") + " " + f.expression(e.Escape)
+		}
+		return like
+	case *parser.CollateExpression:
+		// COLLATE binds tighter than any infix operator
+		return f.operand(e.Expr, prefixPrecedence, false) + " " + f.kw("COLLATE") + " " + e.Collation
+	case *parser.FullTextPredicate:
+		target := f.expressionList(e.Columns)
+		if len(e.Columns) > 1 {
+			target = "(" + target + ")"
+		}
+		pred := fmt.Sprintf("%s(%s, %s", f.kw(e.Function), target, f.expression(e.Search))
+		if e.Language != nil {
+			pred += ", " + f.kw("LANGUAGE") + " " + f.expression(e.Language)
+		}
+		return pred + ")"
+	case *parser.IsNullExpression:
+		if e.Negated {
+			return f.expression(e.Expr) + " " + f.kw("IS NOT NULL")
+		}
+		return f.expression(e.Expr) + " " + f.kw("IS NULL")
+	case *parser.CaseExpression:
+		return f.caseExpression(e)
+	case *parser.RollupExpression:
+		return f.kw("ROLLUP") + "(" + f.groupings(e.Groups) + ")"
+	case *parser.CubeExpression:
+		return f.kw("CUBE") + "(" + f.groupings(e.Groups) + ")"
+	case *parser.GroupingSetsExpression:
+		return f.kw("GROUPING SETS") + "(" + f.groupings(e.Sets) + ")"
+	case *parser.Literal:
+		if e.IsNull() {
+			return f.kw("NULL")
+		}
+		if _, ok := e.Value.(bool); ok {
+			return f.kw(e.String())
+		}
+		return e.String()
+	default:
+		return expr.String()
+	}
+}
+
+// operand renders a child of a binary expression, adding parentheses only
+// where operator precedence requires them
+func (f *formatter) operand(expr parser.Expression, parentPrec int, right bool) string {
+	if ue, ok := expr.(*parser.UnaryExpression); ok && strings.EqualFold(ue.Operator, "NOT") && parentPrec > operatorPrecedence("AND") {
+		return "(" + f.expression(expr) + ")"
+	}
+	be, ok := expr.(*parser.BinaryExpression)
+	if !ok {
+		return f.expression(expr)
+	}
+	prec := operatorPrecedence(be.Operator)
+	if prec < parentPrec || (right && prec == parentPrec && !isLogicalOperator(be.Operator)) {
+		return "(" + f.expression(expr) + ")"
+	}
+	return f.expression(expr)
+}
+
+func (f *formatter) windowSpec(spec *parser.WindowSpec) string {
+	var parts []string
+	if len(spec.PartitionBy) > 0 {
+		parts = append(parts, f.kw("PARTITION BY")+" "+f.expressionList(spec.PartitionBy))
+	}
+	if len(spec.OrderBy) > 0 {
+		parts = append(parts, f.kw("ORDER BY")+" "+f.orderByList(spec.OrderBy))
+	}
+	return f.kw("OVER") + " (" + strings.Join(parts, " ") + ")"
+}
+
+func (f *formatter) caseExpression(ce *parser.CaseExpression) string {
+	var sb strings.Builder
+	sb.WriteString(f.kw("CASE"))
+	if ce.Operand != nil {
+		sb.WriteString(" ")
+		sb.WriteString(f.expression(ce.Operand))
+	}
+	for _, when := range ce.WhenClauses {
+		sb.WriteString(" ")
+		sb.WriteString(f.kw("WHEN"))
+		sb.WriteString(" ")
+		sb.WriteString(f.expression(when.Condition))
+		sb.WriteString(" ")
+		sb.WriteString(f.kw("THEN"))
+		sb.WriteString(" ")
+		sb.WriteString(f.expression(when.Result))
+	}
+	if ce.Else != nil {
+		sb.WriteString(" ")
+		sb.WriteString(f.kw("ELSE"))
+		sb.WriteString(" ")
+		sb.WriteString(f.expression(ce.Else))
+	}
+	sb.WriteString(" ")
+	sb.WriteString(f.kw("END"))
+	return sb.String()
+}
+
+func isLogicalOperator(op string) bool {
+	switch strings.ToUpper(op) {
+	case "AND", "OR":
+		return true
+	}
+	return false
+}
+
+// flattenLogical collects the operands of a chain of the same AND/OR operator
+func flattenLogical(expr parser.Expression, op string) []parser.Expression {
+	be, ok := expr.(*parser.BinaryExpression)
+	if !ok || !strings.EqualFold(be.Operator, op) {
+		return []parser.Expression{expr}
+	}
+	return append(flattenLogical(be.Left, op), flattenLogical(be.Right, op)...)
+}
+
+// prefixPrecedence binds unary -, + and ~ tighter than any binary operator
+const prefixPrecedence = 7
+
+func operatorPrecedence(op string) int {
+	switch strings.ToUpper(op) {
+	case "OR":
+		return 1
+	case "AND":
+		return 2
+	case "&", "|", "^":
+		return 4
+	case "+", "-", "||":
+		return 5
+	case "*", "/", "%":
+		return 6
+	default:
+		return 3 // comparisons and LIKE
+	}
+}
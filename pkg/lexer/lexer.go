@@ -2,6 +2,8 @@
 package lexer
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/Chahine-tech/sql-parser-go/pkg/dialect"
@@ -14,7 +16,20 @@ type Lexer struct {
 	ch           byte
 	line         int
 	column       int
+	lastLine     int // line and column of the previous character
+	lastColumn   int
 	dialect      dialect.Dialect
+
+	// Emit COMMENT tokens instead of skipping comments
+	preserveComments bool
+
+	// Lex "..." as an identifier rather than a string literal
+	quotedIdentifiers bool
+
+	// Only match keywords written in upper case
+	caseSensitiveKeywords bool
+
+	errors []string
 }
 
 func New(input string) *Lexer {
@@ -23,21 +38,90 @@ func New(input string) *Lexer {
 
 func NewWithDialect(input string, d dialect.Dialect) *Lexer {
 	l := &Lexer{
-		input:   input,
-		line:    1,
-		column:  0,
-		dialect: d,
+		input:             input,
+		line:              1,
+		column:            0,
+		dialect:           d,
+		quotedIdentifiers: d.Name() == "PostgreSQL" || d.Name() == "SQLite" || d.Name() == "Oracle",
 	}
 	l.readChar()
 	return l
 }
 
+// LexerOptions configures a lexer created with NewWithOptions. The zero value
+// gives the same lexer as New.
+type LexerOptions struct {
+	// Dialect defaults to SQL Server
+	Dialect dialect.Dialect
+
+	// CaseSensitiveKeywords makes only upper-case words keywords, so that
+	// "select" is an identifier. By default keywords match in any case.
+	// Identifier literals always keep their original case.
+	CaseSensitiveKeywords bool
+
+	// PreserveComments returns comments as COMMENT tokens
+	PreserveComments bool
+
+	// QuotedIdentifier lexes "..." as an identifier rather than a string
+	// literal, as SET QUOTED_IDENTIFIER ON does. Dialects that always quote
+	// identifiers this way (PostgreSQL, SQLite and Oracle) do so regardless.
+	QuotedIdentifier bool
+}
+
+// NewWithOptions creates a lexer for input configured by opts
+func NewWithOptions(input string, opts LexerOptions) *Lexer {
+	d := opts.Dialect
+	if d == nil {
+		d = dialect.GetDialect("sqlserver")
+	}
+	l := NewWithDialect(input, d)
+	l.caseSensitiveKeywords = opts.CaseSensitiveKeywords
+	l.preserveComments = opts.PreserveComments
+	if opts.QuotedIdentifier {
+		l.quotedIdentifiers = true
+	}
+	return l
+}
+
+// Reset reinitializes the lexer to tokenize input, keeping its dialect and
+// comment, quoting and keyword settings
+func (l *Lexer) Reset(input string) {
+	l.input = input
+	l.position = 0
+	l.readPosition = 0
+	l.line = 1
+	l.column = 0
+	l.lastLine = 0
+	l.lastColumn = 0
+	l.errors = l.errors[:0]
+	l.readChar()
+}
+
+// SetPreserveComments controls whether comments are returned as COMMENT tokens
+// (useful for formatters) or skipped like whitespace, which is the default
+func (l *Lexer) SetPreserveComments(preserve bool) {
+	l.preserveComments = preserve
+}
+
+// Errors returns the lexical errors encountered so far
+func (l *Lexer) Errors() []string {
+	return l.errors
+}
+
+// SetQuotedIdentifier mirrors T-SQL SET QUOTED_IDENTIFIER: when on, "..." is
+// lexed as an identifier, otherwise as a string literal. The default depends
+// on the dialect (on for PostgreSQL, SQLite and Oracle).
+func (l *Lexer) SetQuotedIdentifier(on bool) {
+	l.quotedIdentifiers = on
+}
+
 func (l *Lexer) readChar() {
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
 		l.ch = l.input[l.readPosition]
 	}
+	l.lastLine, l.lastColumn = l.line, l.column
 	l.position = l.readPosition
 	l.readPosition++
 
@@ -73,16 +157,47 @@ func (l *Lexer) peekChar() byte {
 	return l.input[l.readPosition]
 }
 
+// peekCharAt returns the character n positions after the next one without consuming input
+func (l *Lexer) peekCharAt(n int) byte {
+	if l.readPosition+n >= len(l.input) {
+		return 0
+	}
+	return l.input[l.readPosition+n]
+}
+
 func (l *Lexer) NextToken() Token {
-	var tok Token
+	for {
+		l.skipWhitespace()
+
+		// Handle -- line comments and /* */ block comments
+		if (l.ch == '-' && l.peekChar() == '-') || (l.ch == '/' && l.peekChar() == '*') {
+			tok := l.readComment()
+			if l.preserveComments || tok.Type == ILLEGAL {
+				l.setEnd(&tok)
+				return tok
+			}
+			continue
+		}
+		break
+	}
 
-	l.skipWhitespace()
+	position, line, column := l.position, l.line, l.column
+	tok := l.readToken()
+	tok.Position, tok.Line, tok.Column = position, line, column
+	l.setEnd(&tok)
+	return tok
+}
 
-	// Handle line comments
-	if l.ch == '-' && l.peekChar() == '-' {
-		l.skipLineComment()
-		return l.NextToken()
-	}
+// setEnd records the position just past the last character read
+func (l *Lexer) setEnd(tok *Token) {
+	tok.EndPosition = l.position
+	tok.EndLine = l.lastLine
+	tok.EndColumn = l.lastColumn + 1
+}
+
+// readToken reads the token starting at the current character
+func (l *Lexer) readToken() Token {
+	var tok Token
 
 	switch l.ch {
 	case '=':
@@ -121,6 +236,16 @@ func (l *Lexer) NextToken() Token {
 		} else {
 			tok = newToken(GT, l.ch, l.position, l.line, l.column)
 		}
+	case ':':
+		// Securable class qualifier, e.g. SCHEMA::Sales
+		if l.peekChar() == ':' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = Token{Type: DOUBLE_COLON, Literal: literal, Position: l.position, Line: l.line, Column: l.column}
+		} else {
+			tok = newToken(ILLEGAL, l.ch, l.position, l.line, l.column)
+		}
 	case ',':
 		tok = newToken(COMMA, l.ch, l.position, l.line, l.column)
 	case ';':
@@ -130,6 +255,13 @@ func (l *Lexer) NextToken() Token {
 	case ')':
 		tok = newToken(RPAREN, l.ch, l.position, l.line, l.column)
 	case '.':
+		// A dot directly followed by a digit starts a float like .25, unless it
+		// qualifies a preceding name
+		if isDigit(l.peekChar()) && !l.followsName() {
+			tok.Type = NUMBER
+			tok.Literal = l.readNumber()
+			return tok
+		}
 		tok = newToken(DOT, l.ch, l.position, l.line, l.column)
 	case '*':
 		tok = newToken(ASTERISK, l.ch, l.position, l.line, l.column)
@@ -141,20 +273,67 @@ func (l *Lexer) NextToken() Token {
 		tok = newToken(SLASH, l.ch, l.position, l.line, l.column)
 	case '%':
 		tok = newToken(PERCENT, l.ch, l.position, l.line, l.column)
+	case '&':
+		tok = newToken(AMPERSAND, l.ch, l.position, l.line, l.column)
+	case '|':
+		tok = newToken(PIPE, l.ch, l.position, l.line, l.column)
+	case '^':
+		tok = newToken(CARET, l.ch, l.position, l.line, l.column)
+	case '~':
+		tok = newToken(TILDE, l.ch, l.position, l.line, l.column)
+	case '?':
+		tok = newToken(PLACEHOLDER, l.ch, l.position, l.line, l.column)
+	case '@':
+		// T-SQL named parameters and variables (@name) and system
+		// variables (@@ROWCOUNT)
+		if isLetter(l.peekChar()) || (l.peekChar() == '@' && isLetter(l.peekCharAt(1))) {
+			tok.Position = l.position
+			tok.Line = l.line
+			tok.Column = l.column
+			tok.Type = PARAMETER
+			if l.peekChar() == '@' {
+				tok.Type = SYSTEM_VARIABLE
+			}
+			tok.Literal = l.readParameter()
+			return tok
+		}
+		tok = newToken(ILLEGAL, l.ch, l.position, l.line, l.column)
+	case '#':
+		// SQL Server temporary tables (#name, ##name)
+		if l.dialect.Name() == "SQL Server" && (isLetter(l.peekChar()) || l.peekChar() == '#') {
+			tok.Position = l.position
+			tok.Line = l.line
+			tok.Column = l.column
+			tok.Type = IDENT
+			tok.Literal = l.readTempTableName()
+			return tok
+		}
+		tok = newToken(ILLEGAL, l.ch, l.position, l.line, l.column)
 	case '\'':
+		line, column := l.line, l.column
+		literal, terminated := l.readString()
 		tok.Type = STRING
-		tok.Literal = l.readString()
+		tok.Literal = literal
+		if !terminated {
+			l.unterminated(&tok, "string literal", line, column)
+		}
 		tok.Position = l.position
 		tok.Line = l.line
 		tok.Column = l.column
 	case '"':
-		// Double quotes can be string literals or identifiers depending on dialect
-		if l.dialect.Name() == "PostgreSQL" || l.dialect.Name() == "SQLite" || l.dialect.Name() == "Oracle" {
+		line, column := l.line, l.column
+		// Double quotes can be string literals or identifiers depending on QUOTED_IDENTIFIER
+		what := "string literal"
+		if l.quotedIdentifiers {
 			tok.Type = IDENT
-			tok.Literal = l.readDoubleQuotedIdentifier()
+			what = "quoted identifier"
 		} else {
 			tok.Type = STRING
-			tok.Literal = l.readDoubleQuotedString()
+		}
+		literal, terminated := l.readDoubleQuoted()
+		tok.Literal = literal
+		if !terminated {
+			l.unterminated(&tok, what, line, column)
 		}
 		tok.Position = l.position
 		tok.Line = l.line
@@ -162,8 +341,13 @@ func (l *Lexer) NextToken() Token {
 	case '`':
 		// Backticks are MySQL-specific quoted identifiers
 		if l.dialect.Name() == "MySQL" {
+			line, column := l.line, l.column
+			literal, terminated := l.readBacktickIdentifier()
 			tok.Type = IDENT
-			tok.Literal = l.readBacktickIdentifier()
+			tok.Literal = literal
+			if !terminated {
+				l.unterminated(&tok, "quoted identifier", line, column)
+			}
 		} else {
 			tok = newToken(ILLEGAL, l.ch, l.position, l.line, l.column)
 		}
@@ -172,15 +356,25 @@ func (l *Lexer) NextToken() Token {
 		tok.Column = l.column
 	case '[':
 		// Brackets are SQL Server-specific quoted identifiers
-		if l.dialect.Name() == "SQL Server" {
-			tok.Type = IDENT
-			tok.Literal = l.readBracketedIdentifier()
-		} else {
+		if l.dialect.Name() != "SQL Server" {
 			tok = newToken(ILLEGAL, l.ch, l.position, l.line, l.column)
+			break
 		}
 		tok.Position = l.position
 		tok.Line = l.line
 		tok.Column = l.column
+		literal, terminated := l.readBracketedIdentifier()
+		tok.Literal = literal
+		tok.Type = IDENT
+		switch {
+		case !terminated:
+			l.unterminated(&tok, "bracketed identifier", tok.Line, tok.Column)
+		case literal == "":
+			// SQL Server rejects [] as a zero-length identifier
+			tok.Type = ILLEGAL
+			l.errors = append(l.errors, fmt.Sprintf("empty bracketed identifier at line %d, column %d", tok.Line, tok.Column))
+		}
+		return tok
 	case 0:
 		tok.Literal = ""
 		tok.Type = EOF
@@ -188,12 +382,38 @@ func (l *Lexer) NextToken() Token {
 		tok.Line = l.line
 		tok.Column = l.column
 	default:
-		if isLetter(l.ch) {
+		if (l.ch == 'N' || l.ch == 'n') && l.peekChar() == '\'' {
+			// N'...' Unicode (national character) string literal
+			line, column := l.line, l.column
+			l.readChar()
+			literal, terminated := l.readString()
+			tok.Type = STRING
+			tok.Literal = literal
+			tok.Unicode = true
+			if !terminated {
+				l.unterminated(&tok, "string literal", line, column)
+			}
+		} else if isLetter(l.ch) {
 			tok.Position = l.position
 			tok.Line = l.line
 			tok.Column = l.column
 			tok.Literal = l.readIdentifier()
-			tok.Type = l.lookupIdent(strings.ToUpper(tok.Literal))
+			tok.Type = IDENT
+			upper := strings.ToUpper(tok.Literal)
+			if l.caseSensitiveKeywords && upper != tok.Literal {
+				return tok
+			}
+			tok.Type = l.lookupIdent(upper)
+			if upper == "GO" && l.dialect.Name() == "SQL Server" && l.atLineStart(tok.Position) {
+				if count, ok := l.batchCount(); ok {
+					tok.Type = GO
+					tok.Literal = strings.TrimSpace(l.input[tok.Position:count])
+				}
+			}
+			return tok
+		} else if l.ch == '0' && (l.peekChar() == 'x' || l.peekChar() == 'X') {
+			tok.Type = HEX
+			tok.Literal = l.readHexNumber()
 			return tok
 		} else if isDigit(l.ch) {
 			tok.Type = NUMBER
@@ -223,6 +443,48 @@ func (l *Lexer) skipLineComment() {
 	}
 }
 
+// readComment reads a line comment or a block comment. Block comments may be
+// nested as in T-SQL; an unterminated block comment yields an ILLEGAL token.
+func (l *Lexer) readComment() Token {
+	tok := Token{Type: COMMENT, Position: l.position, Line: l.line, Column: l.column}
+	position := l.position
+
+	if l.ch == '-' {
+		l.skipLineComment()
+		tok.Literal = l.input[position:l.position]
+		return tok
+	}
+
+	depth := 0
+	for l.ch != 0 {
+		switch {
+		case l.ch == '/' && l.peekChar() == '*':
+			depth++
+			l.readChar()
+		case l.ch == '*' && l.peekChar() == '/':
+			depth--
+			l.readChar()
+			if depth == 0 {
+				l.readChar()
+				tok.Literal = l.input[position:l.position]
+				return tok
+			}
+		}
+		l.readChar()
+	}
+
+	tok.Literal = l.input[position:l.position]
+	l.unterminated(&tok, "block comment", tok.Line, tok.Column)
+	return tok
+}
+
+// unterminated marks tok ILLEGAL and records an error at its start, which
+// for a token running to the end of the input is where the problem lies
+func (l *Lexer) unterminated(tok *Token, what string, line, column int) {
+	tok.Type = ILLEGAL
+	l.errors = append(l.errors, fmt.Sprintf("unterminated %s at line %d, column %d", what, line, column))
+}
+
 func (l *Lexer) readIdentifier() string {
 	position := l.position
 	for isLetter(l.ch) || isDigit(l.ch) || l.ch == '_' {
@@ -231,17 +493,89 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readBracketedIdentifier() string {
-	l.readChar()
+// atLineStart reports whether only spaces and tabs precede position on its line
+func (l *Lexer) atLineStart(position int) bool {
+	for i := position - 1; i >= 0; i-- {
+		switch l.input[i] {
+		case ' ', '\t':
+			continue
+		case '\n', '\r':
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// batchCount checks that the rest of the line after GO holds at most a repeat
+// count and a -- comment. If so, it consumes the count and returns the
+// position just past it.
+func (l *Lexer) batchCount() (int, bool) {
+	i := l.position
+	for i < len(l.input) && (l.input[i] == ' ' || l.input[i] == '\t') {
+		i++
+	}
+	end := l.position
+	if i < len(l.input) && isDigit(l.input[i]) {
+		for i < len(l.input) && isDigit(l.input[i]) {
+			i++
+		}
+		end = i
+		for i < len(l.input) && (l.input[i] == ' ' || l.input[i] == '\t') {
+			i++
+		}
+	}
+	if i < len(l.input) && l.input[i] != '\n' && l.input[i] != '\r' && !strings.HasPrefix(l.input[i:], "--") {
+		return 0, false
+	}
+
+	for l.position < end {
+		l.readChar()
+	}
+	return end, true
+}
+
+func (l *Lexer) readParameter() string {
 	position := l.position
-	for l.ch != ']' && l.ch != 0 {
+	for i := 0; i < 2 && l.ch == '@'; i++ {
 		l.readChar()
 	}
-	result := l.input[position:l.position]
-	if l.ch == ']' {
+	for isLetter(l.ch) || isDigit(l.ch) {
 		l.readChar()
 	}
-	return result
+	return l.input[position:l.position]
+}
+
+// readTempTableName reads a #name or ##name
+func (l *Lexer) readTempTableName() string {
+	position := l.position
+	for i := 0; i < 2 && l.ch == '#'; i++ {
+		l.readChar()
+	}
+	for isLetter(l.ch) || isDigit(l.ch) {
+		l.readChar()
+	}
+	return l.input[position:l.position]
+}
+
+// readBracketedIdentifier reads [name], where ]] stands for a literal ].
+// It reports false if the input ends before the closing bracket.
+func (l *Lexer) readBracketedIdentifier() (string, bool) {
+	var sb strings.Builder
+	l.readChar() // skip the opening bracket
+	for l.ch != 0 {
+		if l.ch == ']' {
+			if l.peekChar() != ']' {
+				l.readChar() // skip the closing bracket
+				return sb.String(), true
+			}
+			l.readChar() // collapse the ]] escape
+		}
+		sb.WriteByte(l.ch)
+		l.readChar()
+	}
+	return sb.String(), false
 }
 
 func (l *Lexer) readNumber() string {
@@ -257,45 +591,119 @@ func (l *Lexer) readNumber() string {
 		}
 	}
 
+	// Exponent: e or E, an optional sign, then at least one digit
+	if l.ch == 'e' || l.ch == 'E' {
+		if isDigit(l.peekChar()) || ((l.peekChar() == '+' || l.peekChar() == '-') && isDigit(l.peekCharAt(1))) {
+			l.readChar()
+			if l.ch == '+' || l.ch == '-' {
+				l.readChar()
+			}
+			for isDigit(l.ch) {
+				l.readChar()
+			}
+		}
+	}
+
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readString() string {
-	l.readChar() // skip the opening quote
+// readHexNumber reads a 0x-prefixed binary literal; 0x alone is an empty value
+func (l *Lexer) readHexNumber() string {
 	position := l.position
-	for l.ch != '\'' && l.ch != 0 {
-		if l.ch == '\\' {
-			l.readChar() // skip escape character
-		}
+	l.readChar() // 0
+	l.readChar() // x
+	for isHexDigit(l.ch) {
 		l.readChar()
 	}
-	result := l.input[position:l.position]
-	return result
+	return l.input[position:l.position]
 }
 
-func (l *Lexer) readDoubleQuotedString() string {
-	position := l.position + 1
-	for {
-		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
+// followsName reports whether the current character directly follows an
+// identifier or quoted name, as the dot in t.1 or [t].[1] does
+func (l *Lexer) followsName() bool {
+	if l.position == 0 {
+		return false
+	}
+	prev := l.input[l.position-1]
+	return isLetter(prev) || isDigit(prev) || prev == ']' || prev == '"' || prev == '`'
+}
+
+// readString reads a single-quoted string, where a doubled quote stands for an
+// embedded quote. A backslash is an ordinary character, as in standard SQL and
+// T-SQL, except in MySQL where it starts an escape sequence such as \' or \n.
+// It reports false if the input ends before the closing quote.
+func (l *Lexer) readString() (string, bool) {
+	l.readChar() // skip the opening quote
+	position := l.position
+	backslashEscapes := l.dialect.Name() == "MySQL"
+
+	// Escapes are rare, so the value is only copied once one is found
+	var sb strings.Builder
+	escaped := false
+	segment := position
+	for l.ch != 0 {
+		if l.ch == '\'' && l.peekChar() != '\'' {
 			break
 		}
+		if l.ch == '\'' || (backslashEscapes && l.ch == '\\' && l.peekChar() != 0) {
+			sb.WriteString(l.input[segment:l.position])
+			l.readChar()
+			if l.ch == '\'' {
+				sb.WriteByte('\'')
+			} else {
+				sb.WriteByte(unescapeBackslash(l.ch))
+			}
+			escaped = true
+			l.readChar()
+			segment = l.position
+			continue
+		}
+		l.readChar()
 	}
-	return l.input[position:l.position]
+
+	terminated := l.ch == '\''
+	if !escaped {
+		return l.input[position:l.position], terminated
+	}
+	sb.WriteString(l.input[segment:l.position])
+	return sb.String(), terminated
 }
 
-func (l *Lexer) readDoubleQuotedIdentifier() string {
-	position := l.position + 1
+// unescapeBackslash returns the character a MySQL backslash escape stands for
+func unescapeBackslash(ch byte) byte {
+	switch ch {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case '0':
+		return 0
+	case 'b':
+		return '\b'
+	case 'Z':
+		return 26 // Ctrl+Z
+	}
+	return ch
+}
+
+// readDoubleQuoted reads "...", where "" stands for an embedded quote
+func (l *Lexer) readDoubleQuoted() (string, bool) {
+	var sb strings.Builder
 	for {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
+		if l.ch == '"' && l.peekChar() == '"' {
+			l.readChar()
+		} else if l.ch == '"' || l.ch == 0 {
 			break
 		}
+		sb.WriteByte(l.ch)
 	}
-	return l.input[position:l.position]
+	return sb.String(), l.ch == '"'
 }
 
-func (l *Lexer) readBacktickIdentifier() string {
+func (l *Lexer) readBacktickIdentifier() (string, bool) {
 	position := l.position + 1
 	for {
 		l.readChar()
@@ -303,7 +711,11 @@ func (l *Lexer) readBacktickIdentifier() string {
 			break
 		}
 	}
-	return l.input[position:l.position]
+	return l.input[position:l.position], l.ch == '`'
+}
+
+func isHexDigit(ch byte) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
 }
 
 func isLetter(ch byte) bool {
@@ -318,6 +730,39 @@ func newToken(tokenType TokenType, ch byte, pos, line, col int) Token {
 	return Token{Type: tokenType, Literal: string(ch), Position: pos, Line: line, Column: col}
 }
 
+// Tokenize returns every token of input up to and including EOF, with
+// comments kept as COMMENT tokens, for tools such as syntax highlighters that
+// need only lexical information. See Lexer.Tokens for error handling.
+func Tokenize(input string) ([]Token, error) {
+	l := New(input)
+	l.SetPreserveComments(true)
+	return l.Tokens()
+}
+
+// Tokens reads the rest of the input and returns its tokens up to and
+// including EOF. Lexing does not stop at a problem: it appears as an ILLEGAL
+// token, and the error describes the first one.
+func (l *Lexer) Tokens() ([]Token, error) {
+	var tokens []Token
+	var err error
+	for {
+		errCount := len(l.errors)
+		tok := l.NextToken()
+		tokens = append(tokens, tok)
+
+		if tok.Type == ILLEGAL && err == nil {
+			if len(l.errors) > errCount {
+				err = errors.New(l.errors[errCount])
+			} else {
+				err = fmt.Errorf("unexpected character %q at line %d, column %d", tok.Literal, tok.Line, tok.Column)
+			}
+		}
+		if tok.Type == EOF {
+			return tokens, err
+		}
+	}
+}
+
 // TokenizeSQL tokenizes a complete SQL string and returns all tokens
 // Optimized version with pre-allocated slice
 func TokenizeSQL(input string) []Token {
@@ -12,6 +12,16 @@ const (
 	IDENT  // table_name, column_name
 	STRING // 'hello'
 	NUMBER // 123, 123.45
+	HEX    // 0x1A2B
+
+	// Query parameters
+	PARAMETER       // @name
+	PLACEHOLDER     // ?
+	SYSTEM_VARIABLE // @@name
+
+	COMMENT // -- comment, /* comment */ (only emitted when preserving comments)
+
+	GO // SQL Server batch separator: GO [count] alone on a line
 
 	// SQL Keywords
 	SELECT
@@ -22,6 +32,9 @@ const (
 	LEFT
 	RIGHT
 	FULL
+	CROSS
+	OUTER
+	APPLY
 	ON
 	GROUP
 	BY
@@ -39,6 +52,8 @@ const (
 	OFFSET
 	UNION
 	ALL
+	EXCEPT
+	INTERSECT
 	INSERT
 	UPDATE
 	DELETE
@@ -46,6 +61,20 @@ const (
 	DROP
 	ALTER
 	TABLE
+	INTO
+	VALUES
+	SET
+	CASE
+	WHEN
+	THEN
+	ELSE
+	END
+	WITH
+	MERGE
+	USING
+	GRANT
+	DENY
+	REVOKE
 
 	// Operators
 	ASSIGN  // =
@@ -59,57 +88,87 @@ const (
 	BETWEEN // BETWEEN
 	IS      // IS
 	NULL    // NULL
+	TRUE    // TRUE
+	FALSE   // FALSE
 
 	// Delimiters
-	COMMA     // ,
-	SEMICOLON // ;
-	LPAREN    // (
-	RPAREN    // )
-	DOT       // .
-	ASTERISK  // *
-	PLUS      // +
-	MINUS     // -
-	SLASH     // /
-	PERCENT   // %
+	COMMA        // ,
+	SEMICOLON    // ;
+	LPAREN       // (
+	RPAREN       // )
+	DOT          // .
+	DOUBLE_COLON // ::
+	ASTERISK     // *
+	PLUS         // +
+	MINUS        // -
+	SLASH        // /
+	PERCENT      // %
+
+	// Bitwise operators
+	AMPERSAND // &
+	PIPE      // |
+	CARET     // ^
+	TILDE     // ~
 )
 
 var keywords = map[string]TokenType{
-	"SELECT":   SELECT,
-	"FROM":     FROM,
-	"WHERE":    WHERE,
-	"JOIN":     JOIN,
-	"INNER":    INNER,
-	"LEFT":     LEFT,
-	"RIGHT":    RIGHT,
-	"FULL":     FULL,
-	"ON":       ON,
-	"GROUP":    GROUP,
-	"BY":       BY,
-	"ORDER":    ORDER,
-	"HAVING":   HAVING,
-	"AS":       AS,
-	"AND":      AND,
-	"OR":       OR,
-	"NOT":      NOT,
-	"IN":       IN,
-	"EXISTS":   EXISTS,
-	"DISTINCT": DISTINCT,
-	"TOP":      TOP,
-	"LIMIT":    LIMIT,
-	"OFFSET":   OFFSET,
-	"UNION":    UNION,
-	"ALL":      ALL,
-	"INSERT":   INSERT,
-	"UPDATE":   UPDATE,
-	"DELETE":   DELETE,
-	"CREATE":   CREATE,
-	"DROP":     DROP,
-	"ALTER":    ALTER,
-	"TABLE":    TABLE,
-	"LIKE":     LIKE,
-	"BETWEEN":  BETWEEN,
-	"IS":       IS,
-	"NULL":     NULL,
+	"SELECT":    SELECT,
+	"FROM":      FROM,
+	"WHERE":     WHERE,
+	"JOIN":      JOIN,
+	"INNER":     INNER,
+	"LEFT":      LEFT,
+	"RIGHT":     RIGHT,
+	"FULL":      FULL,
+	"CROSS":     CROSS,
+	"OUTER":     OUTER,
+	"APPLY":     APPLY,
+	"ON":        ON,
+	"GROUP":     GROUP,
+	"BY":        BY,
+	"ORDER":     ORDER,
+	"HAVING":    HAVING,
+	"AS":        AS,
+	"AND":       AND,
+	"OR":        OR,
+	"NOT":       NOT,
+	"IN":        IN,
+	"EXISTS":    EXISTS,
+	"DISTINCT":  DISTINCT,
+	"TOP":       TOP,
+	"LIMIT":     LIMIT,
+	"OFFSET":    OFFSET,
+	"UNION":     UNION,
+	"ALL":       ALL,
+	"EXCEPT":    EXCEPT,
+	"INTERSECT": INTERSECT,
+	"INSERT":    INSERT,
+	"UPDATE":    UPDATE,
+	"DELETE":    DELETE,
+	"CREATE":    CREATE,
+	"DROP":      DROP,
+	"ALTER":     ALTER,
+	"TABLE":     TABLE,
+	"INTO":      INTO,
+	"VALUES":    VALUES,
+	"SET":       SET,
+	"CASE":      CASE,
+	"WHEN":      WHEN,
+	"THEN":      THEN,
+	"ELSE":      ELSE,
+	"END":       END,
+	"WITH":      WITH,
+	"MERGE":     MERGE,
+	"USING":     USING,
+	"GRANT":     GRANT,
+	"DENY":      DENY,
+	"REVOKE":    REVOKE,
+	"LIKE":      LIKE,
+	"BETWEEN":   BETWEEN,
+	"IS":        IS,
+	"NULL":      NULL,
+	"TRUE":      TRUE,
+	"FALSE":     FALSE,
 }
 
 type Token struct {
@@ -118,6 +177,14 @@ type Token struct {
 	Position int
 	Line     int
 	Column   int
+
+	// Position just past the last character of the token
+	EndPosition int
+	EndLine     int
+	EndColumn   int
+
+	// Unicode marks an N'...' string literal
+	Unicode bool
 }
 
 func (t Token) String() string {
@@ -146,6 +213,18 @@ func (tt TokenType) String() string {
 		return "STRING"
 	case NUMBER:
 		return "NUMBER"
+	case HEX:
+		return "HEX"
+	case PARAMETER:
+		return "PARAMETER"
+	case PLACEHOLDER:
+		return "PLACEHOLDER"
+	case SYSTEM_VARIABLE:
+		return "SYSTEM_VARIABLE"
+	case COMMENT:
+		return "COMMENT"
+	case GO:
+		return "GO"
 	case SELECT:
 		return "SELECT"
 	case FROM:
@@ -162,6 +241,12 @@ func (tt TokenType) String() string {
 		return "RIGHT"
 	case FULL:
 		return "FULL"
+	case CROSS:
+		return "CROSS"
+	case OUTER:
+		return "OUTER"
+	case APPLY:
+		return "APPLY"
 	case ON:
 		return "ON"
 	case GROUP:
@@ -196,6 +281,10 @@ func (tt TokenType) String() string {
 		return "UNION"
 	case ALL:
 		return "ALL"
+	case EXCEPT:
+		return "EXCEPT"
+	case INTERSECT:
+		return "INTERSECT"
 	case INSERT:
 		return "INSERT"
 	case UPDATE:
@@ -210,6 +299,34 @@ func (tt TokenType) String() string {
 		return "ALTER"
 	case TABLE:
 		return "TABLE"
+	case INTO:
+		return "INTO"
+	case VALUES:
+		return "VALUES"
+	case SET:
+		return "SET"
+	case CASE:
+		return "CASE"
+	case WHEN:
+		return "WHEN"
+	case THEN:
+		return "THEN"
+	case ELSE:
+		return "ELSE"
+	case END:
+		return "END"
+	case WITH:
+		return "WITH"
+	case MERGE:
+		return "MERGE"
+	case USING:
+		return "USING"
+	case GRANT:
+		return "GRANT"
+	case DENY:
+		return "DENY"
+	case REVOKE:
+		return "REVOKE"
 	case ASSIGN:
 		return "ASSIGN"
 	case EQ:
@@ -232,6 +349,10 @@ func (tt TokenType) String() string {
 		return "IS"
 	case NULL:
 		return "NULL"
+	case TRUE:
+		return "TRUE"
+	case FALSE:
+		return "FALSE"
 	case COMMA:
 		return "COMMA"
 	case SEMICOLON:
@@ -252,6 +373,16 @@ func (tt TokenType) String() string {
 		return "SLASH"
 	case PERCENT:
 		return "PERCENT"
+	case AMPERSAND:
+		return "AMPERSAND"
+	case PIPE:
+		return "PIPE"
+	case CARET:
+		return "CARET"
+	case TILDE:
+		return "TILDE"
+	case DOUBLE_COLON:
+		return "DOUBLE_COLON"
 	default:
 		return "UNKNOWN"
 	}
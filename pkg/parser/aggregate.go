@@ -0,0 +1,62 @@
+package parser
+
+import "strings"
+
+// aggregateFunctions lists the standard aggregate functions
+var aggregateFunctions = map[string]bool{
+	"COUNT": true,
+	"SUM":   true,
+	"AVG":   true,
+	"MIN":   true,
+	"MAX":   true,
+}
+
+// ContainsAggregate reports whether expr calls an aggregate function.
+// Window functions (calls with an OVER clause) and subqueries are not counted,
+// as they don't require the enclosing query to be grouped.
+func ContainsAggregate(expr Expression) bool {
+	found := false
+	inspectAggregates(expr, func(*FunctionCall) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// AggregateFunctions returns the upper-cased names of the aggregate functions
+// called in expr, in order of first appearance
+func AggregateFunctions(expr Expression) []string {
+	var names []string
+	seen := make(map[string]bool)
+	inspectAggregates(expr, func(fc *FunctionCall) bool {
+		name := strings.ToUpper(fc.Name)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+		return true
+	})
+	return names
+}
+
+// inspectAggregates calls f for each aggregate call in expr until f returns false
+func inspectAggregates(expr Expression, f func(*FunctionCall) bool) {
+	if expr == nil {
+		return
+	}
+	done := false
+	Inspect(expr, func(node Node) bool {
+		if done {
+			return false
+		}
+		switch n := node.(type) {
+		case *SelectStatement, *SetOperation:
+			return false
+		case *FunctionCall:
+			if n.Over == nil && aggregateFunctions[strings.ToUpper(n.Name)] {
+				done = !f(n)
+			}
+		}
+		return !done
+	})
+}
@@ -1,15 +1,25 @@
 package parser
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/lexer"
+)
 
 type Node interface {
 	String() string
 	Type() string
+	Span() (start, end Position)
 }
 
 type Statement interface {
 	Node
 	statementNode()
+	// Clone returns a deep copy that shares no nodes with the original
+	Clone() Statement
 }
 
 type Expression interface {
@@ -17,8 +27,23 @@ type Expression interface {
 	expressionNode()
 }
 
+// Position is a location in the source text. Line and Column are 1-based,
+// Offset is the byte offset from the start of the input.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
 // Base node implementation
-type BaseNode struct{}
+type BaseNode struct {
+	Pos Position // first character of the node
+	End Position // just past the last character of the node
+}
 
 func (bn *BaseNode) String() string {
 	return ""
@@ -28,25 +53,173 @@ func (bn *BaseNode) Type() string {
 	return "BaseNode"
 }
 
+// Span returns the source range covered by the node. Nodes built by hand
+// rather than parsed have a zero span.
+func (bn *BaseNode) Span() (start, end Position) {
+	return bn.Pos, bn.End
+}
+
+func (bn *BaseNode) setSpan(start, end Position) {
+	bn.Pos = start
+	bn.End = end
+}
+
+// Program is a sequence of statements, e.g. the contents of a .sql file
+type Program struct {
+	BaseNode
+	Statements []Statement
+}
+
+func (pr *Program) Type() string { return "Program" }
+func (pr *Program) String() string {
+	stmts := make([]string, len(pr.Statements))
+	for i, stmt := range pr.Statements {
+		stmts[i] = stmt.String() + ";"
+	}
+	return strings.Join(stmts, "\n")
+}
+
+// Batch is a group of statements ended by a GO separator in a SQL Server script
+type Batch struct {
+	Statements []Statement
+	Count      int // times the batch is executed, from GO count; 1 by default
+}
+
 // SELECT Statement
 type SelectStatement struct {
 	BaseNode
+	With     *WithClause
 	Distinct bool
 	Top      *TopClause
 	Columns  []Expression
+	Into     *TableReference // SELECT ... INTO new_table
 	From     *FromClause
 	Joins    []*JoinClause
 	Where    Expression
-	GroupBy  []Expression
+	GroupBy  *GroupByClause
 	Having   Expression
 	OrderBy  []*OrderByClause
 	Limit    *LimitClause
+	Offset   *OffsetFetchClause // SQL Server OFFSET ... FETCH
+	For      *ForClause         // SQL Server FOR JSON / FOR XML
+	Option   *OptionClause      // SQL Server OPTION (...) query hints
 }
 
 func (ss *SelectStatement) statementNode() {}
 func (ss *SelectStatement) Type() string   { return "SelectStatement" }
 func (ss *SelectStatement) String() string {
-	return fmt.Sprintf("SELECT Statement with %d columns", len(ss.Columns))
+	var sb strings.Builder
+	if ss.With != nil {
+		sb.WriteString(ss.With.String())
+		sb.WriteString(" ")
+	}
+	sb.WriteString("SELECT ")
+	if ss.Distinct {
+		sb.WriteString("DISTINCT ")
+	}
+	if ss.Top != nil {
+		sb.WriteString(ss.Top.String())
+		sb.WriteString(" ")
+	}
+	sb.WriteString(joinExpressions(ss.Columns))
+	if ss.Into != nil {
+		sb.WriteString(" INTO ")
+		sb.WriteString(ss.Into.String())
+	}
+	if ss.From != nil {
+		sb.WriteString(" ")
+		sb.WriteString(ss.From.String())
+	}
+	for _, join := range ss.Joins {
+		sb.WriteString(" ")
+		sb.WriteString(join.String())
+	}
+	if ss.Where != nil {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(ss.Where.String())
+	}
+	if ss.GroupBy != nil {
+		sb.WriteString(" ")
+		sb.WriteString(ss.GroupBy.String())
+	}
+	if ss.Having != nil {
+		sb.WriteString(" HAVING ")
+		sb.WriteString(ss.Having.String())
+	}
+	if len(ss.OrderBy) > 0 {
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(joinOrderBy(ss.OrderBy))
+	}
+	if ss.Offset != nil {
+		sb.WriteString(" ")
+		sb.WriteString(ss.Offset.String())
+	}
+	if ss.Limit != nil {
+		sb.WriteString(" ")
+		sb.WriteString(ss.Limit.String())
+	}
+	if ss.For != nil {
+		sb.WriteString(" ")
+		sb.WriteString(ss.For.String())
+	}
+	if ss.Option != nil {
+		sb.WriteString(" ")
+		sb.WriteString(ss.Option.String())
+	}
+	return sb.String()
+}
+
+// Set Operation (UNION, UNION ALL, EXCEPT, INTERSECT)
+type SetOperation struct {
+	BaseNode
+	With     *WithClause
+	Left     Statement
+	Operator string
+	Right    Statement
+}
+
+func (so *SetOperation) statementNode() {}
+func (so *SetOperation) Type() string   { return "SetOperation" }
+func (so *SetOperation) String() string {
+	if so.With != nil {
+		return fmt.Sprintf("%s %s %s %s", so.With.String(), so.Left.String(), so.Operator, so.Right.String())
+	}
+	return fmt.Sprintf("%s %s %s", so.Left.String(), so.Operator, so.Right.String())
+}
+
+// WITH Clause (Common Table Expressions)
+type WithClause struct {
+	BaseNode
+	Recursive bool
+	CTEs      []*CommonTableExpression
+}
+
+func (wc *WithClause) Type() string { return "WithClause" }
+func (wc *WithClause) String() string {
+	ctes := make([]string, len(wc.CTEs))
+	for i, cte := range wc.CTEs {
+		ctes[i] = cte.String()
+	}
+	if wc.Recursive {
+		return "WITH RECURSIVE " + strings.Join(ctes, ", ")
+	}
+	return "WITH " + strings.Join(ctes, ", ")
+}
+
+// Common Table Expression: name [(columns)] AS (query)
+type CommonTableExpression struct {
+	BaseNode
+	Name    string
+	Columns []string
+	Query   Statement
+}
+
+func (cte *CommonTableExpression) Type() string { return "CommonTableExpression" }
+func (cte *CommonTableExpression) String() string {
+	if len(cte.Columns) > 0 {
+		return fmt.Sprintf("%s (%s) AS (%s)", QuoteIdent(cte.Name), joinIdents(cte.Columns), cte.Query.String())
+	}
+	return fmt.Sprintf("%s AS (%s)", QuoteIdent(cte.Name), cte.Query.String())
 }
 
 // FROM Clause
@@ -55,24 +228,167 @@ type FromClause struct {
 	Tables []TableReference
 }
 
-func (fc *FromClause) Type() string   { return "FromClause" }
-func (fc *FromClause) String() string { return "FROM Clause" }
+func (fc *FromClause) Type() string { return "FromClause" }
+func (fc *FromClause) String() string {
+	tables := make([]string, len(fc.Tables))
+	for i := range fc.Tables {
+		tables[i] = fc.Tables[i].String()
+	}
+	return "FROM " + strings.Join(tables, ", ")
+}
 
 // Table Reference
 type TableReference struct {
 	BaseNode
-	Schema string
-	Name   string
-	Alias  string
+	Server      string // linked server of a four-part name
+	Database    string
+	Schema      string
+	Name        string
+	Alias       string
+	Columns     []string         // column aliases of a derived or VALUES table, as in AS t(id, name)
+	Kind        TableKind        // temp table or table variable; empty for other tables
+	Function    *FunctionCall    // table-valued function, e.g. dbo.SplitString(x)
+	Subquery    *SelectStatement // derived table, e.g. (SELECT ...) AS t
+	Values      *ValuesClause    // table value constructor, e.g. (VALUES (1, 'a')) AS t(id, name)
+	TableSample *TableSample
+	Hints       []string // SQL Server table hints, e.g. NOLOCK, INDEX(ix_name)
+	Pivot       *PivotClause
+	Unpivot     *UnpivotClause
+}
+
+// ValuesClause is a table value constructor used as a row source, e.g. the
+// VALUES (1, 'a'), (2, 'b') of FROM (VALUES (1, 'a'), (2, 'b')) AS t(id, name)
+type ValuesClause struct {
+	BaseNode
+	Rows [][]Expression
+}
+
+func (vc *ValuesClause) Type() string { return "ValuesClause" }
+func (vc *ValuesClause) String() string {
+	rows := make([]string, len(vc.Rows))
+	for i, row := range vc.Rows {
+		values := make([]string, len(row))
+		for j, value := range row {
+			values[j] = value.String()
+		}
+		rows[i] = "(" + strings.Join(values, ", ") + ")"
+	}
+	return "VALUES " + strings.Join(rows, ", ")
 }
 
+// TableKind marks SQL Server temporary tables and table variables
+type TableKind string
+
+const (
+	TableLocalTemp  TableKind = "LOCAL_TEMP"     // #name
+	TableGlobalTemp TableKind = "GLOBAL_TEMP"    // ##name
+	TableVariable   TableKind = "TABLE_VARIABLE" // @name
+)
+
 func (tr *TableReference) expressionNode() {}
 func (tr *TableReference) Type() string    { return "TableReference" }
 func (tr *TableReference) String() string {
-	if tr.Schema != "" {
-		return fmt.Sprintf("%s.%s", tr.Schema, tr.Name)
+	var name string
+	switch {
+	case tr.Subquery != nil:
+		name = fmt.Sprintf("(%s)", tr.Subquery.String())
+	case tr.Values != nil:
+		name = fmt.Sprintf("(%s)", tr.Values.String())
+	case tr.Function != nil:
+		name = tr.Function.String()
+	default:
+		name = tr.QualifiedName()
+	}
+	if tr.Alias != "" {
+		name = fmt.Sprintf("%s AS %s", name, QuoteIdent(tr.Alias))
+	}
+	if len(tr.Columns) > 0 {
+		name = fmt.Sprintf("%s(%s)", name, joinIdents(tr.Columns))
+	}
+	if tr.TableSample != nil {
+		name = fmt.Sprintf("%s %s", name, tr.TableSample.String())
+	}
+	if len(tr.Hints) > 0 {
+		name = fmt.Sprintf("%s WITH (%s)", name, strings.Join(tr.Hints, ", "))
+	}
+	if tr.Pivot != nil {
+		name = fmt.Sprintf("%s %s", name, tr.Pivot.String())
+	}
+	if tr.Unpivot != nil {
+		name = fmt.Sprintf("%s %s", name, tr.Unpivot.String())
+	}
+	return name
+}
+
+// QualifiedName returns the dotted server.database.schema.name, starting at
+// the first part that is set. An omitted inner part is kept empty, as in db..t
+func (tr *TableReference) QualifiedName() string {
+	parts := []string{tr.Server, tr.Database, tr.Schema, tr.Name}
+	for len(parts) > 1 && parts[0] == "" {
+		parts = parts[1:]
+	}
+	for i, part := range parts {
+		parts[i] = QuoteIdent(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// TABLESAMPLE clause (SQL Server), e.g. TABLESAMPLE SYSTEM (10 PERCENT) REPEATABLE (42)
+type TableSample struct {
+	BaseNode
+	System     bool       // SYSTEM sampling method given explicitly
+	Size       Expression // sample size
+	Unit       string     // PERCENT, ROWS, or empty when omitted
+	Repeatable Expression // REPEATABLE seed, nil when absent
+}
+
+func (ts *TableSample) Type() string { return "TableSample" }
+func (ts *TableSample) String() string {
+	result := "TABLESAMPLE "
+	if ts.System {
+		result += "SYSTEM "
+	}
+	if ts.Unit != "" {
+		result += fmt.Sprintf("(%s %s)", ts.Size.String(), ts.Unit)
+	} else {
+		result += fmt.Sprintf("(%s)", ts.Size.String())
+	}
+	if ts.Repeatable != nil {
+		result += fmt.Sprintf(" REPEATABLE (%s)", ts.Repeatable.String())
 	}
-	return tr.Name
+	return result
+}
+
+// PIVOT table operator (SQL Server), turning the values of a column into
+// columns, e.g. PIVOT (SUM(amount) FOR month IN ([Jan], [Feb])) AS p
+type PivotClause struct {
+	BaseNode
+	Aggregate *FunctionCall
+	Column    *ColumnReference // FOR column whose values become columns
+	Values    []string         // IN list, the names of the new columns
+	Alias     string
+}
+
+func (pc *PivotClause) Type() string { return "PivotClause" }
+func (pc *PivotClause) String() string {
+	return fmt.Sprintf("PIVOT (%s FOR %s IN (%s)) AS %s",
+		pc.Aggregate.String(), pc.Column.String(), joinIdents(pc.Values), QuoteIdent(pc.Alias))
+}
+
+// UNPIVOT table operator (SQL Server), turning columns into rows, e.g.
+// UNPIVOT (amount FOR month IN (Jan, Feb)) AS u
+type UnpivotClause struct {
+	BaseNode
+	ValueColumn string   // new column holding the values
+	NameColumn  string   // new FOR column holding the source column names
+	Columns     []string // IN list of source columns
+	Alias       string
+}
+
+func (uc *UnpivotClause) Type() string { return "UnpivotClause" }
+func (uc *UnpivotClause) String() string {
+	return fmt.Sprintf("UNPIVOT (%s FOR %s IN (%s)) AS %s",
+		QuoteIdent(uc.ValueColumn), QuoteIdent(uc.NameColumn), joinIdents(uc.Columns), QuoteIdent(uc.Alias))
 }
 
 // JOIN Clause
@@ -83,8 +399,17 @@ type JoinClause struct {
 	Condition Expression
 }
 
-func (jc *JoinClause) Type() string   { return "JoinClause" }
-func (jc *JoinClause) String() string { return fmt.Sprintf("%s JOIN", jc.JoinType) }
+func (jc *JoinClause) Type() string { return "JoinClause" }
+func (jc *JoinClause) String() string {
+	switch jc.JoinType {
+	case "CROSS APPLY", "OUTER APPLY":
+		return fmt.Sprintf("%s %s", jc.JoinType, jc.Table.String())
+	}
+	if jc.Condition == nil {
+		return fmt.Sprintf("%s JOIN %s", jc.JoinType, jc.Table.String())
+	}
+	return fmt.Sprintf("%s JOIN %s ON %s", jc.JoinType, jc.Table.String(), jc.Condition.String())
+}
 
 // Column Reference
 type ColumnReference struct {
@@ -97,20 +422,126 @@ func (cr *ColumnReference) expressionNode() {}
 func (cr *ColumnReference) Type() string    { return "ColumnReference" }
 func (cr *ColumnReference) String() string {
 	if cr.Table != "" {
-		return fmt.Sprintf("%s.%s", cr.Table, cr.Column)
+		return fmt.Sprintf("%s.%s", QuoteIdent(cr.Table), QuoteIdent(cr.Column))
 	}
-	return cr.Column
+	return QuoteIdent(cr.Column)
 }
 
+// NullValue is the Value of a Literal representing SQL NULL
+type NullValue struct{}
+
+func (NullValue) String() string { return "NULL" }
+
+// Null is the shared NULL literal value
+var Null = NullValue{}
+
 // Literal Expression
 type Literal struct {
 	BaseNode
-	Value interface{}
+	Value   interface{}
+	Unicode bool // N'...' string
 }
 
 func (l *Literal) expressionNode() {}
 func (l *Literal) Type() string    { return "Literal" }
-func (l *Literal) String() string  { return fmt.Sprintf("%v", l.Value) }
+
+// IsNull reports whether the literal is SQL NULL
+func (l *Literal) IsNull() bool {
+	switch l.Value.(type) {
+	case nil, NullValue:
+		return true
+	}
+	return false
+}
+func (l *Literal) String() string {
+	switch v := l.Value.(type) {
+	case nil, NullValue:
+		return "NULL"
+	case string:
+		quoted := "'" + strings.ReplaceAll(v, "'", "''") + "'"
+		if l.Unicode {
+			return "N" + quoted
+		}
+		return quoted
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case []byte:
+		return fmt.Sprintf("0x%X", v)
+	case float64:
+		// Keep a decimal point or exponent so the value parses back as a float
+		text := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(text, ".e") {
+			text += ".0"
+		}
+		return text
+	}
+	return fmt.Sprintf("%v", l.Value)
+}
+
+// Parameter Expression: a named @param or a positional ? placeholder
+type Parameter struct {
+	BaseNode
+	Name     string // @name, empty for positional placeholders
+	Position int    // 1-based ordinal of a ? placeholder, 0 for named parameters
+}
+
+func (pm *Parameter) expressionNode() {}
+func (pm *Parameter) Type() string    { return "Parameter" }
+func (pm *Parameter) String() string {
+	if pm.Name != "" {
+		return pm.Name
+	}
+	return "?"
+}
+
+// SystemVariable is a SQL Server global variable such as @@ROWCOUNT
+type SystemVariable struct {
+	BaseNode
+	Name string // including the @@ prefix
+}
+
+func (sv *SystemVariable) expressionNode() {}
+func (sv *SystemVariable) Type() string    { return "SystemVariable" }
+func (sv *SystemVariable) String() string  { return sv.Name }
+
+// DatePart is the date part argument of DATEADD, DATEDIFF and similar SQL
+// Server functions, e.g. day or its abbreviation dd in DATEADD(dd, 1, d)
+type DatePart struct {
+	BaseNode
+	Name string // as written
+}
+
+func (dp *DatePart) expressionNode() {}
+func (dp *DatePart) Type() string    { return "DatePart" }
+func (dp *DatePart) String() string  { return dp.Name }
+
+// Part returns the full upper-case name of the date part, e.g. DAY for dd
+func (dp *DatePart) Part() string {
+	return dateParts[strings.ToUpper(dp.Name)]
+}
+
+// OrdinalReference is a bare integer in ORDER BY or GROUP BY naming a
+// select-list column by position, as in ORDER BY 2 DESC
+type OrdinalReference struct {
+	BaseNode
+	Index int // 1-based position in the select list
+}
+
+func (ord *OrdinalReference) expressionNode() {}
+func (ord *OrdinalReference) Type() string    { return "OrdinalReference" }
+func (ord *OrdinalReference) String() string  { return strconv.Itoa(ord.Index) }
+
+// Resolve returns the select-list expression the ordinal refers to, or nil
+// if it is out of range
+func (ord *OrdinalReference) Resolve(stmt *SelectStatement) Expression {
+	if ord.Index < 1 || ord.Index > len(stmt.Columns) {
+		return nil
+	}
+	return stmt.Columns[ord.Index-1]
+}
 
 // Binary Expression (for WHERE conditions, etc.)
 type BinaryExpression struct {
@@ -130,12 +561,102 @@ func (be *BinaryExpression) String() string {
 type FunctionCall struct {
 	BaseNode
 	Name      string
+	Distinct  bool // COUNT(DISTINCT col)
 	Arguments []Expression
+	Over      *WindowSpec // window function OVER (...)
 }
 
 func (fc *FunctionCall) expressionNode() {}
 func (fc *FunctionCall) Type() string    { return "FunctionCall" }
-func (fc *FunctionCall) String() string  { return fmt.Sprintf("%s(...)", fc.Name) }
+func (fc *FunctionCall) String() string {
+	args := joinExpressions(fc.Arguments)
+	if fc.Distinct {
+		args = "DISTINCT " + args
+	}
+	if fc.Over != nil {
+		return fmt.Sprintf("%s(%s) %s", fc.Name, args, fc.Over.String())
+	}
+	return fmt.Sprintf("%s(%s)", fc.Name, args)
+}
+
+// Window specification for OVER (PARTITION BY ... ORDER BY ...)
+type WindowSpec struct {
+	BaseNode
+	PartitionBy []Expression
+	OrderBy     []*OrderByClause
+}
+
+func (ws *WindowSpec) Type() string { return "WindowSpec" }
+func (ws *WindowSpec) String() string {
+	var parts []string
+	if len(ws.PartitionBy) > 0 {
+		parts = append(parts, "PARTITION BY "+joinExpressions(ws.PartitionBy))
+	}
+	if len(ws.OrderBy) > 0 {
+		parts = append(parts, "ORDER BY "+joinOrderBy(ws.OrderBy))
+	}
+	return fmt.Sprintf("OVER (%s)", strings.Join(parts, " "))
+}
+
+// CAST / CONVERT Expression
+type CastExpression struct {
+	BaseNode
+	Function   string // CAST, TRY_CAST, CONVERT or TRY_CONVERT
+	Expression Expression
+	DataType   *DataType
+	Style      Expression // CONVERT style code, e.g. 120
+	Try        bool       // TRY_CAST or TRY_CONVERT, which yield NULL on failure
+}
+
+func (ce *CastExpression) expressionNode() {}
+func (ce *CastExpression) Type() string    { return "CastExpression" }
+func (ce *CastExpression) String() string {
+	if ce.Function == "CONVERT" || ce.Function == "TRY_CONVERT" {
+		if ce.Style != nil {
+			return fmt.Sprintf("%s(%s, %s, %s)", ce.Function, ce.DataType.String(), ce.Expression.String(), ce.Style.String())
+		}
+		return fmt.Sprintf("%s(%s, %s)", ce.Function, ce.DataType.String(), ce.Expression.String())
+	}
+	return fmt.Sprintf("%s(%s AS %s)", ce.Function, ce.Expression.String(), ce.DataType.String())
+}
+
+// Data type with optional size, e.g. INT, VARCHAR(50), NVARCHAR(MAX), DECIMAL(10,2)
+type DataType struct {
+	BaseNode
+	Name      string
+	Length    int  // VARCHAR(50), 0 if not given
+	Max       bool // VARCHAR(MAX)
+	Precision int  // DECIMAL(10,2), 0 if not given
+	Scale     int
+}
+
+func (dt *DataType) Type() string { return "DataType" }
+func (dt *DataType) String() string {
+	switch {
+	case dt.Max:
+		return fmt.Sprintf("%s(MAX)", dt.Name)
+	case dt.Precision > 0 && dt.Scale > 0:
+		return fmt.Sprintf("%s(%d,%d)", dt.Name, dt.Precision, dt.Scale)
+	case dt.Precision > 0:
+		return fmt.Sprintf("%s(%d)", dt.Name, dt.Precision)
+	case dt.Length > 0:
+		return fmt.Sprintf("%s(%d)", dt.Name, dt.Length)
+	}
+	return dt.Name
+}
+
+// Aliased Expression (select list item with AS alias)
+type AliasedExpression struct {
+	BaseNode
+	Expression Expression
+	Alias      string
+}
+
+func (ae *AliasedExpression) expressionNode() {}
+func (ae *AliasedExpression) Type() string    { return "AliasedExpression" }
+func (ae *AliasedExpression) String() string {
+	return fmt.Sprintf("%s AS %s", ae.Expression.String(), QuoteIdent(ae.Alias))
+}
 
 // SELECT * Expression
 type StarExpression struct {
@@ -147,21 +668,94 @@ func (se *StarExpression) expressionNode() {}
 func (se *StarExpression) Type() string    { return "StarExpression" }
 func (se *StarExpression) String() string {
 	if se.Table != "" {
-		return fmt.Sprintf("%s.*", se.Table)
+		return fmt.Sprintf("%s.*", QuoteIdent(se.Table))
 	}
 	return "*"
 }
 
+// GROUP BY Clause
+type GroupByClause struct {
+	BaseNode
+	Items      []Expression // expressions and ROLLUP/CUBE/GROUPING SETS constructs
+	All        bool         // legacy SQL Server GROUP BY ALL
+	WithRollup bool         // legacy GROUP BY ... WITH ROLLUP
+	WithCube   bool         // legacy SQL Server GROUP BY ... WITH CUBE
+}
+
+func (gbc *GroupByClause) Type() string { return "GroupByClause" }
+func (gbc *GroupByClause) String() string {
+	result := "GROUP BY "
+	if gbc.All {
+		result += "ALL "
+	}
+	result += joinExpressions(gbc.Items)
+	if gbc.WithRollup {
+		result += " WITH ROLLUP"
+	}
+	if gbc.WithCube {
+		result += " WITH CUBE"
+	}
+	return result
+}
+
+// ROLLUP(...) grouping construct
+type RollupExpression struct {
+	BaseNode
+	Groups [][]Expression // each group is a column or a parenthesized column list
+}
+
+func (re *RollupExpression) expressionNode() {}
+func (re *RollupExpression) Type() string    { return "RollupExpression" }
+func (re *RollupExpression) String() string {
+	return fmt.Sprintf("ROLLUP(%s)", joinGroupings(re.Groups))
+}
+
+// CUBE(...) grouping construct
+type CubeExpression struct {
+	BaseNode
+	Groups [][]Expression
+}
+
+func (ce *CubeExpression) expressionNode() {}
+func (ce *CubeExpression) Type() string    { return "CubeExpression" }
+func (ce *CubeExpression) String() string {
+	return fmt.Sprintf("CUBE(%s)", joinGroupings(ce.Groups))
+}
+
+// GROUPING SETS(...) grouping construct; an empty set () is the grand total
+type GroupingSetsExpression struct {
+	BaseNode
+	Sets [][]Expression
+}
+
+func (gse *GroupingSetsExpression) expressionNode() {}
+func (gse *GroupingSetsExpression) Type() string    { return "GroupingSetsExpression" }
+func (gse *GroupingSetsExpression) String() string {
+	return fmt.Sprintf("GROUPING SETS(%s)", joinGroupings(gse.Sets))
+}
+
 // ORDER BY Clause
 type OrderByClause struct {
 	BaseNode
 	Expression Expression
+	Collation  string // COLLATE collation_name, e.g. Latin1_General_CI_AS
 	Direction  string // ASC, DESC
+	Nulls      string // FIRST or LAST for NULLS FIRST / NULLS LAST
 }
 
 func (obc *OrderByClause) Type() string { return "OrderByClause" }
 func (obc *OrderByClause) String() string {
-	return fmt.Sprintf("ORDER BY %s %s", obc.Expression.String(), obc.Direction)
+	result := obc.Expression.String()
+	if obc.Collation != "" {
+		result += " COLLATE " + obc.Collation
+	}
+	if obc.Direction != "" {
+		result += " " + obc.Direction
+	}
+	if obc.Nulls != "" {
+		result += " NULLS " + obc.Nulls
+	}
+	return result
 }
 
 // TOP Clause (SQL Server specific)
@@ -171,8 +765,13 @@ type TopClause struct {
 	Percent bool
 }
 
-func (tc *TopClause) Type() string   { return "TopClause" }
-func (tc *TopClause) String() string { return fmt.Sprintf("TOP %d", tc.Count) }
+func (tc *TopClause) Type() string { return "TopClause" }
+func (tc *TopClause) String() string {
+	if tc.Percent {
+		return fmt.Sprintf("TOP %d PERCENT", tc.Count)
+	}
+	return fmt.Sprintf("TOP %d", tc.Count)
+}
 
 // LIMIT Clause
 type LimitClause struct {
@@ -181,53 +780,747 @@ type LimitClause struct {
 	Offset int
 }
 
-func (lc *LimitClause) Type() string   { return "LimitClause" }
-func (lc *LimitClause) String() string { return fmt.Sprintf("LIMIT %d", lc.Count) }
+func (lc *LimitClause) Type() string { return "LimitClause" }
+func (lc *LimitClause) String() string {
+	if lc.Offset > 0 {
+		return fmt.Sprintf("LIMIT %d OFFSET %d", lc.Count, lc.Offset)
+	}
+	return fmt.Sprintf("LIMIT %d", lc.Count)
+}
+
+// OFFSET ... FETCH Clause (SQL Server / ANSI pagination)
+type OffsetFetchClause struct {
+	BaseNode
+	Offset   int
+	Fetch    int
+	HasFetch bool
+}
+
+func (ofc *OffsetFetchClause) Type() string { return "OffsetFetchClause" }
+func (ofc *OffsetFetchClause) String() string {
+	if ofc.HasFetch {
+		return fmt.Sprintf("OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", ofc.Offset, ofc.Fetch)
+	}
+	return fmt.Sprintf("OFFSET %d ROWS", ofc.Offset)
+}
 
 // INSERT Statement
 type InsertStatement struct {
 	BaseNode
+	With    *WithClause
 	Table   TableReference
 	Columns []string
+	Output  *OutputClause
 	Values  [][]Expression
+	Source  *SelectStatement // INSERT ... SELECT
+	Option  *OptionClause    // after VALUES; a SELECT source holds its own
 }
 
 func (is *InsertStatement) statementNode() {}
 func (is *InsertStatement) Type() string   { return "InsertStatement" }
-func (is *InsertStatement) String() string { return "INSERT Statement" }
+func (is *InsertStatement) String() string {
+	var sb strings.Builder
+	if is.With != nil {
+		sb.WriteString(is.With.String())
+		sb.WriteString(" ")
+	}
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(is.Table.String())
+	if len(is.Columns) > 0 {
+		sb.WriteString(" (")
+		sb.WriteString(joinIdents(is.Columns))
+		sb.WriteString(")")
+	}
+	if is.Output != nil {
+		sb.WriteString(" ")
+		sb.WriteString(is.Output.String())
+	}
+	if is.Source != nil {
+		sb.WriteString(" ")
+		sb.WriteString(is.Source.String())
+		return sb.String()
+	}
+	rows := make([]string, len(is.Values))
+	for i, row := range is.Values {
+		rows[i] = "(" + joinExpressions(row) + ")"
+	}
+	sb.WriteString(" VALUES ")
+	sb.WriteString(strings.Join(rows, ", "))
+	if is.Option != nil {
+		sb.WriteString(" ")
+		sb.WriteString(is.Option.String())
+	}
+	return sb.String()
+}
 
 // UPDATE Statement
 type UpdateStatement struct {
 	BaseNode
-	Table TableReference
-	Set   []*Assignment
-	Where Expression
+	With   *WithClause
+	Table  TableReference
+	Set    []*Assignment
+	Output *OutputClause
+	From   *FromClause   // SQL Server UPDATE ... FROM
+	Joins  []*JoinClause // Joins following the UPDATE ... FROM clause
+	Where  Expression
+	Option *OptionClause
 }
 
 func (us *UpdateStatement) statementNode() {}
 func (us *UpdateStatement) Type() string   { return "UpdateStatement" }
-func (us *UpdateStatement) String() string { return "UPDATE Statement" }
+func (us *UpdateStatement) String() string {
+	var sb strings.Builder
+	if us.With != nil {
+		sb.WriteString(us.With.String())
+		sb.WriteString(" ")
+	}
+	sb.WriteString("UPDATE ")
+	sb.WriteString(us.Table.String())
+	assignments := make([]string, len(us.Set))
+	for i, assignment := range us.Set {
+		assignments[i] = assignment.String()
+	}
+	sb.WriteString(" SET ")
+	sb.WriteString(strings.Join(assignments, ", "))
+	if us.Output != nil {
+		sb.WriteString(" ")
+		sb.WriteString(us.Output.String())
+	}
+	if us.From != nil {
+		sb.WriteString(" ")
+		sb.WriteString(us.From.String())
+	}
+	for _, join := range us.Joins {
+		sb.WriteString(" ")
+		sb.WriteString(join.String())
+	}
+	if us.Where != nil {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(us.Where.String())
+	}
+	if us.Option != nil {
+		sb.WriteString(" ")
+		sb.WriteString(us.Option.String())
+	}
+	return sb.String()
+}
 
 // Assignment for UPDATE SET clause
 type Assignment struct {
 	BaseNode
-	Column string
+	Column *ColumnReference
 	Value  Expression
 }
 
-func (a *Assignment) Type() string   { return "Assignment" }
-func (a *Assignment) String() string { return fmt.Sprintf("%s = %s", a.Column, a.Value.String()) }
+func (a *Assignment) Type() string { return "Assignment" }
+func (a *Assignment) String() string {
+	return fmt.Sprintf("%s = %s", a.Column.String(), a.Value.String())
+}
 
 // DELETE Statement
 type DeleteStatement struct {
 	BaseNode
-	From  TableReference
-	Where Expression
+	With   *WithClause
+	Top    *TopClause
+	From   TableReference
+	Output *OutputClause
+	Where  Expression
+	Option *OptionClause
 }
 
 func (ds *DeleteStatement) statementNode() {}
 func (ds *DeleteStatement) Type() string   { return "DeleteStatement" }
-func (ds *DeleteStatement) String() string { return "DELETE Statement" }
+func (ds *DeleteStatement) String() string {
+	var sb strings.Builder
+	if ds.With != nil {
+		sb.WriteString(ds.With.String())
+		sb.WriteString(" ")
+	}
+	sb.WriteString("DELETE ")
+	if ds.Top != nil {
+		sb.WriteString(fmt.Sprintf("TOP (%d) ", ds.Top.Count))
+		if ds.Top.Percent {
+			sb.WriteString("PERCENT ")
+		}
+	}
+	sb.WriteString("FROM ")
+	sb.WriteString(ds.From.String())
+	if ds.Output != nil {
+		sb.WriteString(" ")
+		sb.WriteString(ds.Output.String())
+	}
+	if ds.Where != nil {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(ds.Where.String())
+	}
+	if ds.Option != nil {
+		sb.WriteString(" ")
+		sb.WriteString(ds.Option.String())
+	}
+	return sb.String()
+}
+
+// OPTION clause (SQL Server) with the query hints ending a statement, e.g.
+// OPTION (MAXDOP 4, RECOMPILE)
+type OptionClause struct {
+	BaseNode
+	Hints []*QueryHint
+}
+
+func (oc *OptionClause) Type() string { return "OptionClause" }
+func (oc *OptionClause) String() string {
+	hints := make([]string, len(oc.Hints))
+	for i, hint := range oc.Hints {
+		hints[i] = hint.String()
+	}
+	return "OPTION (" + strings.Join(hints, ", ") + ")"
+}
+
+// QueryHint is one hint of an OPTION clause: a flag such as RECOMPILE or
+// HASH JOIN, a valued hint such as MAXDOP 4 or LABEL = 'x', or a hint taking a
+// list such as USE HINT ('...') or OPTIMIZE FOR (@p = 1, @q UNKNOWN)
+type QueryHint struct {
+	BaseNode
+	Name      string       // upper-cased words, e.g. MAXDOP or OPTIMIZE FOR UNKNOWN
+	Value     Expression   // nil for flags and list hints
+	Equals    bool         // value written as name = value
+	Arguments []Expression // parenthesized list; OPTIMIZE FOR uses Variables
+	Variables []*OptimizeForVariable
+}
+
+func (qh *QueryHint) Type() string { return "QueryHint" }
+func (qh *QueryHint) String() string {
+	switch {
+	case qh.Value != nil && qh.Equals:
+		return qh.Name + " = " + qh.Value.String()
+	case qh.Value != nil:
+		return qh.Name + " " + qh.Value.String()
+	case len(qh.Variables) > 0:
+		variables := make([]string, len(qh.Variables))
+		for i, variable := range qh.Variables {
+			variables[i] = variable.String()
+		}
+		return qh.Name + " (" + strings.Join(variables, ", ") + ")"
+	case len(qh.Arguments) > 0:
+		return qh.Name + " (" + joinExpressions(qh.Arguments) + ")"
+	}
+	return qh.Name
+}
+
+// OptimizeForVariable is one variable of OPTIMIZE FOR, with the value the
+// plan is optimized for or UNKNOWN when Value is nil
+type OptimizeForVariable struct {
+	BaseNode
+	Name  string
+	Value Expression
+}
+
+func (ofv *OptimizeForVariable) Type() string { return "OptimizeForVariable" }
+func (ofv *OptimizeForVariable) String() string {
+	if ofv.Value == nil {
+		return ofv.Name + " UNKNOWN"
+	}
+	return ofv.Name + " = " + ofv.Value.String()
+}
+
+// FOR clause (SQL Server) returning the result of a SELECT as JSON or XML,
+// e.g. FOR JSON PATH, ROOT('orders') or FOR XML RAW('row'), ELEMENTS
+type ForClause struct {
+	BaseNode
+	Format      string     // JSON or XML
+	Mode        string     // AUTO or PATH, and for XML also RAW or EXPLICIT
+	ElementName Expression // XML RAW ('name') or PATH ('name'), nil if omitted
+	Options     []*ForOption
+}
+
+func (fc *ForClause) Type() string { return "ForClause" }
+func (fc *ForClause) String() string {
+	result := "FOR " + fc.Format + " " + fc.Mode
+	if fc.ElementName != nil {
+		result += "(" + fc.ElementName.String() + ")"
+	}
+	for _, option := range fc.Options {
+		result += ", " + option.String()
+	}
+	return result
+}
+
+// ForOption is one option of a FOR clause, such as INCLUDE_NULL_VALUES,
+// ELEMENTS XSINIL or ROOT('name')
+type ForOption struct {
+	BaseNode
+	Name     string     // upper-cased words
+	Argument Expression // ROOT or XMLSCHEMA name, nil if omitted
+}
+
+func (fo *ForOption) Type() string { return "ForOption" }
+func (fo *ForOption) String() string {
+	if fo.Argument == nil {
+		return fo.Name
+	}
+	return fo.Name + "(" + fo.Argument.String() + ")"
+}
+
+// OUTPUT clause (SQL Server), returning the rows changed by INSERT, UPDATE or
+// DELETE through the inserted and deleted pseudo-tables
+type OutputClause struct {
+	BaseNode
+	Columns     []Expression
+	Into        *TableReference // OUTPUT ... INTO target, often a table variable
+	IntoColumns []string
+}
+
+func (oc *OutputClause) Type() string { return "OutputClause" }
+func (oc *OutputClause) String() string {
+	result := "OUTPUT " + joinExpressions(oc.Columns)
+	if oc.Into != nil {
+		result += " INTO " + oc.Into.String()
+		if len(oc.IntoColumns) > 0 {
+			result += " (" + joinIdents(oc.IntoColumns) + ")"
+		}
+	}
+	return result
+}
+
+// MERGE Statement
+type MergeStatement struct {
+	BaseNode
+	With      *WithClause
+	Target    TableReference
+	Source    TableReference // table or derived table
+	Condition Expression
+	Clauses   []*MergeWhenClause
+}
+
+func (ms *MergeStatement) statementNode() {}
+func (ms *MergeStatement) Type() string   { return "MergeStatement" }
+func (ms *MergeStatement) String() string {
+	var sb strings.Builder
+	if ms.With != nil {
+		sb.WriteString(ms.With.String())
+		sb.WriteString(" ")
+	}
+	sb.WriteString("MERGE INTO ")
+	sb.WriteString(ms.Target.String())
+	sb.WriteString(" USING ")
+	sb.WriteString(ms.Source.String())
+	sb.WriteString(" ON ")
+	sb.WriteString(ms.Condition.String())
+	for _, clause := range ms.Clauses {
+		sb.WriteString(" ")
+		sb.WriteString(clause.String())
+	}
+	return sb.String()
+}
+
+// WHEN [NOT] MATCHED [BY SOURCE] [AND condition] THEN action, where action is
+// UPDATE SET ..., DELETE or INSERT [(columns)] VALUES (...)
+type MergeWhenClause struct {
+	BaseNode
+	Matched   bool
+	BySource  bool       // WHEN NOT MATCHED BY SOURCE
+	Condition Expression // optional AND condition
+	Action    string     // UPDATE, DELETE or INSERT
+	Set       []*Assignment
+	Columns   []string
+	Values    []Expression
+}
+
+func (mc *MergeWhenClause) Type() string { return "MergeWhenClause" }
+func (mc *MergeWhenClause) String() string {
+	var sb strings.Builder
+	switch {
+	case mc.Matched:
+		sb.WriteString("WHEN MATCHED")
+	case mc.BySource:
+		sb.WriteString("WHEN NOT MATCHED BY SOURCE")
+	default:
+		sb.WriteString("WHEN NOT MATCHED")
+	}
+	if mc.Condition != nil {
+		sb.WriteString(" AND ")
+		sb.WriteString(mc.Condition.String())
+	}
+	sb.WriteString(" THEN ")
+	switch mc.Action {
+	case "UPDATE":
+		assignments := make([]string, len(mc.Set))
+		for i, assignment := range mc.Set {
+			assignments[i] = assignment.String()
+		}
+		sb.WriteString("UPDATE SET ")
+		sb.WriteString(strings.Join(assignments, ", "))
+	case "INSERT":
+		sb.WriteString("INSERT")
+		if len(mc.Columns) > 0 {
+			sb.WriteString(" (")
+			sb.WriteString(joinIdents(mc.Columns))
+			sb.WriteString(")")
+		}
+		sb.WriteString(" VALUES (")
+		sb.WriteString(joinExpressions(mc.Values))
+		sb.WriteString(")")
+	default:
+		sb.WriteString(mc.Action)
+	}
+	return sb.String()
+}
+
+// CREATE TABLE Statement
+type CreateTableStatement struct {
+	BaseNode
+	Table       TableReference
+	Columns     []*ColumnDefinition
+	Constraints []*TableConstraint
+}
+
+func (cts *CreateTableStatement) statementNode() {}
+func (cts *CreateTableStatement) Type() string   { return "CreateTableStatement" }
+func (cts *CreateTableStatement) String() string {
+	items := make([]string, 0, len(cts.Columns)+len(cts.Constraints))
+	for _, column := range cts.Columns {
+		items = append(items, column.String())
+	}
+	for _, constraint := range cts.Constraints {
+		items = append(items, constraint.String())
+	}
+	return fmt.Sprintf("CREATE TABLE %s (%s)", cts.Table.String(), strings.Join(items, ", "))
+}
+
+// Column definition in CREATE TABLE
+type ColumnDefinition struct {
+	BaseNode
+	Name       string
+	DataType   *DataType
+	NotNull    bool
+	Null       bool // explicit NULL
+	Default    Expression
+	Identity   *IdentitySpec
+	PrimaryKey bool
+	Unique     bool
+	// CHECK and REFERENCES constraints, and constraints given a name
+	Constraints []*TableConstraint
+}
+
+func (cd *ColumnDefinition) Type() string { return "ColumnDefinition" }
+func (cd *ColumnDefinition) String() string {
+	var sb strings.Builder
+	sb.WriteString(QuoteIdent(cd.Name))
+	sb.WriteString(" ")
+	sb.WriteString(cd.DataType.String())
+	if cd.NotNull {
+		sb.WriteString(" NOT NULL")
+	} else if cd.Null {
+		sb.WriteString(" NULL")
+	}
+	if cd.Default != nil {
+		sb.WriteString(" DEFAULT ")
+		sb.WriteString(cd.Default.String())
+	}
+	if cd.Identity != nil {
+		sb.WriteString(" ")
+		sb.WriteString(cd.Identity.String())
+	}
+	if cd.PrimaryKey {
+		sb.WriteString(" PRIMARY KEY")
+	}
+	if cd.Unique {
+		sb.WriteString(" UNIQUE")
+	}
+	for _, constraint := range cd.Constraints {
+		sb.WriteString(" ")
+		sb.WriteString(constraint.String())
+	}
+	return sb.String()
+}
+
+// IDENTITY(seed, increment) column property
+type IdentitySpec struct {
+	BaseNode
+	Seed              int
+	Increment         int
+	NotForReplication bool
+}
+
+func (is *IdentitySpec) Type() string { return "IdentitySpec" }
+func (is *IdentitySpec) String() string {
+	if is.NotForReplication {
+		return fmt.Sprintf("IDENTITY(%d,%d) NOT FOR REPLICATION", is.Seed, is.Increment)
+	}
+	return fmt.Sprintf("IDENTITY(%d,%d)", is.Seed, is.Increment)
+}
+
+// Table-level constraint in CREATE TABLE, or a column constraint when
+// Columns is empty
+type TableConstraint struct {
+	BaseNode
+	Name              string // optional CONSTRAINT name
+	Kind              string // PRIMARY KEY, FOREIGN KEY, UNIQUE, CHECK, DEFAULT
+	Columns           []string
+	Expr              Expression      // CHECK condition or DEFAULT value
+	References        *TableReference // FOREIGN KEY target table
+	ReferencedColumns []string
+	OnDelete          string // CASCADE, SET NULL, SET DEFAULT, NO ACTION
+	OnUpdate          string
+}
+
+func (tc *TableConstraint) Type() string { return "TableConstraint" }
+func (tc *TableConstraint) String() string {
+	var sb strings.Builder
+	if tc.Name != "" {
+		sb.WriteString("CONSTRAINT ")
+		sb.WriteString(QuoteIdent(tc.Name))
+		sb.WriteString(" ")
+	}
+	switch tc.Kind {
+	case "CHECK":
+		// Binary expressions already print their own parentheses
+		if _, ok := tc.Expr.(*BinaryExpression); ok {
+			sb.WriteString("CHECK ")
+			sb.WriteString(tc.Expr.String())
+		} else {
+			sb.WriteString("CHECK (")
+			sb.WriteString(tc.Expr.String())
+			sb.WriteString(")")
+		}
+	case "DEFAULT":
+		sb.WriteString("DEFAULT ")
+		sb.WriteString(tc.Expr.String())
+	default:
+		// A column-level foreign key is written as REFERENCES alone
+		if tc.Kind != "FOREIGN KEY" || len(tc.Columns) > 0 {
+			sb.WriteString(tc.Kind)
+		}
+		if len(tc.Columns) > 0 {
+			sb.WriteString(" (")
+			sb.WriteString(joinIdents(tc.Columns))
+			sb.WriteString(")")
+		}
+	}
+	if tc.References != nil {
+		if len(tc.Columns) > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString("REFERENCES ")
+		sb.WriteString(tc.References.String())
+		if len(tc.ReferencedColumns) > 0 {
+			sb.WriteString(" (")
+			sb.WriteString(joinIdents(tc.ReferencedColumns))
+			sb.WriteString(")")
+		}
+		if tc.OnDelete != "" {
+			sb.WriteString(" ON DELETE ")
+			sb.WriteString(tc.OnDelete)
+		}
+		if tc.OnUpdate != "" {
+			sb.WriteString(" ON UPDATE ")
+			sb.WriteString(tc.OnUpdate)
+		}
+	}
+	return sb.String()
+}
+
+// ALTER TABLE Statement
+type AlterTableStatement struct {
+	BaseNode
+	Table      TableReference
+	Action     string            // ADD COLUMN, DROP COLUMN, ALTER COLUMN, ADD CONSTRAINT, DROP CONSTRAINT
+	Column     *ColumnDefinition // ADD COLUMN, ALTER COLUMN
+	Constraint *TableConstraint  // ADD CONSTRAINT
+	Name       string            // column or constraint dropped by DROP COLUMN / DROP CONSTRAINT
+}
+
+func (ats *AlterTableStatement) statementNode() {}
+func (ats *AlterTableStatement) Type() string   { return "AlterTableStatement" }
+func (ats *AlterTableStatement) String() string {
+	var action string
+	switch ats.Action {
+	case "ADD COLUMN":
+		action = "ADD " + ats.Column.String()
+	case "ALTER COLUMN":
+		action = "ALTER COLUMN " + ats.Column.String()
+	case "ADD CONSTRAINT":
+		action = "ADD " + ats.Constraint.String()
+	default:
+		action = ats.Action + " " + QuoteIdent(ats.Name)
+	}
+	return fmt.Sprintf("ALTER TABLE %s %s", ats.Table.String(), action)
+}
+
+// CREATE VIEW Statement
+type CreateViewStatement struct {
+	BaseNode
+	OrAlter       bool // CREATE OR ALTER VIEW
+	View          TableReference
+	Columns       []string  // optional column names, e.g. CREATE VIEW v (a, b)
+	SchemaBinding bool      // WITH SCHEMABINDING
+	Query         Statement // *SelectStatement or *SetOperation
+}
+
+func (cvs *CreateViewStatement) statementNode() {}
+func (cvs *CreateViewStatement) Type() string   { return "CreateViewStatement" }
+func (cvs *CreateViewStatement) String() string {
+	var sb strings.Builder
+	sb.WriteString("CREATE ")
+	if cvs.OrAlter {
+		sb.WriteString("OR ALTER ")
+	}
+	sb.WriteString("VIEW ")
+	sb.WriteString(cvs.View.String())
+	if len(cvs.Columns) > 0 {
+		sb.WriteString(" (")
+		sb.WriteString(joinIdents(cvs.Columns))
+		sb.WriteString(")")
+	}
+	if cvs.SchemaBinding {
+		sb.WriteString(" WITH SCHEMABINDING")
+	}
+	sb.WriteString(" AS ")
+	sb.WriteString(cvs.Query.String())
+	return sb.String()
+}
+
+// CREATE INDEX Statement
+type CreateIndexStatement struct {
+	BaseNode
+	Unique    bool
+	Clustered string // CLUSTERED, NONCLUSTERED, or empty when unspecified
+	Name      string
+	Table     TableReference
+	Columns   []*IndexColumn
+	Include   []string   // INCLUDE (...) non-key columns
+	Where     Expression // filtered index predicate
+}
+
+func (cis *CreateIndexStatement) statementNode() {}
+func (cis *CreateIndexStatement) Type() string   { return "CreateIndexStatement" }
+func (cis *CreateIndexStatement) String() string {
+	var sb strings.Builder
+	sb.WriteString("CREATE ")
+	if cis.Unique {
+		sb.WriteString("UNIQUE ")
+	}
+	if cis.Clustered != "" {
+		sb.WriteString(cis.Clustered)
+		sb.WriteString(" ")
+	}
+	sb.WriteString("INDEX ")
+	sb.WriteString(QuoteIdent(cis.Name))
+	sb.WriteString(" ON ")
+	sb.WriteString(cis.Table.String())
+
+	columns := make([]string, len(cis.Columns))
+	for i, column := range cis.Columns {
+		columns[i] = column.String()
+	}
+	sb.WriteString(" (")
+	sb.WriteString(strings.Join(columns, ", "))
+	sb.WriteString(")")
+
+	if len(cis.Include) > 0 {
+		sb.WriteString(" INCLUDE (")
+		sb.WriteString(joinIdents(cis.Include))
+		sb.WriteString(")")
+	}
+	if cis.Where != nil {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(cis.Where.String())
+	}
+	return sb.String()
+}
+
+// Key column of an index
+type IndexColumn struct {
+	BaseNode
+	Name      string
+	Direction string // ASC, DESC
+}
+
+func (ic *IndexColumn) Type() string { return "IndexColumn" }
+func (ic *IndexColumn) String() string {
+	if ic.Direction == "" {
+		return QuoteIdent(ic.Name)
+	}
+	return QuoteIdent(ic.Name) + " " + ic.Direction
+}
+
+// DROP Statement
+type DropStatement struct {
+	BaseNode
+	ObjectType string // TABLE, VIEW, INDEX, PROCEDURE
+	IfExists   bool
+	Objects    []TableReference
+	Table      *TableReference // DROP INDEX ... ON table
+}
+
+func (ds *DropStatement) statementNode() {}
+func (ds *DropStatement) Type() string   { return "DropStatement" }
+func (ds *DropStatement) String() string {
+	names := make([]string, len(ds.Objects))
+	for i := range ds.Objects {
+		names[i] = ds.Objects[i].String()
+	}
+
+	var sb strings.Builder
+	sb.WriteString("DROP ")
+	sb.WriteString(ds.ObjectType)
+	if ds.IfExists {
+		sb.WriteString(" IF EXISTS")
+	}
+	sb.WriteString(" ")
+	sb.WriteString(strings.Join(names, ", "))
+	if ds.Table != nil {
+		sb.WriteString(" ON ")
+		sb.WriteString(ds.Table.String())
+	}
+	return sb.String()
+}
+
+// PermissionStatement is a GRANT, DENY or REVOKE of permissions, e.g.
+// GRANT SELECT, INSERT ON dbo.orders TO app_role WITH GRANT OPTION
+type PermissionStatement struct {
+	BaseNode
+	Action      string          // GRANT, DENY or REVOKE
+	Permissions []string        // e.g. SELECT, EXECUTE, CREATE TABLE
+	ObjectClass string          // SCHEMA in ON SCHEMA::Sales, empty for plain objects
+	Object      *TableReference // nil for database-wide permissions
+	Principals  []string
+	GrantOption bool // WITH GRANT OPTION, or REVOKE GRANT OPTION FOR
+	Cascade     bool
+}
+
+func (ps *PermissionStatement) statementNode() {}
+func (ps *PermissionStatement) Type() string   { return "PermissionStatement" }
+func (ps *PermissionStatement) String() string {
+	var sb strings.Builder
+	sb.WriteString(ps.Action)
+	if ps.GrantOption && ps.Action == "REVOKE" {
+		sb.WriteString(" GRANT OPTION FOR")
+	}
+	sb.WriteString(" ")
+	sb.WriteString(strings.Join(ps.Permissions, ", "))
+	if ps.Object != nil {
+		sb.WriteString(" ON ")
+		if ps.ObjectClass != "" {
+			sb.WriteString(ps.ObjectClass)
+			sb.WriteString("::")
+		}
+		sb.WriteString(ps.Object.String())
+	}
+	if ps.Action == "REVOKE" {
+		sb.WriteString(" FROM ")
+	} else {
+		sb.WriteString(" TO ")
+	}
+	sb.WriteString(joinIdents(ps.Principals))
+	if ps.GrantOption && ps.Action == "GRANT" {
+		sb.WriteString(" WITH GRANT OPTION")
+	}
+	if ps.Cascade {
+		sb.WriteString(" CASCADE")
+	}
+	return sb.String()
+}
 
 // Unary Expression (NOT, etc.)
 type UnaryExpression struct {
@@ -239,7 +1532,10 @@ type UnaryExpression struct {
 func (ue *UnaryExpression) expressionNode() {}
 func (ue *UnaryExpression) Type() string    { return "UnaryExpression" }
 func (ue *UnaryExpression) String() string {
-	return fmt.Sprintf("%s %s", ue.Operator, ue.Operand.String())
+	if ue.Operator == "-" || ue.Operator == "+" || ue.Operator == "~" {
+		return fmt.Sprintf("(%s%s)", ue.Operator, ue.Operand.String())
+	}
+	return fmt.Sprintf("(%s %s)", ue.Operator, ue.Operand.String())
 }
 
 // IN Expression
@@ -247,22 +1543,27 @@ type InExpression struct {
 	BaseNode
 	Expression Expression
 	Values     []Expression
+	Subquery   *SelectStatement // IN (SELECT ...), mutually exclusive with Values
 	Not        bool
 }
 
 func (ie *InExpression) expressionNode() {}
 func (ie *InExpression) Type() string    { return "InExpression" }
 func (ie *InExpression) String() string {
+	operator := "IN"
 	if ie.Not {
-		return fmt.Sprintf("%s NOT IN (...)", ie.Expression.String())
+		operator = "NOT IN"
+	}
+	if ie.Subquery != nil {
+		return fmt.Sprintf("(%s %s (%s))", ie.Expression.String(), operator, ie.Subquery.String())
 	}
-	return fmt.Sprintf("%s IN (...)", ie.Expression.String())
+	return fmt.Sprintf("(%s %s (%s))", ie.Expression.String(), operator, joinExpressions(ie.Values))
 }
 
 // EXISTS Expression
 type ExistsExpression struct {
 	BaseNode
-	Subquery Statement
+	Subquery *SelectStatement
 	Not      bool
 }
 
@@ -270,9 +1571,22 @@ func (ee *ExistsExpression) expressionNode() {}
 func (ee *ExistsExpression) Type() string    { return "ExistsExpression" }
 func (ee *ExistsExpression) String() string {
 	if ee.Not {
-		return "NOT EXISTS (...)"
+		return fmt.Sprintf("NOT EXISTS (%s)", ee.Subquery.String())
 	}
-	return "EXISTS (...)"
+	return fmt.Sprintf("EXISTS (%s)", ee.Subquery.String())
+}
+
+// RowValueExpression is a row value constructor such as (a, b), compared as a
+// whole, e.g. WHERE (a, b) IN ((1, 2), (3, 4)) or WHERE (a, b) > (1, 2)
+type RowValueExpression struct {
+	BaseNode
+	Values []Expression
+}
+
+func (rv *RowValueExpression) expressionNode() {}
+func (rv *RowValueExpression) Type() string    { return "RowValueExpression" }
+func (rv *RowValueExpression) String() string {
+	return "(" + joinExpressions(rv.Values) + ")"
 }
 
 // SubqueryExpression wraps a SelectStatement to make it usable as an Expression
@@ -286,3 +1600,217 @@ func (se *SubqueryExpression) Type() string    { return "SubqueryExpression" }
 func (se *SubqueryExpression) String() string {
 	return fmt.Sprintf("(%s)", se.Query.String())
 }
+
+// BETWEEN Expression
+type BetweenExpression struct {
+	BaseNode
+	Expr  Expression
+	Lower Expression
+	Upper Expression
+	Not   bool
+}
+
+func (be *BetweenExpression) expressionNode() {}
+func (be *BetweenExpression) Type() string    { return "BetweenExpression" }
+func (be *BetweenExpression) String() string {
+	if be.Not {
+		return fmt.Sprintf("(%s NOT BETWEEN %s AND %s)", be.Expr.String(), be.Lower.String(), be.Upper.String())
+	}
+	return fmt.Sprintf("(%s BETWEEN %s AND %s)", be.Expr.String(), be.Lower.String(), be.Upper.String())
+}
+
+// [NOT] LIKE Expression with optional ESCAPE character
+type LikeExpression struct {
+	BaseNode
+	Expr    Expression
+	Pattern Expression
+	Escape  Expression // nil when there is no ESCAPE clause
+	Not     bool
+}
+
+func (le *LikeExpression) expressionNode() {}
+func (le *LikeExpression) Type() string    { return "LikeExpression" }
+func (le *LikeExpression) String() string {
+	operator := "LIKE"
+	if le.Not {
+		operator = "NOT LIKE"
+	}
+	if le.Escape != nil {
+		return fmt.Sprintf("(%s %s %s ESCAPE %s)", le.Expr.String(), operator, le.Pattern.String(), le.Escape.String())
+	}
+	return fmt.Sprintf("(%s %s %s)", le.Expr.String(), operator, le.Pattern.String())
+}
+
+// CurrentOfClause is the WHERE CURRENT OF condition of a positioned UPDATE
+// or DELETE, which affects the row a cursor was last fetched from
+type CurrentOfClause struct {
+	BaseNode
+	Cursor string // cursor name, or @variable holding a cursor
+	Global bool   // CURRENT OF GLOBAL cursor_name
+}
+
+func (co *CurrentOfClause) expressionNode() {}
+func (co *CurrentOfClause) Type() string    { return "CurrentOfClause" }
+func (co *CurrentOfClause) String() string {
+	if co.Global {
+		return "CURRENT OF GLOBAL " + QuoteIdent(co.Cursor)
+	}
+	return "CURRENT OF " + QuoteIdent(co.Cursor)
+}
+
+// CollateExpression applies a collation to a string expression, as in
+// name COLLATE Latin1_General_CS_AS = 'Smith'
+type CollateExpression struct {
+	BaseNode
+	Expr      Expression
+	Collation string
+}
+
+func (ce *CollateExpression) expressionNode() {}
+func (ce *CollateExpression) Type() string    { return "CollateExpression" }
+func (ce *CollateExpression) String() string {
+	return ce.Expr.String() + " COLLATE " + ce.Collation
+}
+
+// FullTextPredicate is a SQL Server full-text search condition, e.g.
+// CONTAINS(description, 'bike') or FREETEXT((title, body), @phrase, LANGUAGE 1033)
+type FullTextPredicate struct {
+	BaseNode
+	Function string       // CONTAINS or FREETEXT
+	Columns  []Expression // column references, or a single *
+	Search   Expression
+	Language Expression // nil when there is no LANGUAGE argument
+}
+
+func (ft *FullTextPredicate) expressionNode() {}
+func (ft *FullTextPredicate) Type() string    { return "FullTextPredicate" }
+func (ft *FullTextPredicate) String() string {
+	columns := make([]string, len(ft.Columns))
+	for i, col := range ft.Columns {
+		columns[i] = col.String()
+	}
+	target := strings.Join(columns, ", ")
+	if len(ft.Columns) > 1 {
+		target = "(" + target + ")"
+	}
+
+	result := fmt.Sprintf("%s(%s, %s", ft.Function, target, ft.Search.String())
+	if ft.Language != nil {
+		result += ", LANGUAGE " + ft.Language.String()
+	}
+	return result + ")"
+}
+
+// IS [NOT] NULL Expression
+type IsNullExpression struct {
+	BaseNode
+	Expr    Expression
+	Negated bool
+}
+
+func (ie *IsNullExpression) expressionNode() {}
+func (ie *IsNullExpression) Type() string    { return "IsNullExpression" }
+func (ie *IsNullExpression) String() string {
+	if ie.Negated {
+		return fmt.Sprintf("(%s IS NOT NULL)", ie.Expr.String())
+	}
+	return fmt.Sprintf("(%s IS NULL)", ie.Expr.String())
+}
+
+// CASE Expression (searched CASE when Operand is nil, simple CASE otherwise)
+type CaseExpression struct {
+	BaseNode
+	Operand     Expression
+	WhenClauses []*WhenClause
+	Else        Expression
+}
+
+func (ce *CaseExpression) expressionNode() {}
+func (ce *CaseExpression) Type() string    { return "CaseExpression" }
+func (ce *CaseExpression) String() string {
+	var sb strings.Builder
+	sb.WriteString("CASE")
+	if ce.Operand != nil {
+		sb.WriteString(" ")
+		sb.WriteString(ce.Operand.String())
+	}
+	for _, when := range ce.WhenClauses {
+		sb.WriteString(" ")
+		sb.WriteString(when.String())
+	}
+	if ce.Else != nil {
+		sb.WriteString(" ELSE ")
+		sb.WriteString(ce.Else.String())
+	}
+	sb.WriteString(" END")
+	return sb.String()
+}
+
+// WHEN ... THEN ... branch of a CASE expression
+type WhenClause struct {
+	BaseNode
+	Condition Expression
+	Result    Expression
+}
+
+func (wc *WhenClause) Type() string { return "WhenClause" }
+func (wc *WhenClause) String() string {
+	return fmt.Sprintf("WHEN %s THEN %s", wc.Condition.String(), wc.Result.String())
+}
+
+// joinExpressions renders a comma-separated expression list
+func joinExpressions(exprs []Expression) string {
+	parts := make([]string, len(exprs))
+	for i, expr := range exprs {
+		parts[i] = expr.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// joinGroupings renders grouping lists, parenthesizing multi-column groups
+func joinGroupings(groups [][]Expression) string {
+	parts := make([]string, len(groups))
+	for i, group := range groups {
+		if len(group) == 1 {
+			parts[i] = group[0].String()
+		} else {
+			parts[i] = "(" + joinExpressions(group) + ")"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// joinOrderBy renders a comma-separated ORDER BY item list
+func joinOrderBy(items []*OrderByClause) string {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = item.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// plainIdent matches names that need no brackets unless they are keywords,
+// including #temp tables and @table variables
+var plainIdent = regexp.MustCompile(`^(#{1,2}|@)?[A-Za-z_][A-Za-z0-9_]*$`)
+
+// QuoteIdent writes name so that it parses back to the same identifier:
+// names that are not plain words, or are keywords, are put in brackets, with
+// ] escaped as ]]. GO is bracketed as well, since on a line of its own it
+// would end the batch. An empty name, which stands for an omitted part such as
+// the schema in db..t, is returned empty.
+func QuoteIdent(name string) string {
+	upper := strings.ToUpper(name)
+	if name == "" || plainIdent.MatchString(name) && lexer.LookupIdent(upper) == lexer.IDENT && upper != "GO" {
+		return name
+	}
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+// joinIdents renders a comma-separated list of quoted names
+func joinIdents(names []string) string {
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = QuoteIdent(name)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,208 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/dialect"
+)
+
+// BuiltinFunction describes how many arguments a built-in function takes
+type BuiltinFunction struct {
+	Name    string
+	MinArgs int
+	MaxArgs int // -1 when there is no upper bound
+}
+
+// variadic marks a BuiltinFunction without an upper bound on its arguments
+const variadic = -1
+
+// builtinFunctions lists functions whose arity is the same in every dialect,
+// keyed by upper-cased name
+var builtinFunctions = builtinMap(
+	BuiltinFunction{"COUNT", 1, 1},
+	BuiltinFunction{"SUM", 1, 1},
+	BuiltinFunction{"AVG", 1, 1},
+	BuiltinFunction{"MIN", 1, 1},
+	BuiltinFunction{"MAX", 1, 1},
+	BuiltinFunction{"COALESCE", 1, variadic},
+	BuiltinFunction{"CONCAT", 1, variadic},
+	BuiltinFunction{"NULLIF", 2, 2},
+	BuiltinFunction{"UPPER", 1, 1},
+	BuiltinFunction{"LOWER", 1, 1},
+	BuiltinFunction{"ABS", 1, 1},
+	BuiltinFunction{"ROUND", 1, 3},
+	BuiltinFunction{"SUBSTRING", 2, 3},
+	BuiltinFunction{"REPLACE", 3, 3},
+	BuiltinFunction{"LEFT", 2, 2},
+	BuiltinFunction{"RIGHT", 2, 2},
+	BuiltinFunction{"ROW_NUMBER", 0, 0},
+	BuiltinFunction{"RANK", 0, 0},
+	BuiltinFunction{"DENSE_RANK", 0, 0},
+	BuiltinFunction{"NTILE", 1, 1},
+	BuiltinFunction{"LAG", 1, 3},
+	BuiltinFunction{"LEAD", 1, 3},
+	BuiltinFunction{"FIRST_VALUE", 1, 1},
+	BuiltinFunction{"LAST_VALUE", 1, 1},
+)
+
+// dialectBuiltinFunctions adds functions specific to a dialect, keyed by
+// dialect name, and overrides the arity of common ones
+var dialectBuiltinFunctions = map[string]map[string]BuiltinFunction{
+	"SQL Server": builtinMap(
+		BuiltinFunction{"ISNULL", 2, 2},
+		BuiltinFunction{"COALESCE", 2, variadic},
+		BuiltinFunction{"ROUND", 2, 3},
+		BuiltinFunction{"SUBSTRING", 3, 3},
+		BuiltinFunction{"IIF", 3, 3},
+		BuiltinFunction{"LEN", 1, 1},
+		BuiltinFunction{"CHARINDEX", 2, 3},
+		BuiltinFunction{"GETDATE", 0, 0},
+		BuiltinFunction{"DATEADD", 3, 3},
+		BuiltinFunction{"DATEDIFF", 3, 3},
+		BuiltinFunction{"DATEDIFF_BIG", 3, 3},
+		BuiltinFunction{"DATENAME", 2, 2},
+		BuiltinFunction{"DATEPART", 2, 2},
+	),
+	"MySQL": builtinMap(
+		BuiltinFunction{"COUNT", 1, variadic}, // COUNT(DISTINCT a, b)
+		BuiltinFunction{"ISNULL", 1, 1},
+		BuiltinFunction{"IFNULL", 2, 2},
+		BuiltinFunction{"NOW", 0, 1},
+	),
+	"PostgreSQL": builtinMap(
+		BuiltinFunction{"NOW", 0, 0},
+	),
+	"SQLite": builtinMap(
+		BuiltinFunction{"IFNULL", 2, 2},
+	),
+	"Oracle": builtinMap(
+		BuiltinFunction{"NVL", 2, 2},
+	),
+}
+
+// windowOnlyFunctions are the functions that are only valid with an OVER
+// clause, keyed by upper-cased name
+var windowOnlyFunctions = map[string]bool{
+	"ROW_NUMBER":   true,
+	"RANK":         true,
+	"DENSE_RANK":   true,
+	"NTILE":        true,
+	"PERCENT_RANK": true,
+	"CUME_DIST":    true,
+	"LAG":          true,
+	"LEAD":         true,
+	"FIRST_VALUE":  true,
+	"LAST_VALUE":   true,
+}
+
+// IsWindowOnlyFunction reports whether the function called name requires an
+// OVER clause, e.g. ROW_NUMBER. Names are case-insensitive.
+func IsWindowOnlyFunction(name string) bool {
+	return windowOnlyFunctions[strings.ToUpper(name)]
+}
+
+// datePartFunctions are the SQL Server functions whose first argument is a
+// date part rather than an expression
+var datePartFunctions = map[string]bool{
+	"DATEADD":      true,
+	"DATEDIFF":     true,
+	"DATEDIFF_BIG": true,
+	"DATENAME":     true,
+	"DATEPART":     true,
+	"DATETRUNC":    true,
+}
+
+// dateParts maps each SQL Server date part and abbreviation to its full name
+var dateParts = map[string]string{
+	"YEAR": "YEAR", "YY": "YEAR", "YYYY": "YEAR",
+	"QUARTER": "QUARTER", "QQ": "QUARTER", "Q": "QUARTER",
+	"MONTH": "MONTH", "MM": "MONTH", "M": "MONTH",
+	"DAYOFYEAR": "DAYOFYEAR", "DY": "DAYOFYEAR", "Y": "DAYOFYEAR",
+	"DAY": "DAY", "DD": "DAY", "D": "DAY",
+	"WEEK": "WEEK", "WK": "WEEK", "WW": "WEEK",
+	"WEEKDAY": "WEEKDAY", "DW": "WEEKDAY", "W": "WEEKDAY",
+	"HOUR": "HOUR", "HH": "HOUR",
+	"MINUTE": "MINUTE", "MI": "MINUTE", "N": "MINUTE",
+	"SECOND": "SECOND", "SS": "SECOND", "S": "SECOND",
+	"MILLISECOND": "MILLISECOND", "MS": "MILLISECOND",
+	"MICROSECOND": "MICROSECOND", "MCS": "MICROSECOND",
+	"NANOSECOND": "NANOSECOND", "NS": "NANOSECOND",
+	"ISO_WEEK": "ISO_WEEK", "ISOWK": "ISO_WEEK", "ISOWW": "ISO_WEEK",
+	"TZOFFSET": "TZOFFSET", "TZ": "TZOFFSET",
+}
+
+func builtinMap(functions ...BuiltinFunction) map[string]BuiltinFunction {
+	m := make(map[string]BuiltinFunction, len(functions))
+	for _, f := range functions {
+		m[f.Name] = f
+	}
+	return m
+}
+
+// LookupBuiltinFunction returns the built-in function called name in dialect d.
+// Names are case-insensitive; a nil dialect only finds the common functions.
+func LookupBuiltinFunction(name string, d dialect.Dialect) (BuiltinFunction, bool) {
+	name = strings.ToUpper(name)
+	if d != nil {
+		if f, ok := dialectBuiltinFunctions[d.Name()][name]; ok {
+			return f, true
+		}
+	}
+	f, ok := builtinFunctions[name]
+	return f, ok
+}
+
+// CheckArguments reports an error if n arguments is the wrong number for f
+func (f BuiltinFunction) CheckArguments(n int) error {
+	if n >= f.MinArgs && (f.MaxArgs == variadic || n <= f.MaxArgs) {
+		return nil
+	}
+
+	var want string
+	switch {
+	case f.MaxArgs == variadic:
+		want = fmt.Sprintf("at least %d", f.MinArgs)
+	case f.MinArgs == f.MaxArgs:
+		want = fmt.Sprintf("%d", f.MinArgs)
+	default:
+		want = fmt.Sprintf("%d to %d", f.MinArgs, f.MaxArgs)
+	}
+	noun := "arguments"
+	if want == "1" || want == "at least 1" {
+		noun = "argument"
+	}
+	return fmt.Errorf("%s takes %s %s, got %d", f.Name, want, noun, n)
+}
+
+// ValidateFunctionCalls checks the number of arguments of every call to a
+// known built-in function in node, e.g. ISNULL(a, b, c) in SQL Server, and
+// that window-only functions such as ROW_NUMBER have an OVER clause. It
+// returns the problems found joined into one error, each a *ParseError at
+// the call. Calls to other functions are not checked.
+func ValidateFunctionCalls(node Node, d dialect.Dialect) error {
+	if node == nil {
+		return nil
+	}
+
+	var errs []error
+	Inspect(node, func(n Node) bool {
+		call, ok := n.(*FunctionCall)
+		if !ok {
+			return true
+		}
+		start, _ := call.Span()
+		if f, ok := LookupBuiltinFunction(call.Name, d); ok {
+			if err := f.CheckArguments(len(call.Arguments)); err != nil {
+				errs = append(errs, NewParseError(err.Error(), call.Name, start.Line, start.Column))
+			}
+		}
+		if call.Over == nil && IsWindowOnlyFunction(call.Name) {
+			msg := fmt.Sprintf("%s requires an OVER clause", strings.ToUpper(call.Name))
+			errs = append(errs, NewParseError(msg, call.Name, start.Line, start.Column))
+		}
+		return true
+	})
+	return errors.Join(errs...)
+}
@@ -0,0 +1,98 @@
+package parser
+
+import "reflect"
+
+// Deep copies of the AST. Every node of a copy is newly allocated, never taken
+// from the object pools, so a copy stays valid after the nodes of the original
+// are returned to a pool and reused, and rewriting a copy leaves the original
+// untouched.
+
+// CloneNode returns a deep copy of any AST node, of the same concrete type
+func CloneNode(node Node) Node {
+	if node == nil {
+		return nil
+	}
+	return cloneValue(reflect.ValueOf(node)).Interface().(Node)
+}
+
+// cloneStatement copies a statement for the Clone methods
+func cloneStatement(stmt Statement) Statement {
+	return CloneNode(stmt).(Statement)
+}
+
+// Clone returns a deep copy of the statement
+func (ss *SelectStatement) Clone() Statement { return cloneStatement(ss) }
+
+// Clone returns a deep copy of the statement
+func (so *SetOperation) Clone() Statement { return cloneStatement(so) }
+
+// Clone returns a deep copy of the statement
+func (is *InsertStatement) Clone() Statement { return cloneStatement(is) }
+
+// Clone returns a deep copy of the statement
+func (us *UpdateStatement) Clone() Statement { return cloneStatement(us) }
+
+// Clone returns a deep copy of the statement
+func (ds *DeleteStatement) Clone() Statement { return cloneStatement(ds) }
+
+// Clone returns a deep copy of the statement
+func (ms *MergeStatement) Clone() Statement { return cloneStatement(ms) }
+
+// Clone returns a deep copy of the statement
+func (cts *CreateTableStatement) Clone() Statement { return cloneStatement(cts) }
+
+// Clone returns a deep copy of the statement
+func (ats *AlterTableStatement) Clone() Statement { return cloneStatement(ats) }
+
+// Clone returns a deep copy of the statement
+func (cvs *CreateViewStatement) Clone() Statement { return cloneStatement(cvs) }
+
+// Clone returns a deep copy of the statement
+func (cis *CreateIndexStatement) Clone() Statement { return cloneStatement(cis) }
+
+// Clone returns a deep copy of the statement
+func (ds *DropStatement) Clone() Statement { return cloneStatement(ds) }
+
+// Clone returns a deep copy of the statement
+func (ps *PermissionStatement) Clone() Statement { return cloneStatement(ps) }
+
+// cloneValue copies v, following pointers, slices and interfaces. Strings
+// and other immutable values are shared.
+func cloneValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(cloneValue(v.Elem()))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(cloneValue(v.Elem()))
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(cloneValue(v.Index(i)))
+		}
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				copied.Field(i).Set(cloneValue(v.Field(i)))
+			}
+		}
+		return copied
+	default:
+		return v
+	}
+}
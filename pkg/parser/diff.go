@@ -0,0 +1,247 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ChangeKind says how a part of a statement differs between two versions
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "changed"
+)
+
+// Change is one difference found by Diff
+type Change struct {
+	Kind ChangeKind
+	// Path locates the changed part by field names and list indexes, e.g.
+	// Columns[2], Where.Right or Joins[0].Condition. It is empty when the
+	// two statements have different types.
+	Path string
+	Old  string // SQL text of the old part, empty when added
+	New  string // SQL text of the new part, empty when removed
+}
+
+// String describes the change, e.g. "changed Where.Right from 1 to 2"
+func (c Change) String() string {
+	path := c.Path
+	if path == "" {
+		path = "statement"
+	}
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("added %s: %s", path, c.New)
+	case ChangeRemoved:
+		return fmt.Sprintf("removed %s: %s", path, c.Old)
+	}
+	return fmt.Sprintf("changed %s from %s to %s", path, c.Old, c.New)
+}
+
+// Diff compares two statements structurally, with the same rules as Equal,
+// and returns what changed from a to b in source order. Items added to or
+// removed from a list, such as select columns or joins, are reported on
+// their own; an expression that changed is reported whole at the deepest
+// path where the two versions still have the same shape, so changing
+// WHERE a > 1 to WHERE a > 2 reports Where.Right. Diff returns nil when the
+// statements are equal.
+func Diff(a, b Statement) []Change {
+	d := &differ{}
+	if isNilNode(a) && isNilNode(b) {
+		return nil
+	}
+	if isNilNode(a) || isNilNode(b) || reflect.TypeOf(a) != reflect.TypeOf(b) {
+		d.report("", reflect.ValueOf(a), reflect.ValueOf(b))
+		return d.changes
+	}
+	d.diff(reflect.ValueOf(a), reflect.ValueOf(b), "", "")
+	return d.changes
+}
+
+type differ struct {
+	changes []Change
+}
+
+// diff compares a and b, which have the same type, found at path. field is
+// the name of the struct field they were read from, if any.
+func (d *differ) diff(a, b reflect.Value, path, field string) {
+	if equalValues(a, b, field) {
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() || a.Elem().Type() != b.Elem().Type() {
+			d.report(path, a, b)
+			return
+		}
+		d.diff(a.Elem(), b.Elem(), path, field)
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() || a.Elem().Kind() != reflect.Struct {
+			d.report(path, a, b)
+			return
+		}
+		d.diff(a.Elem(), b.Elem(), path, "")
+	case reflect.Slice:
+		d.diffSlices(a, b, path)
+	case reflect.Struct:
+		d.diffStructs(a, b, path)
+	default:
+		d.report(path, a, b)
+	}
+}
+
+// diffStructs compares the fields of two structs. A node other than a
+// statement whose own names, operators or values differ is reported whole.
+func (d *differ) diffStructs(a, b reflect.Value, path string) {
+	_, isStatement := asNode(a).(Statement)
+	if asNode(a) != nil && !isStatement {
+		for i := 0; i < a.NumField(); i++ {
+			f := a.Type().Field(i)
+			if f.Type == baseNodeType || !f.IsExported() || !isScalarField(f.Type) {
+				continue
+			}
+			if !equalValues(a.Field(i), b.Field(i), f.Name) {
+				d.report(path, a, b)
+				return
+			}
+		}
+	}
+
+	for i := 0; i < a.NumField(); i++ {
+		f := a.Type().Field(i)
+		if f.Type == baseNodeType || !f.IsExported() {
+			continue
+		}
+		d.diff(a.Field(i), b.Field(i), joinPath(path, f.Name), f.Name)
+	}
+}
+
+// diffSlices aligns two lists on their longest common subsequence of equal
+// items. Between two aligned items, as many items as were both removed and
+// added are compared pairwise; the rest are reported as added or removed.
+func (d *differ) diffSlices(a, b reflect.Value, path string) {
+	n, m := a.Len(), b.Len()
+	// lcs[i][j] is the length of the common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if equalValues(a.Index(i), b.Index(j), "") {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		// Collect the unaligned items up to the next aligned pair
+		startI, startJ := i, j
+		for i < n && j < m && !equalValues(a.Index(i), b.Index(j), "") {
+			if lcs[i+1][j] >= lcs[i][j+1] {
+				i++
+			} else {
+				j++
+			}
+		}
+		if i == n || j == m {
+			i, j = n, m
+		}
+
+		k := 0
+		for ; startI+k < i && startJ+k < j; k++ {
+			d.diff(a.Index(startI+k), b.Index(startJ+k), indexPath(path, startJ+k), "")
+		}
+		for x := startI + k; x < i; x++ {
+			d.report(indexPath(path, x), a.Index(x), reflect.Value{})
+		}
+		for y := startJ + k; y < j; y++ {
+			d.report(indexPath(path, y), reflect.Value{}, b.Index(y))
+		}
+
+		if i < n && j < m {
+			i++
+			j++
+		}
+	}
+}
+
+// report records a change from a to b at path; an invalid or nil value
+// means the part is absent on that side
+func (d *differ) report(path string, a, b reflect.Value) {
+	change := Change{Kind: ChangeModified, Path: path, Old: sqlText(a), New: sqlText(b)}
+	switch {
+	case isAbsent(a):
+		change.Kind = ChangeAdded
+	case isAbsent(b):
+		change.Kind = ChangeRemoved
+	}
+	d.changes = append(d.changes, change)
+}
+
+func isAbsent(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+// sqlText renders a value for a change description
+func sqlText(v reflect.Value) string {
+	if isAbsent(v) {
+		return ""
+	}
+	if node := asNode(v); node != nil {
+		return node.String()
+	}
+	if v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		return sqlText(v.Elem())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// asNode returns v as a Node, taking the address of node structs held by
+// value such as the tables of a FROM clause, or nil if v is not a node
+func asNode(v reflect.Value) Node {
+	if v.Kind() == reflect.Struct && v.CanAddr() {
+		v = v.Addr()
+	}
+	if !v.CanInterface() {
+		return nil
+	}
+	node, _ := v.Interface().(Node)
+	return node
+}
+
+// isScalarField reports whether a field of type t holds a name, flag or
+// value rather than child nodes
+func isScalarField(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Struct:
+		return false
+	case reflect.Interface:
+		return t.NumMethod() == 0
+	}
+	return true
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func indexPath(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ToDOT renders the tree rooted at node as a Graphviz DOT digraph, e.g. for
+// dot -Tpng. Each node is labeled with its type followed by its set scalar
+// fields, such as the operator of a BinaryExpression, the name of a column or
+// the value of a literal. Children are linked in the order Walk visits them.
+func ToDOT(node Node) string {
+	d := &dotWriter{}
+	d.sb.WriteString("digraph AST {\n")
+	d.sb.WriteString("  node [shape=box, fontname=\"Helvetica\"];\n")
+	if node != nil {
+		Walk(node, d)
+	}
+	d.sb.WriteString("}\n")
+	return d.sb.String()
+}
+
+// dotWriter is a Visitor that writes a DOT node per AST node and an edge from
+// its parent
+type dotWriter struct {
+	sb      strings.Builder
+	nextID  int
+	parents []int
+}
+
+func (d *dotWriter) Visit(node Node) Visitor {
+	if node == nil {
+		d.parents = d.parents[:len(d.parents)-1]
+		return nil
+	}
+
+	id := d.nextID
+	d.nextID++
+	fmt.Fprintf(&d.sb, "  n%d [label=\"%s\"];\n", id, dotEscape(dotLabel(node)))
+	if len(d.parents) > 0 {
+		fmt.Fprintf(&d.sb, "  n%d -> n%d;\n", d.parents[len(d.parents)-1], id)
+	}
+	d.parents = append(d.parents, id)
+	return d
+}
+
+// dotLabel returns the type of node and its non-empty string, number, bool
+// and string list fields, one per line
+func dotLabel(node Node) string {
+	if lit, ok := node.(*Literal); ok {
+		return node.Type() + "\n" + lit.String()
+	}
+
+	v := reflect.ValueOf(node)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return node.Type()
+	}
+	v = v.Elem()
+	lines := []string{node.Type()}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		value := v.Field(i)
+		var text string
+		switch value.Kind() {
+		case reflect.String:
+			text = value.String()
+		case reflect.Bool:
+			if value.Bool() {
+				text = "true"
+			}
+		case reflect.Int, reflect.Int64:
+			if value.Int() != 0 {
+				text = fmt.Sprint(value.Int())
+			}
+		case reflect.Slice:
+			if strs, ok := value.Interface().([]string); ok {
+				text = strings.Join(strs, ", ")
+			}
+		}
+		if text != "" {
+			lines = append(lines, field.Name+": "+text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// dotEscape quotes text for a DOT string, keeping newlines as line breaks
+func dotEscape(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, `"`, `\"`)
+	return strings.ReplaceAll(text, "\n", `\n`)
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+)
+
+var baseNodeType = reflect.TypeOf(BaseNode{})
+
+// Equal reports whether two ASTs have the same structure: the same node types,
+// names, operators, literal values and children. Source positions are ignored,
+// as are the differences between a nil and an empty list. Operators are
+// keywords and compare case-insensitively ("and" equals "AND"); names compare
+// exactly. Integer literals equal integer literals of the same value whatever
+// their Go type, and likewise for floats, but 1 never equals 1.0.
+func Equal(a, b Node) bool {
+	if a == nil || b == nil {
+		return isNilNode(a) && isNilNode(b)
+	}
+	return equalValues(reflect.ValueOf(a), reflect.ValueOf(b), "")
+}
+
+func isNilNode(node Node) bool {
+	if node == nil {
+		return true
+	}
+	rv := reflect.ValueOf(node)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// equalValues compares a and b, which have the same type. field is the name
+// of the struct field they were read from, if any.
+func equalValues(a, b reflect.Value, field string) bool {
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		// Literal values are the only interface{} fields
+		if a.Kind() == reflect.Interface && a.NumMethod() == 0 {
+			return equalLiteralValues(a.Elem().Interface(), b.Elem().Interface())
+		}
+		if a.Elem().Type() != b.Elem().Type() {
+			return false
+		}
+		return equalValues(a.Elem(), b.Elem(), "")
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !equalValues(a.Index(i), b.Index(i), "") {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			f := a.Type().Field(i)
+			if f.Type == baseNodeType || !f.IsExported() {
+				continue
+			}
+			if !equalValues(a.Field(i), b.Field(i), f.Name) {
+				return false
+			}
+		}
+		return true
+	case reflect.String:
+		if field == "Operator" {
+			return strings.EqualFold(a.String(), b.String())
+		}
+		return a.String() == b.String()
+	default:
+		return a.Interface() == b.Interface()
+	}
+}
+
+// equalLiteralValues compares the values of two literals
+func equalLiteralValues(a, b interface{}) bool {
+	if ab, ok := a.([]byte); ok {
+		bb, ok := b.([]byte)
+		return ok && bytes.Equal(ab, bb)
+	}
+
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case av.CanInt() && bv.CanInt():
+		return av.Int() == bv.Int()
+	case av.CanUint() && bv.CanUint():
+		return av.Uint() == bv.Uint()
+	case av.CanInt() && bv.CanUint():
+		return av.Int() >= 0 && uint64(av.Int()) == bv.Uint()
+	case av.CanUint() && bv.CanInt():
+		return bv.Int() >= 0 && av.Uint() == uint64(bv.Int())
+	case av.CanFloat() && bv.CanFloat():
+		return av.Float() == bv.Float()
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -3,7 +3,13 @@
 // SELECT, INSERT, UPDATE, and DELETE statements with complex joins and expressions.
 package parser
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
 
 // ParseError represents errors that occur during SQL parsing.
 // It provides detailed information about the location and nature of parsing errors.
@@ -20,6 +26,16 @@ func (e *ParseError) Error() string {
 		e.Line, e.Column, e.Message, e.Token)
 }
 
+// ErrorWithContext renders the error followed by the offending line of src,
+// with the token underlined:
+//
+//	parse error at line 1, column 8: ... (near 'FROM')
+//	  1 | SELECT FROM users
+//	    |        ^^^^
+func (e *ParseError) ErrorWithContext(src string) string {
+	return withSourceContext(e.Error(), src, e.Line, e.Column, len(e.Token))
+}
+
 // NewParseError creates a new ParseError with the given details.
 func NewParseError(message, token string, line, column int) *ParseError {
 	return &ParseError{
@@ -44,6 +60,12 @@ func (e *SyntaxError) Error() string {
 		e.Line, e.Column, e.Expected, e.Found)
 }
 
+// ErrorWithContext renders the error followed by the offending line of src,
+// with the unexpected token underlined.
+func (e *SyntaxError) ErrorWithContext(src string) string {
+	return withSourceContext(e.Error(), src, e.Line, e.Column, len(e.Found))
+}
+
 // NewSyntaxError creates a new SyntaxError with the given details.
 func NewSyntaxError(expected, found string, line, column int) *SyntaxError {
 	return &SyntaxError{
@@ -53,3 +75,100 @@ func NewSyntaxError(expected, found string, line, column int) *SyntaxError {
 		Column:   column,
 	}
 }
+
+// ErrParseCancelled matches any *CancelledError via errors.Is.
+var ErrParseCancelled = errors.New("parsing cancelled")
+
+// CancelledError is returned when the parser's context is cancelled or its
+// deadline passes before parsing completes.
+type CancelledError struct {
+	Err error // the context's error
+}
+
+// Error returns a message describing why parsing stopped.
+func (e *CancelledError) Error() string {
+	if errors.Is(e.Err, context.DeadlineExceeded) {
+		return "parsing cancelled due to timeout"
+	}
+	return fmt.Sprintf("parsing cancelled: %v", e.Err)
+}
+
+// Unwrap returns the context's error, so errors.Is(err, context.DeadlineExceeded) works.
+func (e *CancelledError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrParseCancelled.
+func (e *CancelledError) Is(target error) bool {
+	return target == ErrParseCancelled
+}
+
+// ErrorsWithContext renders every error in err with the source line it refers
+// to. Errors joined together, such as those returned by ParseProgram, are
+// rendered one after another; errors without a position are rendered as is.
+func ErrorsWithContext(err error, src string) string {
+	if err == nil {
+		return ""
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		parts := make([]string, 0, len(joined.Unwrap()))
+		for _, e := range joined.Unwrap() {
+			parts = append(parts, ErrorsWithContext(e, src))
+		}
+		return strings.Join(parts, "\n")
+	}
+
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr.ErrorWithContext(src)
+	}
+	var syntaxErr *SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return syntaxErr.ErrorWithContext(src)
+	}
+	return err.Error()
+}
+
+// withSourceContext appends line of src to message, with width carets under column
+func withSourceContext(message, src string, line, column, width int) string {
+	lines := strings.Split(src, "\n")
+	if line < 1 || line > len(lines) {
+		return message
+	}
+	text := strings.TrimRight(lines[line-1], "\r")
+
+	// Clamp the underline to the line, keeping at least one caret
+	start := column - 1
+	if start < 0 {
+		start = 0
+	}
+	if start > len(text) {
+		start = len(text)
+	}
+	if width > len(text)-start {
+		width = len(text) - start
+	}
+	// Columns count bytes, but a terminal shows one character per rune
+	width = utf8.RuneCountInString(text[start : start+width])
+	if width < 1 {
+		width = 1
+	}
+
+	// Keep tabs in the padding so the carets line up with the source
+	var pad strings.Builder
+	for _, ch := range text[:start] {
+		if ch == '\t' {
+			pad.WriteByte('\t')
+		} else {
+			pad.WriteByte(' ')
+		}
+	}
+
+	gutter := fmt.Sprintf("%d", line)
+	var sb strings.Builder
+	sb.WriteString(message)
+	sb.WriteString(fmt.Sprintf("\n  %s | %s", gutter, text))
+	sb.WriteString(fmt.Sprintf("\n  %s | %s%s", strings.Repeat(" ", len(gutter)), pad.String(), strings.Repeat("^", width)))
+	return sb.String()
+}
@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"strings"
+)
+
+// Fingerprint returns a stable hash of the shape of stmt, so that queries
+// differing only in their constants, as in a slow-query log, can be grouped
+// together. It hashes the String form of a copy of stmt normalized as follows:
+//   - numbers, strings, binary values and booleans, including a leading sign,
+//     become a placeholder; NULL is kept
+//   - named @parameters and ? placeholders become the same placeholder
+//   - an IN list made only of such values, or of rows of them, collapses to
+//     its first entry, so IN (1, 2) and IN (1, 2, 3) match
+//   - the row counts of TOP, LIMIT and OFFSET ... FETCH are dropped
+//
+// Operators are upper-cased, as in Equal, and whitespace, comments and the
+// case of other keywords never reach the String form. Identifiers and function
+// names are taken as written and, like data types and clause structure, change
+// the fingerprint. The result is 16 hexadecimal digits, or empty for a nil
+// statement.
+func Fingerprint(stmt Statement) string {
+	if stmt == nil {
+		return ""
+	}
+	normalized := stmt.Clone()
+	normalizeConstants(reflect.ValueOf(normalized))
+	sum := sha256.Sum256([]byte(normalized.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// fingerprintPlaceholder is the value of a normalized literal
+type fingerprintPlaceholder struct{}
+
+func (fingerprintPlaceholder) String() string { return "?" }
+
+// normalizeConstants rewrites the constants under v in place
+func normalizeConstants(v reflect.Value) {
+	if !v.CanInterface() {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if expr, ok := v.Interface().(Expression); ok && v.CanSet() {
+			if constant := signedConstant(expr); constant != nil {
+				v.Set(reflect.ValueOf(constant))
+			}
+		}
+		normalizeConstants(v.Elem())
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		switch n := v.Interface().(type) {
+		case *Literal:
+			if !n.IsNull() {
+				n.Value = fingerprintPlaceholder{}
+				n.Unicode = false
+			}
+			return
+		case *Parameter:
+			n.Name, n.Position = "", 0
+			return
+		case *InExpression:
+			if len(n.Values) > 1 && allConstants(n.Values) {
+				n.Values = n.Values[:1]
+			}
+		case *TopClause:
+			n.Count = 0
+		case *LimitClause:
+			n.Count, n.Offset = 0, 0
+		case *OffsetFetchClause:
+			n.Offset, n.Fetch = 0, 0
+		}
+		normalizeConstants(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if v.Type().Field(i).Name == "Operator" && field.Kind() == reflect.String && field.CanSet() {
+				field.SetString(strings.ToUpper(field.String()))
+				continue
+			}
+			normalizeConstants(field)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			normalizeConstants(v.Index(i))
+		}
+	}
+}
+
+// signedConstant returns the literal of a signed constant such as -5, or nil
+func signedConstant(expr Expression) *Literal {
+	unary, ok := expr.(*UnaryExpression)
+	if !ok || unary.Operator != "-" && unary.Operator != "+" {
+		return nil
+	}
+	if literal, ok := unary.Operand.(*Literal); ok && !literal.IsNull() {
+		return literal
+	}
+	return nil
+}
+
+// allConstants reports whether every value is a literal other than NULL, a
+// signed literal, a parameter or a row of those
+func allConstants(values []Expression) bool {
+	for _, value := range values {
+		switch v := value.(type) {
+		case *Literal:
+			if v.IsNull() {
+				return false
+			}
+		case *Parameter:
+		case *RowValueExpression:
+			if !allConstants(v.Values) {
+				return false
+			}
+		default:
+			if signedConstant(value) == nil {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,390 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// JSON serialization of the AST. Every node is encoded as an object whose
+// "type" field holds its Type() name, so Expression and Statement interface
+// fields can be decoded back into the right concrete node. The source span
+// is kept in the "pos" and "end" fields.
+
+// nodeTypes maps Type() names to constructors of the corresponding node
+var nodeTypes = map[string]func() Node{}
+
+func init() {
+	for _, newNode := range []func() Node{
+		func() Node { return &Program{} },
+		func() Node { return &SelectStatement{} },
+		func() Node { return &SetOperation{} },
+		func() Node { return &WithClause{} },
+		func() Node { return &CommonTableExpression{} },
+		func() Node { return &FromClause{} },
+		func() Node { return &TableReference{} },
+		func() Node { return &TableSample{} },
+		func() Node { return &ValuesClause{} },
+		func() Node { return &PivotClause{} },
+		func() Node { return &UnpivotClause{} },
+		func() Node { return &JoinClause{} },
+		func() Node { return &ColumnReference{} },
+		func() Node { return &Literal{} },
+		func() Node { return &Parameter{} },
+		func() Node { return &SystemVariable{} },
+		func() Node { return &DatePart{} },
+		func() Node { return &OrdinalReference{} },
+		func() Node { return &BinaryExpression{} },
+		func() Node { return &FunctionCall{} },
+		func() Node { return &WindowSpec{} },
+		func() Node { return &AliasedExpression{} },
+		func() Node { return &CastExpression{} },
+		func() Node { return &DataType{} },
+		func() Node { return &StarExpression{} },
+		func() Node { return &GroupByClause{} },
+		func() Node { return &RollupExpression{} },
+		func() Node { return &CubeExpression{} },
+		func() Node { return &GroupingSetsExpression{} },
+		func() Node { return &OrderByClause{} },
+		func() Node { return &TopClause{} },
+		func() Node { return &LimitClause{} },
+		func() Node { return &OffsetFetchClause{} },
+		func() Node { return &InsertStatement{} },
+		func() Node { return &UpdateStatement{} },
+		func() Node { return &Assignment{} },
+		func() Node { return &DeleteStatement{} },
+		func() Node { return &OutputClause{} },
+		func() Node { return &ForClause{} },
+		func() Node { return &ForOption{} },
+		func() Node { return &OptionClause{} },
+		func() Node { return &RowValueExpression{} },
+		func() Node { return &QueryHint{} },
+		func() Node { return &OptimizeForVariable{} },
+		func() Node { return &MergeStatement{} },
+		func() Node { return &MergeWhenClause{} },
+		func() Node { return &CreateTableStatement{} },
+		func() Node { return &ColumnDefinition{} },
+		func() Node { return &IdentitySpec{} },
+		func() Node { return &TableConstraint{} },
+		func() Node { return &AlterTableStatement{} },
+		func() Node { return &CreateViewStatement{} },
+		func() Node { return &CreateIndexStatement{} },
+		func() Node { return &IndexColumn{} },
+		func() Node { return &DropStatement{} },
+		func() Node { return &PermissionStatement{} },
+		func() Node { return &UnaryExpression{} },
+		func() Node { return &InExpression{} },
+		func() Node { return &ExistsExpression{} },
+		func() Node { return &SubqueryExpression{} },
+		func() Node { return &BetweenExpression{} },
+		func() Node { return &LikeExpression{} },
+		func() Node { return &CurrentOfClause{} },
+		func() Node { return &CollateExpression{} },
+		func() Node { return &FullTextPredicate{} },
+		func() Node { return &IsNullExpression{} },
+		func() Node { return &CaseExpression{} },
+		func() Node { return &WhenClause{} },
+	} {
+		nodeTypes[newNode().Type()] = newNode
+	}
+}
+
+var nodeInterface = reflect.TypeOf((*Node)(nil)).Elem()
+
+// ToJSON encodes a statement, preserving node types via a "type" field
+func ToJSON(stmt Statement) ([]byte, error) {
+	return MarshalNode(stmt)
+}
+
+// FromJSON decodes a statement produced by ToJSON
+func FromJSON(data []byte) (Statement, error) {
+	node, err := UnmarshalNode(data)
+	if err != nil {
+		return nil, err
+	}
+	stmt, ok := node.(Statement)
+	if !ok {
+		return nil, fmt.Errorf("expected statement, got %s", node.Type())
+	}
+	return stmt, nil
+}
+
+// MarshalNode encodes any AST node as JSON
+func MarshalNode(node Node) ([]byte, error) {
+	v, err := encodeNode(node)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalNode decodes any AST node encoded by MarshalNode
+func UnmarshalNode(data []byte) (Node, error) {
+	return decodeNode(json.RawMessage(data))
+}
+
+func encodeNode(node Node) (interface{}, error) {
+	if node == nil {
+		return nil, nil
+	}
+	rv := reflect.ValueOf(node)
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, nil
+	}
+
+	if lit, ok := node.(*Literal); ok {
+		return encodeLiteral(lit)
+	}
+
+	obj := map[string]interface{}{"type": node.Type()}
+	obj["pos"], obj["end"] = node.Span()
+	sv := rv.Elem()
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		if field.Anonymous || !field.IsExported() {
+			continue
+		}
+		value, err := encodeValue(sv.Field(i))
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", node.Type(), field.Name, err)
+		}
+		obj[jsonFieldName(field.Name)] = value
+	}
+	return obj, nil
+}
+
+func encodeValue(v reflect.Value) (interface{}, error) {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil, nil
+		}
+		node, ok := v.Interface().(Node)
+		if !ok {
+			return nil, fmt.Errorf("unsupported value of type %s", v.Type())
+		}
+		return encodeNode(node)
+	case reflect.Struct:
+		node, ok := v.Addr().Interface().(Node)
+		if !ok {
+			return nil, fmt.Errorf("unsupported value of type %s", v.Type())
+		}
+		return encodeNode(node)
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, err := encodeValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return v.Interface(), nil
+	}
+}
+
+// encodeLiteral records the Go type of the value so integers, floats,
+// strings, booleans, binary values and NULL round-trip exactly
+func encodeLiteral(lit *Literal) (interface{}, error) {
+	obj := map[string]interface{}{"type": lit.Type(), "value": lit.Value}
+	obj["pos"], obj["end"] = lit.Span()
+	switch lit.Value.(type) {
+	case nil, NullValue:
+		obj["value"] = nil
+		obj["value_type"] = "null"
+	case int64:
+		obj["value_type"] = "int"
+	case float64:
+		obj["value_type"] = "float"
+	case string:
+		obj["value_type"] = "string"
+		if lit.Unicode {
+			obj["unicode"] = true
+		}
+	case bool:
+		obj["value_type"] = "bool"
+	case []byte:
+		obj["value_type"] = "binary"
+	default:
+		return nil, fmt.Errorf("unsupported literal value of type %T", lit.Value)
+	}
+	return obj, nil
+}
+
+func decodeNode(data json.RawMessage) (Node, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, nil
+	}
+
+	var typeName string
+	if err := json.Unmarshal(fields["type"], &typeName); err != nil {
+		return nil, fmt.Errorf("missing node type: %w", err)
+	}
+	newNode, ok := nodeTypes[typeName]
+	if !ok {
+		return nil, fmt.Errorf("unknown node type: %s", typeName)
+	}
+	node := newNode()
+	if err := decodeSpan(node, fields); err != nil {
+		return nil, fmt.Errorf("%s: %w", typeName, err)
+	}
+
+	if lit, ok := node.(*Literal); ok {
+		return lit, decodeLiteral(lit, fields)
+	}
+
+	sv := reflect.ValueOf(node).Elem()
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		if field.Anonymous || !field.IsExported() {
+			continue
+		}
+		raw, ok := fields[jsonFieldName(field.Name)]
+		if !ok {
+			continue
+		}
+		if err := decodeValue(raw, sv.Field(i)); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", typeName, field.Name, err)
+		}
+	}
+	return node, nil
+}
+
+func decodeValue(raw json.RawMessage, v reflect.Value) error {
+	if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.Kind() == reflect.Ptr && !v.Type().Implements(nodeInterface) {
+			return json.Unmarshal(raw, v.Addr().Interface())
+		}
+		node, err := decodeNode(raw)
+		if err != nil {
+			return err
+		}
+		nv := reflect.ValueOf(node)
+		if !nv.Type().AssignableTo(v.Type()) {
+			return fmt.Errorf("cannot use %s as %s", node.Type(), v.Type())
+		}
+		v.Set(nv)
+		return nil
+	case reflect.Struct:
+		if !reflect.PointerTo(v.Type()).Implements(nodeInterface) {
+			return json.Unmarshal(raw, v.Addr().Interface())
+		}
+		node, err := decodeNode(raw)
+		if err != nil {
+			return err
+		}
+		nv := reflect.ValueOf(node)
+		if nv.Type() != reflect.PointerTo(v.Type()) {
+			return fmt.Errorf("cannot use %s as %s", node.Type(), v.Type())
+		}
+		v.Set(nv.Elem())
+		return nil
+	case reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeValue(item, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	default:
+		return json.Unmarshal(raw, v.Addr().Interface())
+	}
+}
+
+func decodeSpan(node Node, fields map[string]json.RawMessage) error {
+	var start, end Position
+	if raw, ok := fields["pos"]; ok {
+		if err := json.Unmarshal(raw, &start); err != nil {
+			return err
+		}
+	}
+	if raw, ok := fields["end"]; ok {
+		if err := json.Unmarshal(raw, &end); err != nil {
+			return err
+		}
+	}
+	if n, ok := node.(spanned); ok {
+		n.setSpan(start, end)
+	}
+	return nil
+}
+
+func decodeLiteral(lit *Literal, fields map[string]json.RawMessage) error {
+	var valueType string
+	if err := json.Unmarshal(fields["value_type"], &valueType); err != nil {
+		return fmt.Errorf("missing literal value type: %w", err)
+	}
+
+	raw := fields["value"]
+	var err error
+	switch valueType {
+	case "null":
+		lit.Value = Null
+	case "int":
+		var v int64
+		err = json.Unmarshal(raw, &v)
+		lit.Value = v
+	case "float":
+		var v float64
+		err = json.Unmarshal(raw, &v)
+		lit.Value = v
+	case "string":
+		var v string
+		err = json.Unmarshal(raw, &v)
+		lit.Value = v
+		if flag, ok := fields["unicode"]; ok && err == nil {
+			err = json.Unmarshal(flag, &lit.Unicode)
+		}
+	case "bool":
+		var v bool
+		err = json.Unmarshal(raw, &v)
+		lit.Value = v
+	case "binary":
+		var v []byte
+		err = json.Unmarshal(raw, &v)
+		lit.Value = v
+	default:
+		return fmt.Errorf("unknown literal value type: %s", valueType)
+	}
+	return err
+}
+
+// jsonFieldName converts a Go field name to snake_case, e.g. JoinType -> join_type
+func jsonFieldName(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
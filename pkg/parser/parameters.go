@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// ExtractParameters returns the bind parameters of stmt in order of
+// appearance: each ? placeholder, and each named @parameter the first time it
+// occurs. Names are compared case-insensitively, as SQL Server does. The
+// result tells a driver how many arguments to bind and in what order.
+func ExtractParameters(stmt Statement) []Parameter {
+	if stmt == nil {
+		return nil
+	}
+
+	var all []*Parameter
+	Inspect(stmt, func(node Node) bool {
+		if param, ok := node.(*Parameter); ok {
+			all = append(all, param)
+		}
+		return true
+	})
+
+	// Walk visits clauses in grammar order, which is not always the order
+	// they were written in; nodes built by hand keep the walk order
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Pos.Offset < all[j].Pos.Offset
+	})
+
+	var params []Parameter
+	seen := make(map[string]bool)
+	for _, param := range all {
+		if param.Name != "" {
+			name := strings.ToUpper(param.Name)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+		}
+		params = append(params, *param)
+	}
+	return params
+}
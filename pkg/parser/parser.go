@@ -3,7 +3,10 @@ package parser
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -12,14 +15,36 @@ import (
 	"github.com/Chahine-tech/sql-parser-go/pkg/lexer"
 )
 
+// DefaultMaxDepth is the nesting depth of subqueries and parenthesized
+// expressions a parser accepts unless SetMaxDepth says otherwise
+const DefaultMaxDepth = 1000
+
 type Parser struct {
 	l *lexer.Lexer
 
+	prevToken lexer.Token // last consumed token, marks the end of a node
 	curToken  lexer.Token
 	peekToken lexer.Token
 
 	errors []string
 
+	// Number of positional ? placeholders seen so far
+	placeholderCount int
+
+	// Set once the context ends; parsing stops at the next token
+	cancelled *CancelledError
+
+	// Recover from an error in one clause and go on with the next one,
+	// collecting the errors of the current statement in recovered
+	collectErrors bool
+	recovered     []error
+
+	// Current and maximum nesting depth; tooDeep is set once the maximum is
+	// exceeded and ends the statement
+	depth    int
+	maxDepth int
+	tooDeep  error
+
 	parseStartTime time.Time
 	tokenCount     int
 
@@ -27,6 +52,15 @@ type Parser struct {
 	dialect dialect.Dialect
 }
 
+// ParseWithTimeout parses a single statement, giving up with a *CancelledError
+// if parsing takes longer than d
+func ParseWithTimeout(input string, d time.Duration) (Statement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	return NewWithContext(ctx, input).ParseStatement()
+}
+
 func New(input string) *Parser {
 	return NewWithContext(context.Background(), input)
 }
@@ -36,13 +70,26 @@ func NewWithContext(ctx context.Context, input string) *Parser {
 }
 
 func NewWithDialect(ctx context.Context, input string, d dialect.Dialect) *Parser {
-	l := lexer.NewWithDialect(input, d)
+	return NewWithOptions(ctx, input, lexer.LexerOptions{Dialect: d})
+}
+
+// NewWithOptions creates a parser whose lexer is configured by opts, e.g.
+// to accept "..." as quoted identifiers. The dialect is opts.Dialect, SQL
+// Server by default. Comments are always skipped, so PreserveComments is
+// ignored.
+func NewWithOptions(ctx context.Context, input string, opts lexer.LexerOptions) *Parser {
+	if opts.Dialect == nil {
+		opts.Dialect = dialect.GetDialect("sqlserver")
+	}
+	opts.PreserveComments = false
+
 	p := &Parser{
-		l:              l,
+		l:              lexer.NewWithOptions(input, opts),
 		errors:         make([]string, 0, 4),
 		parseStartTime: time.Now(),
 		ctx:            ctx,
-		dialect:        d,
+		dialect:        opts.Dialect,
+		maxDepth:       DefaultMaxDepth,
 	}
 
 	p.nextToken()
@@ -52,15 +99,102 @@ func NewWithDialect(ctx context.Context, input string, d dialect.Dialect) *Parse
 }
 
 func (p *Parser) nextToken() {
-	select {
-	case <-p.ctx.Done():
-		p.errors = append(p.errors, "parsing cancelled due to timeout")
+	if p.cancelled != nil {
 		return
-	default:
-		p.curToken = p.peekToken
-		p.peekToken = p.l.NextToken()
-		p.tokenCount++
 	}
+	if err := p.ctx.Err(); err != nil {
+		p.cancel(err)
+		return
+	}
+
+	p.prevToken = p.curToken
+	p.curToken = p.peekToken
+	p.peekToken = p.l.NextToken()
+	p.tokenCount++
+}
+
+// cancel records that the context ended and moves to EOF, so that every
+// parsing loop terminates without consuming further input
+func (p *Parser) cancel(err error) {
+	p.cancelled = &CancelledError{Err: err}
+	p.errors = append(p.errors, p.cancelled.Error())
+	p.curToken = lexer.Token{Type: lexer.EOF}
+	p.peekToken = p.curToken
+}
+
+// pos returns the start position of the current token
+func (p *Parser) pos() Position {
+	return Position{Line: p.curToken.Line, Column: p.curToken.Column, Offset: p.curToken.Position}
+}
+
+// spanned is implemented by every node through BaseNode
+type spanned interface {
+	setSpan(start, end Position)
+}
+
+// finish records the node's span, from start to the end of the last consumed token
+func (p *Parser) finish(node Node, start Position) {
+	if n, ok := node.(spanned); ok {
+		end := Position{Line: p.prevToken.EndLine, Column: p.prevToken.EndColumn, Offset: p.prevToken.EndPosition}
+		n.setSpan(start, end)
+	}
+}
+
+// Reset prepares the parser to parse input, so that a single *Parser can be
+// reused across queries. The lexer and error buffers are reused, so slices
+// returned by Errors before the reset must not be retained. The parser keeps
+// its context and dialect.
+func (p *Parser) Reset(input string) {
+	p.l.Reset(input)
+	p.prevToken = lexer.Token{}
+	p.curToken = lexer.Token{}
+	p.peekToken = lexer.Token{}
+	p.errors = p.errors[:0]
+	p.placeholderCount = 0
+	p.cancelled = nil
+	p.recovered = nil
+	p.parseStartTime = time.Now()
+	p.tokenCount = 0
+
+	p.nextToken()
+	p.nextToken()
+}
+
+// SetCollectErrors makes the parser report every error in a statement instead
+// of stopping at the first one, as IDE linters do. After an error in a clause
+// of a SELECT, the parser skips to the next clause keyword (FROM, WHERE,
+// GROUP BY, ...) and carries on. The errors of a statement are then returned
+// joined together, each a *ParseError, along with what could be parsed of it.
+func (p *Parser) SetCollectErrors(on bool) {
+	p.collectErrors = on
+}
+
+// SetMaxDepth limits how deeply subqueries and parenthesized expressions may
+// nest, so that adversarial input fails with an error rather than exhausting
+// the stack. A depth of 0 or less restores DefaultMaxDepth.
+func (p *Parser) SetMaxDepth(depth int) {
+	if depth <= 0 {
+		depth = DefaultMaxDepth
+	}
+	p.maxDepth = depth
+}
+
+// descend enters a nested subquery or expression, failing once the maximum
+// depth is reached. Every successful call is paired with a deferred ascend.
+func (p *Parser) descend() error {
+	if p.tooDeep != nil {
+		return p.tooDeep
+	}
+	if p.depth >= p.maxDepth {
+		p.tooDeep = fmt.Errorf("maximum nesting depth exceeded (%d levels)", p.maxDepth)
+		return p.tooDeep
+	}
+	p.depth++
+	return nil
+}
+
+func (p *Parser) ascend() {
+	p.depth--
 }
 
 // GetDialect returns the dialect used by this parser
@@ -73,10 +207,29 @@ func (p *Parser) SetDialect(d dialect.Dialect) {
 	p.dialect = d
 }
 
+// Errors returns the lexical and syntax errors encountered so far
 func (p *Parser) Errors() []string {
+	if lexErrors := p.l.Errors(); len(lexErrors) > 0 {
+		return append(append([]string{}, lexErrors...), p.errors...)
+	}
 	return p.errors
 }
 
+// lexError describes the current token if the lexer rejected it, e.g. an
+// unterminated string, with the position where the token starts
+func (p *Parser) lexError() *ParseError {
+	if !p.curTokenIs(lexer.ILLEGAL) {
+		return nil
+	}
+	suffix := fmt.Sprintf(" at line %d, column %d", p.curToken.Line, p.curToken.Column)
+	for _, msg := range p.l.Errors() {
+		if strings.HasSuffix(msg, suffix) {
+			return NewParseError(strings.TrimSuffix(msg, suffix), p.curToken.Literal, p.curToken.Line, p.curToken.Column)
+		}
+	}
+	return nil
+}
+
 func (p *Parser) peekError(t lexer.TokenType) {
 	syntaxErr := NewSyntaxError(
 		t.String(),
@@ -114,23 +267,483 @@ func (p *Parser) GetParseMetrics() map[string]interface{} {
 	}
 }
 
-func (p *Parser) ParseStatement() (Statement, error) {
+// ParseStatement parses a single statement. Syntax errors are *ParseErrors
+// giving the position where parsing stopped. If the parser's context ends
+// first, the error is a *CancelledError. Any panic raised while parsing is
+// recovered and returned as a *ParseError, so untrusted input is safe to parse.
+func (p *Parser) ParseStatement() (stmt Statement, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stmt, err = nil, p.panicError(r)
+		}
+	}()
+
+	// Placeholder ordinals are numbered per statement
+	p.placeholderCount = 0
+	p.recovered = nil
+	p.depth = 0
+	p.tooDeep = nil
+
+	stmt, err = p.parseStatement()
+	if p.cancelled != nil {
+		return nil, p.cancelled
+	}
+	if p.tooDeep != nil {
+		// Rather than the error as wrapped by every enclosing subquery
+		stmt, err = nil, p.tooDeep
+	} else if err != nil {
+		if lexErr := p.lexError(); lexErr != nil {
+			err = lexErr
+		} else if _, ok := err.(*ParseError); !ok {
+			// Report where parsing stopped, as ParseProgram does
+			err = NewParseError(err.Error(), p.curToken.Literal, p.curToken.Line, p.curToken.Column)
+		}
+	}
+	if len(p.recovered) > 0 {
+		errs := p.recovered
+		p.recovered = nil
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return stmt, errors.Join(errs...)
+	}
+	return stmt, err
+}
+
+func (p *Parser) parseStatement() (Statement, error) {
 	switch p.curToken.Type {
+	case lexer.WITH:
+		return p.parseWithStatement()
 	case lexer.SELECT:
-		return p.parseSelectStatement()
+		return p.parseQueryExpression()
 	case lexer.INSERT:
 		return p.parseInsertStatement()
 	case lexer.UPDATE:
 		return p.parseUpdateStatement()
 	case lexer.DELETE:
 		return p.parseDeleteStatement()
+	case lexer.CREATE:
+		return p.parseCreateStatement()
+	case lexer.DROP:
+		return p.parseDropStatement()
+	case lexer.ALTER:
+		return p.parseAlterTableStatement()
+	case lexer.MERGE:
+		return p.parseMergeStatement()
+	case lexer.GRANT, lexer.DENY, lexer.REVOKE:
+		return p.parsePermissionStatement()
 	default:
 		return nil, fmt.Errorf("unsupported statement type: %s", p.curToken.Literal)
 	}
 }
 
+// ParseProgram parses a sequence of statements separated by semicolons or GO
+// until EOF. A statement that fails to parse is skipped and parsing resumes at
+// the next statement; all collected errors are returned joined together. Like
+// ParseStatement, it never panics on malformed input.
+func (p *Parser) ParseProgram() (program *Program, err error) {
+	program = &Program{Statements: make([]Statement, 0, 4)}
+	var errs []error
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Join(append(errs, p.panicError(r))...)
+		}
+	}()
+
+	for {
+		stmt, err := p.nextStatement()
+		if err == io.EOF || p.cancelled != nil {
+			break
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		program.Statements = append(program.Statements, stmt)
+	}
+
+	if p.cancelled != nil {
+		errs = append(errs, p.cancelled)
+	}
+	return program, errors.Join(errs...)
+}
+
+// ParseBatches parses a SQL Server script, splitting its statements into
+// batches at each GO separator. Batches with no statements are omitted.
+// Errors are handled as in ParseProgram.
+func (p *Parser) ParseBatches() (batches []Batch, err error) {
+	var errs []error
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Join(append(errs, p.panicError(r))...)
+		}
+	}()
+
+	batch := Batch{Count: 1}
+	started := false
+	for {
+		for p.curTokenIs(lexer.SEMICOLON) {
+			p.nextToken()
+		}
+		if p.curTokenIs(lexer.GO) {
+			if started {
+				batch.Count = batchCount(p.curToken.Literal)
+				batches = append(batches, batch)
+			}
+			batch, started = Batch{Count: 1}, false
+			p.nextToken()
+			continue
+		}
+
+		stmt, err := p.nextStatement()
+		if err == io.EOF || p.cancelled != nil {
+			break
+		}
+		started = true
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		batch.Statements = append(batch.Statements, stmt)
+	}
+	if started {
+		batches = append(batches, batch)
+	}
+
+	if p.cancelled != nil {
+		errs = append(errs, p.cancelled)
+	}
+	return batches, errors.Join(errs...)
+}
+
+// batchCount returns the repeat count of a "GO n" separator, 1 if absent
+func batchCount(separator string) int {
+	fields := strings.Fields(separator)
+	if len(fields) == 2 {
+		if count, err := strconv.Atoi(fields[1]); err == nil && count > 0 {
+			return count
+		}
+	}
+	return 1
+}
+
+// StatementIterator parses statements one at a time, so that large scripts
+// can be processed without holding every statement in memory
+type StatementIterator struct {
+	p    *Parser
+	done bool
+}
+
+// Statements returns an iterator over the semicolon-separated statements of the input
+func (p *Parser) Statements() *StatementIterator {
+	return &StatementIterator{p: p}
+}
+
+// Next parses and returns the next statement. A statement that fails to parse
+// is reported as a *ParseError and the iterator resumes at the following
+// statement. Next returns io.EOF at the end of the input; if parsing is
+// cancelled, the *CancelledError is returned once and io.EOF after it.
+func (it *StatementIterator) Next() (stmt Statement, err error) {
+	if it.done {
+		return nil, io.EOF
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			it.done = true
+			stmt, err = nil, it.p.panicError(r)
+		}
+	}()
+
+	stmt, err = it.p.nextStatement()
+	if err == io.EOF || it.p.cancelled != nil {
+		it.done = true
+	}
+	return stmt, err
+}
+
+// nextStatement parses the next statement, recovering from a failed one by
+// skipping to the following semicolon. It returns io.EOF at the end of input.
+func (p *Parser) nextStatement() (Statement, error) {
+	for p.curTokenIs(lexer.SEMICOLON) || p.curTokenIs(lexer.GO) {
+		p.nextToken()
+	}
+	if p.cancelled != nil {
+		return nil, p.cancelled
+	}
+	if p.curTokenIs(lexer.EOF) {
+		return nil, io.EOF
+	}
+	if err := p.ctx.Err(); err != nil {
+		p.cancel(err)
+		return nil, p.cancelled
+	}
+
+	stmt, err := p.ParseStatement()
+	if p.cancelled != nil {
+		return nil, p.cancelled
+	}
+	if err == nil && !p.curTokenIs(lexer.SEMICOLON) && !p.curTokenIs(lexer.GO) && !p.curTokenIs(lexer.EOF) {
+		err = fmt.Errorf("expected ; or end of input, got %s", p.curToken.Literal)
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		// Errors collected within the statement are already *ParseErrors
+		for _, e := range joined.Unwrap() {
+			p.errors = append(p.errors, e.Error())
+		}
+		p.synchronize()
+		return nil, err
+	}
+	if err != nil {
+		parseErr, ok := err.(*ParseError)
+		if !ok {
+			parseErr = NewParseError(err.Error(), p.curToken.Literal, p.curToken.Line, p.curToken.Column)
+		}
+		p.errors = append(p.errors, parseErr.Error())
+		p.synchronize()
+		return nil, parseErr
+	}
+	return stmt, nil
+}
+
+// panicError converts a recovered panic into a *ParseError at the current
+// token, so that malformed input never crashes the caller
+func (p *Parser) panicError(r interface{}) error {
+	parseErr := NewParseError(fmt.Sprintf("internal parser error: %v", r), p.curToken.Literal, p.curToken.Line, p.curToken.Column)
+	p.errors = append(p.errors, parseErr.Error())
+	return parseErr
+}
+
+// synchronize skips tokens up to the end of the current statement
+func (p *Parser) synchronize() {
+	for !p.curTokenIs(lexer.SEMICOLON) && !p.curTokenIs(lexer.GO) && !p.curTokenIs(lexer.EOF) {
+		p.nextToken()
+	}
+}
+
+// recoverClause records err and skips to the next clause when collecting
+// errors. It reports whether parsing can go on.
+func (p *Parser) recoverClause(err error) bool {
+	if !p.collectErrors || p.cancelled != nil || p.tooDeep != nil {
+		return false
+	}
+	p.recovered = append(p.recovered, NewParseError(err.Error(), p.curToken.Literal, p.curToken.Line, p.curToken.Column))
+	p.synchronizeClause()
+	return true
+}
+
+// synchronizeClause skips tokens up to the next clause of the current query,
+// the parenthesis closing an enclosing subquery, or the end of the statement
+func (p *Parser) synchronizeClause() {
+	depth := 0
+	for !p.curTokenIs(lexer.SEMICOLON) && !p.curTokenIs(lexer.GO) && !p.curTokenIs(lexer.EOF) {
+		switch p.curToken.Type {
+		case lexer.LPAREN:
+			depth++
+		case lexer.RPAREN:
+			if depth == 0 {
+				return
+			}
+			depth--
+		case lexer.FROM, lexer.WHERE, lexer.GROUP, lexer.HAVING, lexer.ORDER, lexer.OFFSET, lexer.LIMIT,
+			lexer.UNION, lexer.EXCEPT, lexer.INTERSECT:
+			if depth == 0 {
+				return
+			}
+		default:
+			if depth == 0 && p.curTokenIsJoin() {
+				return
+			}
+		}
+		p.nextToken()
+	}
+}
+
+// parseWithStatement parses a WITH clause followed by the statement it applies to
+func (p *Parser) parseWithStatement() (Statement, error) {
+	start := p.pos()
+	with, err := p.parseWithClause()
+	if err != nil {
+		return nil, err
+	}
+
+	switch p.curToken.Type {
+	case lexer.SELECT:
+		stmt, err := p.parseQueryExpression()
+		if err != nil {
+			return nil, err
+		}
+		switch s := stmt.(type) {
+		case *SelectStatement:
+			s.With = with
+			p.finish(s, start)
+		case *SetOperation:
+			s.With = with
+			p.finish(s, start)
+		}
+		return stmt, nil
+	case lexer.INSERT:
+		stmt, err := p.parseInsertStatement()
+		if err != nil {
+			return nil, err
+		}
+		stmt.With = with
+		p.finish(stmt, start)
+		return stmt, nil
+	case lexer.UPDATE:
+		stmt, err := p.parseUpdateStatement()
+		if err != nil {
+			return nil, err
+		}
+		stmt.With = with
+		p.finish(stmt, start)
+		return stmt, nil
+	case lexer.DELETE:
+		stmt, err := p.parseDeleteStatement()
+		if err != nil {
+			return nil, err
+		}
+		stmt.With = with
+		p.finish(stmt, start)
+		return stmt, nil
+	case lexer.MERGE:
+		stmt, err := p.parseMergeStatement()
+		if err != nil {
+			return nil, err
+		}
+		stmt.With = with
+		p.finish(stmt, start)
+		return stmt, nil
+	default:
+		return nil, fmt.Errorf("expected SELECT, INSERT, UPDATE, DELETE or MERGE after WITH clause, got %s", p.curToken.Literal)
+	}
+}
+
+func (p *Parser) parseWithClause() (*WithClause, error) {
+	if !p.curTokenIs(lexer.WITH) {
+		return nil, fmt.Errorf("expected WITH, got %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+	p.nextToken()
+
+	with := &WithClause{}
+
+	if p.curIdentIs("RECURSIVE") {
+		with.Recursive = true
+		p.nextToken()
+	}
+
+	for {
+		cte, err := p.parseCommonTableExpression()
+		if err != nil {
+			return nil, err
+		}
+		with.CTEs = append(with.CTEs, cte)
+
+		if !p.curTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	p.finish(with, start)
+	return with, nil
+}
+
+func (p *Parser) parseCommonTableExpression() (*CommonTableExpression, error) {
+	if !p.curTokenIs(lexer.IDENT) {
+		return nil, fmt.Errorf("expected CTE name, got %s", p.curToken.Literal)
+	}
+
+	cte := &CommonTableExpression{Name: p.curToken.Literal}
+	start := p.pos()
+	p.nextToken()
+
+	if p.curTokenIs(lexer.LPAREN) {
+		columns, err := p.parseColumnList()
+		if err != nil {
+			return nil, err
+		}
+		cte.Columns = columns
+	}
+
+	if !p.curTokenIs(lexer.AS) {
+		return nil, fmt.Errorf("expected AS after CTE name %s, got %s", cte.Name, p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	if !p.curTokenIs(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' to open CTE %s, got %s", cte.Name, p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	// CTE bodies may combine SELECTs, e.g. the UNION ALL of a recursive CTE
+	query, err := p.parseQueryExpression()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CTE %s: %v", cte.Name, err)
+	}
+	cte.Query = query
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' to close CTE %s, got %s", cte.Name, p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	p.finish(cte, start)
+	return cte, nil
+}
+
+// parseQueryExpression parses a SELECT optionally combined with further SELECTs
+// through UNION [ALL], EXCEPT or INTERSECT. Chained operators associate left.
+func (p *Parser) parseQueryExpression() (Statement, error) {
+	start := p.pos()
+	selectStmt, err := p.parseSelectStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	var left Statement = selectStmt
+
+	for p.curTokenIsSetOperator() {
+		operator := strings.ToUpper(p.curToken.Literal)
+		p.nextToken()
+
+		if operator == "UNION" && p.curTokenIs(lexer.ALL) {
+			operator = "UNION ALL"
+			p.nextToken()
+		}
+
+		right, err := p.parseSelectStatement()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse right side of %s: %v", operator, err)
+		}
+
+		operation := &SetOperation{
+			Left:     left,
+			Operator: operator,
+			Right:    right,
+		}
+		p.finish(operation, start)
+		left = operation
+	}
+
+	return left, nil
+}
+
+func (p *Parser) curTokenIsSetOperator() bool {
+	return p.curTokenIs(lexer.UNION) || p.curTokenIs(lexer.EXCEPT) || p.curTokenIs(lexer.INTERSECT)
+}
+
 // Parse SELECT statement
 func (p *Parser) parseSelectStatement() (*SelectStatement, error) {
+	if err := p.descend(); err != nil {
+		return nil, err
+	}
+	defer p.ascend()
+
 	stmt := GetSelectStatement() // Use object pool
 
 	if !p.curTokenIs(lexer.SELECT) {
@@ -138,6 +751,7 @@ func (p *Parser) parseSelectStatement() (*SelectStatement, error) {
 		return nil, fmt.Errorf("expected SELECT, got %s", p.curToken.Literal)
 	}
 
+	start := p.pos()
 	p.nextToken()
 
 	if p.curTokenIs(lexer.DISTINCT) {
@@ -145,32 +759,52 @@ func (p *Parser) parseSelectStatement() (*SelectStatement, error) {
 		p.nextToken()
 	}
 
+	// When collecting errors, a failed clause is skipped and parsing goes on
+	// with the next one
 	if p.curTokenIs(lexer.TOP) {
 		topClause, err := p.parseTopClause()
-		if err != nil {
+		if err != nil && !p.recoverClause(err) {
 			return nil, err
 		}
 		stmt.Top = topClause
 	}
 
 	columns, err := p.parseSelectList()
-	if err != nil {
+	if err != nil && !p.recoverClause(err) {
 		return nil, err
 	}
 	stmt.Columns = columns
 
+	// SELECT ... INTO new_table creates a table from the query
+	if p.curTokenIs(lexer.INTO) {
+		p.nextToken()
+		into, err := p.parseTableName()
+		if err != nil && !p.recoverClause(err) {
+			return nil, err
+		}
+		stmt.Into = into
+	}
+
 	if p.curTokenIs(lexer.FROM) {
 		fromClause, err := p.parseFromClause()
-		if err != nil {
+		if err != nil && !p.recoverClause(err) {
 			return nil, err
 		}
 		stmt.From = fromClause
 	}
 
-	for p.curTokenIs(lexer.JOIN) || p.curTokenIs(lexer.INNER) || p.curTokenIs(lexer.LEFT) || p.curTokenIs(lexer.RIGHT) || p.curTokenIs(lexer.FULL) {
+	for p.curTokenIsJoin() {
+		joinStart := p.curToken.Position
 		joinClause, err := p.parseJoinClause()
 		if err != nil {
-			return nil, err
+			if !p.recoverClause(err) {
+				return nil, err
+			}
+			// Stop if recovery went back to the same join
+			if p.curToken.Position == joinStart {
+				break
+			}
+			continue
 		}
 		stmt.Joins = append(stmt.Joins, joinClause)
 	}
@@ -178,7 +812,7 @@ func (p *Parser) parseSelectStatement() (*SelectStatement, error) {
 	if p.curTokenIs(lexer.WHERE) {
 		p.nextToken()
 		whereExpr, err := p.parseExpression()
-		if err != nil {
+		if err != nil && !p.recoverClause(err) {
 			return nil, err
 		}
 		stmt.Where = whereExpr
@@ -186,7 +820,7 @@ func (p *Parser) parseSelectStatement() (*SelectStatement, error) {
 
 	if p.curTokenIs(lexer.GROUP) {
 		groupBy, err := p.parseGroupByClause()
-		if err != nil {
+		if err != nil && !p.recoverClause(err) {
 			return nil, err
 		}
 		stmt.GroupBy = groupBy
@@ -195,7 +829,7 @@ func (p *Parser) parseSelectStatement() (*SelectStatement, error) {
 	if p.curTokenIs(lexer.HAVING) {
 		p.nextToken()
 		havingExpr, err := p.parseExpression()
-		if err != nil {
+		if err != nil && !p.recoverClause(err) {
 			return nil, err
 		}
 		stmt.Having = havingExpr
@@ -203,21 +837,50 @@ func (p *Parser) parseSelectStatement() (*SelectStatement, error) {
 
 	if p.curTokenIs(lexer.ORDER) {
 		orderBy, err := p.parseOrderByClause()
-		if err != nil {
+		if err != nil && !p.recoverClause(err) {
 			return nil, err
 		}
+		for _, item := range orderBy {
+			item.Expression = asOrdinal(item.Expression)
+		}
 		stmt.OrderBy = orderBy
+
+		// SQL Server pagination: ORDER BY ... OFFSET n ROWS [FETCH NEXT m ROWS ONLY]
+		if p.curTokenIs(lexer.OFFSET) {
+			offset, err := p.parseOffsetFetchClause()
+			if err != nil && !p.recoverClause(err) {
+				return nil, err
+			}
+			stmt.Offset = offset
+		}
 	}
 
 	// Parse LIMIT clause
 	if p.curTokenIs(lexer.LIMIT) {
 		limit, err := p.parseLimitClause()
-		if err != nil {
+		if err != nil && !p.recoverClause(err) {
 			return nil, err
 		}
 		stmt.Limit = limit
 	}
 
+	if p.curTokenIsFor() {
+		forClause, err := p.parseForClause()
+		if err != nil && !p.recoverClause(err) {
+			return nil, err
+		}
+		stmt.For = forClause
+	}
+
+	if p.curTokenIsOption() {
+		option, err := p.parseOptionClause()
+		if err != nil && !p.recoverClause(err) {
+			return nil, err
+		}
+		stmt.Option = option
+	}
+
+	p.finish(stmt, start)
 	return stmt, nil
 }
 
@@ -226,8 +889,15 @@ func (p *Parser) parseTopClause() (*TopClause, error) {
 		return nil, fmt.Errorf("expected TOP, got %s", p.curToken.Literal)
 	}
 
+	start := p.pos()
 	p.nextToken()
 
+	// TOP (n) is required for DELETE/UPDATE and allowed for SELECT
+	parenthesized := p.curTokenIs(lexer.LPAREN)
+	if parenthesized {
+		p.nextToken()
+	}
+
 	if !p.curTokenIs(lexer.NUMBER) {
 		return nil, fmt.Errorf("expected number after TOP, got %s", p.curToken.Literal)
 	}
@@ -240,12 +910,20 @@ func (p *Parser) parseTopClause() (*TopClause, error) {
 	topClause := &TopClause{Count: count}
 	p.nextToken()
 
+	if parenthesized {
+		if !p.curTokenIs(lexer.RPAREN) {
+			return nil, fmt.Errorf("expected ')' after TOP count, got %s", p.curToken.Literal)
+		}
+		p.nextToken()
+	}
+
 	// Check for PERCENT
 	if p.curTokenIs(lexer.IDENT) && strings.ToUpper(p.curToken.Literal) == "PERCENT" {
 		topClause.Percent = true
 		p.nextToken()
 	}
 
+	p.finish(topClause, start)
 	return topClause, nil
 }
 
@@ -253,12 +931,11 @@ func (p *Parser) parseSelectList() ([]Expression, error) {
 	var columns []Expression
 
 	if p.curTokenIs(lexer.ASTERISK) {
-		columns = append(columns, &StarExpression{})
-		p.nextToken()
+		columns = append(columns, p.parseStar())
 		return columns, nil
 	}
 
-	expr, err := p.parseExpression()
+	expr, err := p.parseSelectItem()
 	if err != nil {
 		return nil, err
 	}
@@ -268,10 +945,9 @@ func (p *Parser) parseSelectList() ([]Expression, error) {
 		p.nextToken()
 
 		if p.curTokenIs(lexer.ASTERISK) {
-			columns = append(columns, &StarExpression{})
-			p.nextToken()
+			columns = append(columns, p.parseStar())
 		} else {
-			expr, err := p.parseExpression()
+			expr, err := p.parseSelectItem()
 			if err != nil {
 				return nil, err
 			}
@@ -282,11 +958,53 @@ func (p *Parser) parseSelectList() ([]Expression, error) {
 	return columns, nil
 }
 
+// parseStar parses a bare * in a select list
+func (p *Parser) parseStar() *StarExpression {
+	star := &StarExpression{}
+	start := p.pos()
+	p.nextToken()
+	p.finish(star, start)
+	return star
+}
+
+// parseSelectItem parses a select list expression with an optional alias
+func (p *Parser) parseSelectItem() (Expression, error) {
+	start := p.pos()
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.curTokenIs(lexer.AS) {
+		p.nextToken()
+		if !p.curTokenIs(lexer.IDENT) && !p.curTokenIs(lexer.STRING) {
+			return nil, fmt.Errorf("expected alias after AS, got %s", p.curToken.Literal)
+		}
+		alias := p.curToken.Literal
+		p.nextToken()
+		aliased := &AliasedExpression{Expression: expr, Alias: alias}
+		p.finish(aliased, start)
+		return aliased, nil
+	}
+
+	if p.curTokenIs(lexer.IDENT) && !p.curTokenIsOption() && !p.curTokenIsFor() {
+		// Implicit alias (no AS keyword)
+		alias := p.curToken.Literal
+		p.nextToken()
+		aliased := &AliasedExpression{Expression: expr, Alias: alias}
+		p.finish(aliased, start)
+		return aliased, nil
+	}
+
+	return expr, nil
+}
+
 func (p *Parser) parseFromClause() (*FromClause, error) {
 	if !p.curTokenIs(lexer.FROM) {
 		return nil, fmt.Errorf("expected FROM, got %s", p.curToken.Literal)
 	}
 
+	start := p.pos()
 	p.nextToken()
 
 	fromClause := &FromClause{}
@@ -306,499 +1024,3714 @@ func (p *Parser) parseFromClause() (*FromClause, error) {
 		fromClause.Tables = append(fromClause.Tables, *table)
 	}
 
+	p.finish(fromClause, start)
 	return fromClause, nil
 }
 
 func (p *Parser) parseTableReference() (*TableReference, error) {
-	if !p.curTokenIs(lexer.IDENT) {
-		return nil, fmt.Errorf("expected table name, got %s", p.curToken.Literal)
+	start := p.pos()
+
+	// Derived table: (SELECT ...) AS alias
+	if p.curTokenIs(lexer.LPAREN) && p.peekTokenIs(lexer.SELECT) {
+		return p.parseDerivedTable()
 	}
 
-	table := &TableReference{}
+	// Table value constructor: (VALUES (...), ...) AS alias(columns)
+	if p.curTokenIs(lexer.LPAREN) && p.peekTokenIs(lexer.VALUES) {
+		return p.parseValuesTable()
+	}
 
-	firstIdent := p.curToken.Literal
-	p.nextToken()
+	table, err := p.parseTableName()
+	if err != nil {
+		return nil, err
+	}
 
-	if p.curTokenIs(lexer.DOT) {
-		p.nextToken()
-		if !p.curTokenIs(lexer.IDENT) {
-			return nil, fmt.Errorf("expected table name after dot, got %s", p.curToken.Literal)
+	// Table-valued function, e.g. dbo.SplitString(x)
+	if p.curTokenIs(lexer.LPAREN) {
+		function, err := p.parseFunctionCall(table.QualifiedName())
+		if err != nil {
+			return nil, err
 		}
-		table.Schema = firstIdent
-		table.Name = p.curToken.Literal
-		p.nextToken()
-	} else {
-		table.Name = firstIdent
+		table.Function = function.(*FunctionCall)
+		p.finish(table.Function, start)
 	}
 
-	if p.curTokenIs(lexer.AS) {
-		p.nextToken()
-		if !p.curTokenIs(lexer.IDENT) {
-			return nil, fmt.Errorf("expected alias after AS, got %s", p.curToken.Literal)
+	if err := p.parseTableAlias(table); err != nil {
+		return nil, err
+	}
+
+	if p.curIdentIs("TABLESAMPLE") {
+		sample, err := p.parseTableSample()
+		if err != nil {
+			return nil, err
 		}
-		table.Alias = p.curToken.Literal
-		p.nextToken()
-	} else if p.curTokenIs(lexer.IDENT) {
-		// Implicit alias (no AS keyword)
-		table.Alias = p.curToken.Literal
-		p.nextToken()
+		table.TableSample = sample
+	}
+
+	// SQL Server table hints, e.g. WITH (NOLOCK)
+	if p.curTokenIs(lexer.WITH) && p.peekTokenIs(lexer.LPAREN) {
+		hints, err := p.parseTableHints()
+		if err != nil {
+			return nil, err
+		}
+		table.Hints = hints
+	}
+
+	if err := p.parsePivotOperator(table); err != nil {
+		return nil, err
 	}
 
+	p.finish(table, start)
 	return table, nil
 }
 
-func (p *Parser) parseJoinClause() (*JoinClause, error) {
-	joinClause := GetJoinClause()
-
-	if p.curTokenIs(lexer.INNER) {
-		joinClause.JoinType = "INNER"
-		p.nextToken()
-		if !p.expectPeek(lexer.JOIN) {
-			PutJoinClause(joinClause)
-			return nil, fmt.Errorf("expected JOIN after INNER")
-		}
-	} else if p.curTokenIs(lexer.LEFT) {
-		joinClause.JoinType = "LEFT"
-		p.nextToken()
-		if !p.expectPeek(lexer.JOIN) {
-			PutJoinClause(joinClause)
-			return nil, fmt.Errorf("expected JOIN after LEFT")
-		}
-	} else if p.curTokenIs(lexer.RIGHT) {
-		joinClause.JoinType = "RIGHT"
-		p.nextToken()
-		if !p.expectPeek(lexer.JOIN) {
-			PutJoinClause(joinClause)
-			return nil, fmt.Errorf("expected JOIN after RIGHT")
+// parsePivotOperator parses an optional PIVOT or UNPIVOT applied to table
+func (p *Parser) parsePivotOperator(table *TableReference) error {
+	switch {
+	case p.curIdentIs("PIVOT"):
+		pivot, err := p.parsePivotClause()
+		if err != nil {
+			return err
 		}
-	} else if p.curTokenIs(lexer.FULL) {
-		joinClause.JoinType = "FULL"
-		p.nextToken()
-		if !p.expectPeek(lexer.JOIN) {
-			PutJoinClause(joinClause)
-			return nil, fmt.Errorf("expected JOIN after FULL")
+		table.Pivot = pivot
+	case p.curIdentIs("UNPIVOT"):
+		unpivot, err := p.parseUnpivotClause()
+		if err != nil {
+			return err
 		}
-	} else if p.curTokenIs(lexer.JOIN) {
-		joinClause.JoinType = "INNER"
-		p.nextToken()
+		table.Unpivot = unpivot
 	}
+	return nil
+}
 
-	table, err := p.parseTableReference()
-	if err != nil {
-		PutJoinClause(joinClause)
-		return nil, err
+// Parse PIVOT (aggregate(column) FOR column IN ([value], ...)) [AS] alias
+func (p *Parser) parsePivotClause() (*PivotClause, error) {
+	start := p.pos()
+	if !p.expectPeek(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' after PIVOT, got %s", p.peekToken.Literal)
 	}
-	joinClause.Table = *table
+	p.nextToken()
 
-	// Parse ON condition
-	if !p.curTokenIs(lexer.ON) {
-		PutJoinClause(joinClause) // Return to pool on error
-		return nil, fmt.Errorf("expected ON after JOIN table, got %s", p.curToken.Literal)
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PIVOT aggregate: %v", err)
+	}
+	aggregate, ok := expr.(*FunctionCall)
+	if !ok {
+		return nil, fmt.Errorf("expected aggregate function in PIVOT, got %s", expr.String())
 	}
+	pivot := &PivotClause{Aggregate: aggregate}
 
+	if !p.curIdentIs("FOR") {
+		return nil, fmt.Errorf("expected FOR in PIVOT, got %s", p.curToken.Literal)
+	}
 	p.nextToken()
-	condition, err := p.parseExpression()
+
+	column, err := p.parsePivotColumn()
 	if err != nil {
-		PutJoinClause(joinClause) // Return to pool on error
 		return nil, err
 	}
-	joinClause.Condition = condition
-
-	return joinClause, nil
-}
-
-func (p *Parser) parseGroupByClause() ([]Expression, error) {
-	if !p.curTokenIs(lexer.GROUP) {
-		return nil, fmt.Errorf("expected GROUP, got %s", p.curToken.Literal)
-	}
+	pivot.Column = column
 
-	p.nextToken()
-	if !p.expectPeek(lexer.BY) {
-		return nil, fmt.Errorf("expected BY after GROUP")
+	if !p.curTokenIs(lexer.IN) {
+		return nil, fmt.Errorf("expected IN after PIVOT column, got %s", p.curToken.Literal)
 	}
-
 	p.nextToken()
 
-	var expressions []Expression
-
-	// Parse first expression
-	expr, err := p.parseExpression()
+	values, err := p.parseColumnList()
 	if err != nil {
 		return nil, err
 	}
-	expressions = append(expressions, expr)
+	pivot.Values = values
 
-	// Parse additional expressions
-	for p.curTokenIs(lexer.COMMA) {
-		p.nextToken()
-		expr, err := p.parseExpression()
-		if err != nil {
-			return nil, err
-		}
-		expressions = append(expressions, expr)
+	alias, err := p.parsePivotAlias("PIVOT")
+	if err != nil {
+		return nil, err
 	}
+	pivot.Alias = alias
 
-	return expressions, nil
+	p.finish(pivot, start)
+	return pivot, nil
 }
 
-func (p *Parser) parseOrderByClause() ([]*OrderByClause, error) {
-	if !p.curTokenIs(lexer.ORDER) {
-		return nil, fmt.Errorf("expected ORDER, got %s", p.curToken.Literal)
+// Parse UNPIVOT (value_column FOR name_column IN (column, ...)) [AS] alias
+func (p *Parser) parseUnpivotClause() (*UnpivotClause, error) {
+	start := p.pos()
+	if !p.expectPeek(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' after UNPIVOT, got %s", p.peekToken.Literal)
 	}
-
-	p.nextToken()
-	if !p.expectPeek(lexer.BY) {
-		return nil, fmt.Errorf("expected BY after ORDER")
+	if !p.expectPeek(lexer.IDENT) {
+		return nil, fmt.Errorf("expected value column in UNPIVOT, got %s", p.peekToken.Literal)
 	}
+	unpivot := &UnpivotClause{ValueColumn: p.curToken.Literal}
+	p.nextToken()
 
+	if !p.curIdentIs("FOR") {
+		return nil, fmt.Errorf("expected FOR in UNPIVOT, got %s", p.curToken.Literal)
+	}
+	if !p.expectPeek(lexer.IDENT) {
+		return nil, fmt.Errorf("expected name column in UNPIVOT, got %s", p.peekToken.Literal)
+	}
+	unpivot.NameColumn = p.curToken.Literal
 	p.nextToken()
 
-	var clauses []*OrderByClause
+	if !p.curTokenIs(lexer.IN) {
+		return nil, fmt.Errorf("expected IN after UNPIVOT column, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
 
-	// Parse first clause
-	clause, err := p.parseOrderByItem()
+	columns, err := p.parseColumnList()
 	if err != nil {
 		return nil, err
 	}
-	clauses = append(clauses, clause)
+	unpivot.Columns = columns
 
-	// Parse additional clauses
-	for p.curTokenIs(lexer.COMMA) {
-		p.nextToken()
-		clause, err := p.parseOrderByItem()
-		if err != nil {
-			return nil, err
-		}
-		clauses = append(clauses, clause)
+	alias, err := p.parsePivotAlias("UNPIVOT")
+	if err != nil {
+		return nil, err
 	}
+	unpivot.Alias = alias
 
-	return clauses, nil
+	p.finish(unpivot, start)
+	return unpivot, nil
 }
 
-func (p *Parser) parseOrderByItem() (*OrderByClause, error) {
-	expr, err := p.parseExpression()
-	if err != nil {
-		return nil, err
+// parsePivotColumn parses the [table.]column after FOR in a PIVOT
+func (p *Parser) parsePivotColumn() (*ColumnReference, error) {
+	if !p.curTokenIs(lexer.IDENT) {
+		return nil, fmt.Errorf("expected column after FOR, got %s", p.curToken.Literal)
 	}
+	start := p.pos()
+	column := &ColumnReference{Column: p.curToken.Literal}
+	p.nextToken()
 
-	clause := &OrderByClause{
-		Expression: expr,
-		Direction:  "ASC", // Default
+	if p.curTokenIs(lexer.DOT) {
+		if !p.expectPeek(lexer.IDENT) {
+			return nil, fmt.Errorf("expected column name after dot, got %s", p.peekToken.Literal)
+		}
+		column.Table = column.Column
+		column.Column = p.curToken.Literal
+		p.nextToken()
 	}
 
-	// Check for ASC/DESC
-	if p.curTokenIs(lexer.IDENT) {
-		direction := strings.ToUpper(p.curToken.Literal)
-		if direction == "ASC" || direction == "DESC" {
-			clause.Direction = direction
-			p.nextToken()
-		}
+	p.finish(column, start)
+	return column, nil
+}
+
+// parsePivotAlias parses the closing ')' and the alias every PIVOT and
+// UNPIVOT requires
+func (p *Parser) parsePivotAlias(operator string) (string, error) {
+	if !p.curTokenIs(lexer.RPAREN) {
+		return "", fmt.Errorf("expected ')' to close %s, got %s", operator, p.curToken.Literal)
 	}
+	p.nextToken()
 
-	return clause, nil
+	if p.curTokenIs(lexer.AS) {
+		p.nextToken()
+	}
+	if !p.curTokenIs(lexer.IDENT) {
+		return "", fmt.Errorf("expected alias after %s, got %s", operator, p.curToken.Literal)
+	}
+	alias := p.curToken.Literal
+	p.nextToken()
+	return alias, nil
 }
 
-func (p *Parser) parseLimitClause() (*LimitClause, error) {
-	if !p.curTokenIs(lexer.LIMIT) {
-		return nil, fmt.Errorf("expected LIMIT, got %s", p.curToken.Literal)
+// Parse TABLESAMPLE [SYSTEM] (size [PERCENT | ROWS]) [REPEATABLE (seed)]
+func (p *Parser) parseTableSample() (*TableSample, error) {
+	if !p.curIdentIs("TABLESAMPLE") {
+		return nil, fmt.Errorf("expected TABLESAMPLE, got %s", p.curToken.Literal)
 	}
 
+	start := p.pos()
 	p.nextToken()
 
-	// Parse count
-	if !p.curTokenIs(lexer.NUMBER) {
-		return nil, fmt.Errorf("expected number after LIMIT, got %s", p.curToken.Literal)
+	sample := &TableSample{}
+	if p.curIdentIs("SYSTEM") {
+		sample.System = true
+		p.nextToken()
 	}
 
-	count, err := strconv.Atoi(p.curToken.Literal)
+	if !p.curTokenIs(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' after TABLESAMPLE, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+
+	size, err := p.parseExpression()
 	if err != nil {
-		return nil, fmt.Errorf("invalid LIMIT count: %s", p.curToken.Literal)
+		return nil, fmt.Errorf("failed to parse TABLESAMPLE size: %v", err)
 	}
+	sample.Size = size
 
-	clause := &LimitClause{
-		Count:  count,
-		Offset: 0, // Default
+	if p.curIdentIs("PERCENT") || p.curIdentIs("ROWS") {
+		sample.Unit = strings.ToUpper(p.curToken.Literal)
+		p.nextToken()
 	}
 
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' after TABLESAMPLE size, got %s", p.curToken.Literal)
+	}
 	p.nextToken()
 
-	// Check for OFFSET (MySQL/PostgreSQL style: LIMIT count OFFSET offset)
-	if p.curTokenIs(lexer.OFFSET) {
-		p.nextToken()
-		if !p.curTokenIs(lexer.NUMBER) {
-			return nil, fmt.Errorf("expected number after OFFSET, got %s", p.curToken.Literal)
+	if p.curIdentIs("REPEATABLE") {
+		if !p.expectPeek(lexer.LPAREN) {
+			return nil, fmt.Errorf("expected '(' after REPEATABLE, got %s", p.peekToken.Literal)
 		}
+		p.nextToken()
 
-		offset, err := strconv.Atoi(p.curToken.Literal)
+		seed, err := p.parseExpression()
 		if err != nil {
-			return nil, fmt.Errorf("invalid OFFSET value: %s", p.curToken.Literal)
+			return nil, fmt.Errorf("failed to parse REPEATABLE seed: %v", err)
 		}
+		sample.Repeatable = seed
 
-		clause.Offset = offset
+		if !p.curTokenIs(lexer.RPAREN) {
+			return nil, fmt.Errorf("expected ')' after REPEATABLE seed, got %s", p.curToken.Literal)
+		}
 		p.nextToken()
 	}
 
-	return clause, nil
-}
-
-// Basic expression parsing (simplified for now)
-func (p *Parser) parseExpression() (Expression, error) {
-	return p.parseInfixExpression()
+	p.finish(sample, start)
+	return sample, nil
 }
 
-func (p *Parser) parseInfixExpression() (Expression, error) {
-	left, err := p.parsePrimaryExpression()
-	if err != nil {
-		return nil, err
+// Parse WITH (hint [, hint ...]), e.g. WITH (NOLOCK, INDEX(ix_name), FORCESEEK)
+func (p *Parser) parseTableHints() ([]string, error) {
+	if !p.curTokenIs(lexer.WITH) {
+		return nil, fmt.Errorf("expected WITH, got %s", p.curToken.Literal)
 	}
+	if !p.expectPeek(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' after WITH, got %s", p.peekToken.Literal)
+	}
+	p.nextToken()
 
-	for p.isInfixOperator(p.curToken.Type) {
-		if p.curToken.Type == lexer.IN {
-			// Special handling for IN expressions
-			inExpr, err := p.parseInExpression(left)
+	var hints []string
+	for {
+		if !p.curTokenIs(lexer.IDENT) {
+			return nil, fmt.Errorf("expected table hint, got %s", p.curToken.Literal)
+		}
+		hint := strings.ToUpper(p.curToken.Literal)
+		p.nextToken()
+
+		switch {
+		case p.curTokenIs(lexer.LPAREN):
+			args, err := p.parseHintArguments()
 			if err != nil {
 				return nil, err
 			}
-			left = inExpr
-		} else {
-			operator := p.curToken.Literal
+			hint += args
+		case p.curTokenIs(lexer.ASSIGN):
+			// Legacy INDEX = name form
 			p.nextToken()
-
-			right, err := p.parsePrimaryExpression()
-			if err != nil {
-				return nil, err
+			if !p.curTokenIs(lexer.IDENT) && !p.curTokenIs(lexer.NUMBER) {
+				return nil, fmt.Errorf("expected value for table hint %s, got %s", hint, p.curToken.Literal)
+			}
+			value := p.curToken.Literal
+			if p.curTokenIs(lexer.IDENT) {
+				value = QuoteIdent(value)
 			}
+			hint += " = " + value
+			p.nextToken()
+		}
+		hints = append(hints, hint)
 
-			expr := GetBinaryExpression() // Use object pool
-			expr.Left = left
-			expr.Operator = operator
-			expr.Right = right
-			left = expr
+		if !p.curTokenIs(lexer.COMMA) {
+			break
 		}
+		p.nextToken()
 	}
 
-	return left, nil
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' after table hints, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+
+	return hints, nil
 }
 
-func (p *Parser) parseInExpression(left Expression) (Expression, error) {
-	inExpr := &InExpression{
-		Expression: left,
-		Not:        false,
+// parseHintArguments reads a parenthesized hint argument list as text,
+// e.g. (ix_name) or (ix_name(a, b))
+func (p *Parser) parseHintArguments() (string, error) {
+	var args []string
+	for {
+		p.nextToken()
+		var arg string
+		switch p.curToken.Type {
+		case lexer.IDENT:
+			arg = QuoteIdent(p.curToken.Literal)
+			p.nextToken()
+			if p.curTokenIs(lexer.LPAREN) {
+				nested, err := p.parseHintArguments()
+				if err != nil {
+					return "", err
+				}
+				arg += nested
+			}
+		case lexer.NUMBER:
+			arg = p.curToken.Literal
+			p.nextToken()
+		default:
+			return "", fmt.Errorf("unexpected %s in table hint", p.curToken.Literal)
+		}
+		args = append(args, arg)
+
+		if !p.curTokenIs(lexer.COMMA) {
+			break
+		}
 	}
 
-	// Move past the IN token
+	if !p.curTokenIs(lexer.RPAREN) {
+		return "", fmt.Errorf("expected ',' or ')' in table hint, got %s", p.curToken.Literal)
+	}
 	p.nextToken()
 
-	// Expect opening parenthesis
-	if !p.curTokenIs(lexer.LPAREN) {
-		return nil, fmt.Errorf("expected '(' after IN, got %s", p.curToken.Literal)
-	}
+	return "(" + strings.Join(args, ", ") + ")", nil
+}
+
+// curTokenIsFor reports whether a FOR JSON or FOR XML clause starts here
+func (p *Parser) curTokenIsFor() bool {
+	return p.curIdentIs("FOR") && (p.peekIdentIs("JSON") || p.peekIdentIs("XML"))
+}
 
+// forModes lists the modes of FOR JSON and FOR XML
+var forModes = map[string][]string{
+	"JSON": {"AUTO", "PATH"},
+	"XML":  {"RAW", "AUTO", "EXPLICIT", "PATH"},
+}
+
+// forOptions lists the options of FOR JSON and FOR XML, mapped to whether
+// they take a ('name') argument
+var forOptions = map[string]map[string]bool{
+	"JSON": {"ROOT": true, "INCLUDE_NULL_VALUES": false, "WITHOUT_ARRAY_WRAPPER": false},
+	"XML": {"TYPE": false, "ROOT": true, "ELEMENTS": false, "ELEMENTS XSINIL": false, "ELEMENTS ABSENT": false,
+		"BINARY BASE64": false, "XMLDATA": false, "XMLSCHEMA": true},
+}
+
+// Parse FOR JSON mode [, option ...] or FOR XML mode [('name')] [, option ...]
+func (p *Parser) parseForClause() (*ForClause, error) {
+	start := p.pos()
+	p.nextToken()
+	clause := &ForClause{Format: strings.ToUpper(p.curToken.Literal)}
 	p.nextToken()
 
-	// Check if this is a subquery (starts with SELECT)
-	if p.curTokenIs(lexer.SELECT) {
-		// Parse subquery
-		subquery, err := p.parseSelectStatement()
+	for _, mode := range forModes[clause.Format] {
+		if p.curIdentIs(mode) {
+			clause.Mode = mode
+		}
+	}
+	if clause.Mode == "" {
+		return nil, fmt.Errorf("expected %s after FOR %s, got %s",
+			strings.Join(forModes[clause.Format], " or "), clause.Format, p.curToken.Literal)
+	}
+	p.nextToken()
+
+	if p.curTokenIs(lexer.LPAREN) {
+		if clause.Format != "XML" || clause.Mode != "RAW" && clause.Mode != "PATH" {
+			return nil, fmt.Errorf("unexpected element name after FOR %s %s", clause.Format, clause.Mode)
+		}
+		name, err := p.parseForName()
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse subquery in IN clause: %v", err)
+			return nil, err
 		}
+		clause.ElementName = name
+	}
 
-		// Wrap in SubqueryExpression
-		subqueryExpr := &SubqueryExpression{
-			Query: subquery,
+	for p.curTokenIs(lexer.COMMA) {
+		p.nextToken()
+		option, err := p.parseForOption(clause.Format)
+		if err != nil {
+			return nil, err
 		}
-		inExpr.Values = []Expression{subqueryExpr}
-	} else {
-		// Parse list of values
-		values := make([]Expression, 0)
+		clause.Options = append(clause.Options, option)
+	}
 
-		// Parse first value
-		if !p.curTokenIs(lexer.RPAREN) {
-			expr, err := p.parseExpression()
-			if err != nil {
-				return nil, err
-			}
-			values = append(values, expr)
+	p.finish(clause, start)
+	return clause, nil
+}
 
-			// Parse additional values
-			for p.curTokenIs(lexer.COMMA) {
-				p.nextToken()
-				expr, err := p.parseExpression()
-				if err != nil {
-					return nil, err
-				}
-				values = append(values, expr)
-			}
-		}
+// Parse one option of a FOR JSON or FOR XML clause
+func (p *Parser) parseForOption(format string) (*ForOption, error) {
+	option := &ForOption{}
+	start := p.pos()
 
-		inExpr.Values = values
+	var words []string
+	for p.curTokenIsWord() && !p.curTokenIsOption() {
+		words = append(words, strings.ToUpper(p.curToken.Literal))
+		p.nextToken()
 	}
+	option.Name = strings.Join(words, " ")
 
-	// Expect closing parenthesis
-	if !p.curTokenIs(lexer.RPAREN) {
-		return nil, fmt.Errorf("expected ')' after IN values, got %s", p.curToken.Literal)
+	takesName, ok := forOptions[format][option.Name]
+	if !ok {
+		return nil, fmt.Errorf("expected FOR %s option, got %s", format, strings.Join(append(words, p.curToken.Literal), " "))
+	}
+	if takesName && p.curTokenIs(lexer.LPAREN) {
+		name, err := p.parseForName()
+		if err != nil {
+			return nil, err
+		}
+		option.Argument = name
 	}
 
-	p.nextToken()
-
-	return inExpr, nil
+	p.finish(option, start)
+	return option, nil
 }
 
-func (p *Parser) parsePrimaryExpression() (Expression, error) {
-	switch p.curToken.Type {
-	case lexer.IDENT:
-		return p.parseIdentifierExpression()
-	case lexer.NUMBER:
-		return p.parseNumberLiteral()
-	case lexer.STRING:
-		return p.parseStringLiteral()
-	case lexer.ASTERISK:
-		expr := &StarExpression{}
-		p.nextToken()
-		return expr, nil
-	case lexer.LPAREN:
-		return p.parseGroupedExpression()
-	default:
-		return nil, fmt.Errorf("unexpected token in expression: %s", p.curToken.Literal)
+// Parse the ('name') of a FOR XML mode or of ROOT and XMLSCHEMA
+func (p *Parser) parseForName() (Expression, error) {
+	if !p.expectPeek(lexer.STRING) {
+		return nil, fmt.Errorf("expected string name, got %s", p.peekToken.Literal)
+	}
+	name, err := p.parseExpression()
+	if err != nil {
+		return nil, err
 	}
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' after name, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+	return name, nil
 }
 
-func (p *Parser) parseIdentifierExpression() (Expression, error) {
-	firstIdent := p.curToken.Literal
+// curTokenIsOption reports whether an OPTION (...) clause starts here
+func (p *Parser) curTokenIsOption() bool {
+	return p.curIdentIs("OPTION") && p.peekTokenIs(lexer.LPAREN)
+}
+
+// Parse OPTION (hint, ...) at the end of a statement
+func (p *Parser) parseOptionClause() (*OptionClause, error) {
+	clause := &OptionClause{}
+	start := p.pos()
+	p.nextToken()
 	p.nextToken()
 
-	// Check if it's a qualified column (table.column)
-	if p.curTokenIs(lexer.DOT) {
-		p.nextToken()
-		if !p.curTokenIs(lexer.IDENT) && !p.curTokenIs(lexer.ASTERISK) {
-			return nil, fmt.Errorf("expected column name after dot, got %s", p.curToken.Literal)
+	for {
+		hint, err := p.parseQueryHint()
+		if err != nil {
+			return nil, err
 		}
+		clause.Hints = append(clause.Hints, hint)
 
-		if p.curTokenIs(lexer.ASTERISK) {
-			expr := &StarExpression{Table: firstIdent}
-			p.nextToken()
-			return expr, nil
+		if !p.curTokenIs(lexer.COMMA) {
+			break
 		}
-
-		expr := GetColumnReference() // Use object pool
-		expr.Table = firstIdent
-		expr.Column = p.curToken.Literal
 		p.nextToken()
-		return expr, nil
 	}
 
-	// Check if it's a function call
-	if p.curTokenIs(lexer.LPAREN) {
-		return p.parseFunctionCall(firstIdent)
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' to close OPTION, got %s", p.curToken.Literal)
 	}
+	p.nextToken()
 
-	// It's a simple column reference
-	expr := GetColumnReference() // Use object pool
-	expr.Column = firstIdent
-	return expr, nil
+	p.finish(clause, start)
+	return clause, nil
 }
 
-func (p *Parser) parseFunctionCall(name string) (Expression, error) {
-	if !p.curTokenIs(lexer.LPAREN) {
-		return nil, fmt.Errorf("expected '(' for function call, got %s", p.curToken.Literal)
-	}
-
-	p.nextToken()
+// Parse a query hint: words such as RECOMPILE or HASH JOIN, optionally
+// followed by a value, = value or a parenthesized list
+func (p *Parser) parseQueryHint() (*QueryHint, error) {
+	hint := &QueryHint{}
+	start := p.pos()
 
-	var arguments []Expression
+	var words []string
+	for p.curTokenIsWord() {
+		words = append(words, strings.ToUpper(p.curToken.Literal))
+		p.nextToken()
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("expected query hint, got %s", p.curToken.Literal)
+	}
+	hint.Name = strings.Join(words, " ")
 
-	if !p.curTokenIs(lexer.RPAREN) {
-		arg, err := p.parseExpression()
+	switch {
+	case p.curTokenIs(lexer.ASSIGN):
+		hint.Equals = true
+		p.nextToken()
+		fallthrough
+	case p.curTokenIs(lexer.NUMBER), p.curTokenIs(lexer.STRING):
+		value, err := p.parseExpression()
 		if err != nil {
 			return nil, err
 		}
-		arguments = append(arguments, arg)
+		hint.Value = value
+	case p.curTokenIs(lexer.LPAREN) && hint.Name == "OPTIMIZE FOR":
+		variables, err := p.parseOptimizeForVariables()
+		if err != nil {
+			return nil, err
+		}
+		hint.Variables = variables
+	case p.curTokenIs(lexer.LPAREN):
+		p.nextToken()
+		for {
+			arg, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			hint.Arguments = append(hint.Arguments, arg)
 
-		for p.curTokenIs(lexer.COMMA) {
+			if !p.curTokenIs(lexer.COMMA) {
+				break
+			}
 			p.nextToken()
-			arg, err := p.parseExpression()
+		}
+		if !p.curTokenIs(lexer.RPAREN) {
+			return nil, fmt.Errorf("expected ')' to close %s, got %s", hint.Name, p.curToken.Literal)
+		}
+		p.nextToken()
+	}
+
+	p.finish(hint, start)
+	return hint, nil
+}
+
+// Parse the (@p = value, @q UNKNOWN, ...) list of OPTIMIZE FOR
+func (p *Parser) parseOptimizeForVariables() ([]*OptimizeForVariable, error) {
+	var variables []*OptimizeForVariable
+	p.nextToken()
+
+	for {
+		if !p.curTokenIs(lexer.PARAMETER) {
+			return nil, fmt.Errorf("expected variable in OPTIMIZE FOR, got %s", p.curToken.Literal)
+		}
+		variable := &OptimizeForVariable{Name: p.curToken.Literal}
+		start := p.pos()
+		p.nextToken()
+
+		switch {
+		case p.curIdentIs("UNKNOWN"):
+			p.nextToken()
+		case p.curTokenIs(lexer.ASSIGN):
+			p.nextToken()
+			value, err := p.parseExpression()
 			if err != nil {
 				return nil, err
 			}
-			arguments = append(arguments, arg)
+			variable.Value = value
+		default:
+			return nil, fmt.Errorf("expected = or UNKNOWN after %s, got %s", variable.Name, p.curToken.Literal)
+		}
+		p.finish(variable, start)
+		variables = append(variables, variable)
+
+		if !p.curTokenIs(lexer.COMMA) {
+			break
 		}
+		p.nextToken()
 	}
 
 	if !p.curTokenIs(lexer.RPAREN) {
-		return nil, fmt.Errorf("expected ')' to close function call, got %s", p.curToken.Literal)
+		return nil, fmt.Errorf("expected ')' to close OPTIMIZE FOR, got %s", p.curToken.Literal)
 	}
+	p.nextToken()
+	return variables, nil
+}
 
-	p.nextToken() // consume the closing paren
+func (p *Parser) parseDerivedTable() (*TableReference, error) {
+	if !p.curTokenIs(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' before derived table, got %s", p.curToken.Literal)
+	}
 
-	return &FunctionCall{
-		Name:      name,
-		Arguments: arguments,
-	}, nil
-}
+	start := p.pos()
+	p.nextToken()
 
-func (p *Parser) parseNumberLiteral() (Expression, error) {
-	literal := &Literal{}
+	subquery, err := p.parseSelectStatement()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse derived table: %v", err)
+	}
 
-	if strings.Contains(p.curToken.Literal, ".") {
-		value, err := strconv.ParseFloat(p.curToken.Literal, 64)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse %q as float", p.curToken.Literal)
-		}
-		literal.Value = value
-	} else {
-		value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' to close derived table, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	table := &TableReference{Subquery: subquery}
+	if err := p.parseTableAlias(table); err != nil {
+		return nil, err
+	}
+
+	if table.Alias == "" {
+		return nil, fmt.Errorf("derived table requires an alias, got %s", p.curToken.Literal)
+	}
+
+	if p.curTokenIs(lexer.LPAREN) {
+		columns, err := p.parseColumnList()
 		if err != nil {
-			return nil, fmt.Errorf("could not parse %q as integer", p.curToken.Literal)
+			return nil, err
 		}
-		literal.Value = value
+		table.Columns = columns
 	}
 
-	p.nextToken()
-	return literal, nil
-}
+	if err := p.parsePivotOperator(table); err != nil {
+		return nil, err
+	}
 
-func (p *Parser) parseStringLiteral() (Expression, error) {
-	literal := &Literal{Value: p.curToken.Literal}
-	p.nextToken()
-	return literal, nil
+	p.finish(table, start)
+	return table, nil
 }
 
-func (p *Parser) parseGroupedExpression() (Expression, error) {
+// Parse (VALUES (expr, ...), ...) [AS] alias(column, ...)
+func (p *Parser) parseValuesTable() (*TableReference, error) {
+	start := p.pos()
 	p.nextToken()
 
-	exp, err := p.parseExpression()
+	valuesStart := p.pos()
+	rows, err := p.parseValuesList()
 	if err != nil {
 		return nil, err
 	}
+	values := &ValuesClause{Rows: rows}
+	p.finish(values, valuesStart)
 
-	if !p.expectPeek(lexer.RPAREN) {
-		return nil, fmt.Errorf("expected ')' to close grouped expression")
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' to close VALUES table, got %s", p.curToken.Literal)
 	}
+	p.nextToken()
 
-	return exp, nil
-}
+	table := &TableReference{Values: values}
+	if err := p.parseTableAlias(table); err != nil {
+		return nil, err
+	}
+	if table.Alias == "" {
+		return nil, fmt.Errorf("VALUES table requires an alias, got %s", p.curToken.Literal)
+	}
+	if !p.curTokenIs(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected column list after VALUES table alias, got %s", p.curToken.Literal)
+	}
+	columns, err := p.parseColumnList()
+	if err != nil {
+		return nil, err
+	}
+	table.Columns = columns
 
-func (p *Parser) isInfixOperator(tokenType lexer.TokenType) bool {
-	switch tokenType {
-	case lexer.ASSIGN, lexer.EQ, lexer.NOT_EQ, lexer.LT, lexer.GT, lexer.LTE, lexer.GTE,
-		lexer.AND, lexer.OR, lexer.PLUS, lexer.MINUS, lexer.ASTERISK, lexer.SLASH,
-		lexer.LIKE, lexer.IN:
-		return true
-	default:
-		return false
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return nil, fmt.Errorf("VALUES row %d has %d values for %d columns", i+1, len(row), len(columns))
+		}
 	}
-}
 
-// Stub implementations for other statement types
-func (p *Parser) parseInsertStatement() (*InsertStatement, error) {
-	return nil, fmt.Errorf("INSERT statement parsing not implemented yet")
+	p.finish(table, start)
+	return table, nil
 }
 
-func (p *Parser) parseUpdateStatement() (*UpdateStatement, error) {
-	return nil, fmt.Errorf("UPDATE statement parsing not implemented yet")
-}
+// parseTableName parses a [schema.]name without alias
+func (p *Parser) parseTableName() (*TableReference, error) {
+	start := p.pos()
 
-func (p *Parser) parseDeleteStatement() (*DeleteStatement, error) {
-	return nil, fmt.Errorf("DELETE statement parsing not implemented yet")
+	// Table variable, e.g. @results
+	if p.curTokenIs(lexer.PARAMETER) {
+		table := &TableReference{Name: p.curToken.Literal, Kind: TableVariable}
+		p.nextToken()
+		p.finish(table, start)
+		return table, nil
+	}
+
+	if !p.curTokenIs(lexer.IDENT) {
+		return nil, fmt.Errorf("expected table name, got %s", p.curToken.Literal)
+	}
+
+	table := &TableReference{}
+
+	// Up to four dotted parts, server.database.schema.name; an inner part may
+	// be omitted, as in db..name
+	parts := []string{p.curToken.Literal}
+	p.nextToken()
+	for p.curTokenIs(lexer.DOT) {
+		if len(parts) == 4 {
+			return nil, fmt.Errorf("too many parts in table name %s", strings.Join(parts, "."))
+		}
+		p.nextToken()
+		if p.curTokenIs(lexer.DOT) {
+			parts = append(parts, "")
+			continue
+		}
+		if !p.curTokenIs(lexer.IDENT) {
+			return nil, fmt.Errorf("expected table name after dot, got %s", p.curToken.Literal)
+		}
+		parts = append(parts, p.curToken.Literal)
+		p.nextToken()
+	}
+
+	// Parts are right-aligned, so a two-part name is schema.name
+	fields := []*string{&table.Server, &table.Database, &table.Schema, &table.Name}
+	for i, part := range parts {
+		*fields[4-len(parts)+i] = part
+	}
+	switch {
+	case strings.HasPrefix(table.Name, "##"):
+		table.Kind = TableGlobalTemp
+	case strings.HasPrefix(table.Name, "#"):
+		table.Kind = TableLocalTemp
+	}
+
+	p.finish(table, start)
+	return table, nil
+}
+
+// parseTableAlias parses an optional [AS] alias following a table reference
+func (p *Parser) parseTableAlias(table *TableReference) error {
+	if p.curTokenIs(lexer.AS) {
+		p.nextToken()
+		if !p.curTokenIs(lexer.IDENT) {
+			return fmt.Errorf("expected alias after AS, got %s", p.curToken.Literal)
+		}
+		table.Alias = p.curToken.Literal
+		p.nextToken()
+	} else if p.curTokenIs(lexer.IDENT) && !p.curIdentIsTableClause() {
+		// Implicit alias (no AS keyword)
+		table.Alias = p.curToken.Literal
+		p.nextToken()
+	}
+
+	return nil
+}
+
+// curIdentIsTableClause reports whether the current token is a non-reserved
+// word that starts a clause following a table, rather than an implicit alias
+func (p *Parser) curIdentIsTableClause() bool {
+	return p.curIdentIs("OUTPUT") || p.curIdentIs("TABLESAMPLE") || p.curIdentIs("PIVOT") || p.curIdentIs("UNPIVOT") ||
+		p.curTokenIsOption() || p.curTokenIsFor()
+}
+
+// curTokenIsJoin reports whether the current token starts a JOIN clause
+func (p *Parser) curTokenIsJoin() bool {
+	switch p.curToken.Type {
+	case lexer.JOIN, lexer.INNER, lexer.LEFT, lexer.RIGHT, lexer.FULL, lexer.CROSS:
+		return true
+	case lexer.OUTER:
+		// OUTER APPLY
+		return p.peekTokenIs(lexer.APPLY)
+	default:
+		return false
+	}
+}
+
+func (p *Parser) parseJoinClause() (*JoinClause, error) {
+	joinClause := GetJoinClause()
+	start := p.pos()
+
+	// SQL Server CROSS APPLY / OUTER APPLY
+	if (p.curTokenIs(lexer.CROSS) || p.curTokenIs(lexer.OUTER)) && p.peekTokenIs(lexer.APPLY) {
+		joinClause.JoinType = strings.ToUpper(p.curToken.Literal) + " APPLY"
+		p.nextToken()
+		p.nextToken() // move past APPLY
+
+		table, err := p.parseTableReference()
+		if err != nil {
+			PutJoinClause(joinClause)
+			return nil, err
+		}
+		joinClause.Table = *table
+
+		// APPLY has no ON condition
+		p.finish(joinClause, start)
+		return joinClause, nil
+	}
+
+	switch p.curToken.Type {
+	case lexer.LEFT, lexer.RIGHT, lexer.FULL:
+		joinClause.JoinType = strings.ToUpper(p.curToken.Literal)
+		// OUTER is optional: LEFT [OUTER] JOIN
+		if p.peekTokenIs(lexer.OUTER) {
+			p.nextToken()
+		}
+		if !p.expectPeek(lexer.JOIN) {
+			PutJoinClause(joinClause)
+			return nil, fmt.Errorf("expected JOIN after %s", joinClause.JoinType)
+		}
+	case lexer.INNER, lexer.CROSS:
+		joinClause.JoinType = strings.ToUpper(p.curToken.Literal)
+		if !p.expectPeek(lexer.JOIN) {
+			PutJoinClause(joinClause)
+			return nil, fmt.Errorf("expected JOIN after %s", joinClause.JoinType)
+		}
+	case lexer.JOIN:
+		joinClause.JoinType = "INNER"
+	default:
+		PutJoinClause(joinClause)
+		return nil, fmt.Errorf("expected JOIN, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken() // move past JOIN
+
+	table, err := p.parseTableReference()
+	if err != nil {
+		PutJoinClause(joinClause)
+		return nil, err
+	}
+	joinClause.Table = *table
+
+	// CROSS JOIN has no ON condition
+	if joinClause.JoinType == "CROSS" {
+		p.finish(joinClause, start)
+		return joinClause, nil
+	}
+
+	// Parse ON condition
+	if !p.curTokenIs(lexer.ON) {
+		PutJoinClause(joinClause) // Return to pool on error
+		return nil, fmt.Errorf("expected ON after JOIN table, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+	condition, err := p.parseExpression()
+	if err != nil {
+		PutJoinClause(joinClause) // Return to pool on error
+		return nil, err
+	}
+	joinClause.Condition = condition
+
+	p.finish(joinClause, start)
+	return joinClause, nil
+}
+
+func (p *Parser) parseGroupByClause() (*GroupByClause, error) {
+	if !p.curTokenIs(lexer.GROUP) {
+		return nil, fmt.Errorf("expected GROUP, got %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+
+	if !p.expectPeek(lexer.BY) {
+		return nil, fmt.Errorf("expected BY after GROUP")
+	}
+
+	p.nextToken()
+
+	clause := &GroupByClause{}
+	if p.curTokenIs(lexer.ALL) {
+		clause.All = true
+		p.nextToken()
+	}
+
+	// Parse first item
+	item, err := p.parseGroupingElement()
+	if err != nil {
+		return nil, err
+	}
+	clause.Items = append(clause.Items, asOrdinal(item))
+
+	// Parse additional items
+	for p.curTokenIs(lexer.COMMA) {
+		p.nextToken()
+		item, err := p.parseGroupingElement()
+		if err != nil {
+			return nil, err
+		}
+		clause.Items = append(clause.Items, asOrdinal(item))
+	}
+
+	// Legacy WITH ROLLUP / WITH CUBE modifier
+	if p.curTokenIs(lexer.WITH) && (p.peekIdentIs("ROLLUP") || p.peekIdentIs("CUBE")) {
+		p.nextToken()
+		if clause.All {
+			return nil, fmt.Errorf("GROUP BY ALL cannot be combined with WITH %s", strings.ToUpper(p.curToken.Literal))
+		}
+		clause.WithRollup = p.curIdentIs("ROLLUP")
+		clause.WithCube = !clause.WithRollup
+		p.nextToken()
+	}
+
+	p.finish(clause, start)
+	return clause, nil
+}
+
+// asOrdinal converts a bare integer literal in ORDER BY or GROUP BY into the
+// select-list position it refers to
+func asOrdinal(expr Expression) Expression {
+	lit, ok := expr.(*Literal)
+	if !ok {
+		return expr
+	}
+	index, ok := lit.Value.(int64)
+	if !ok {
+		return expr
+	}
+	ordinal := &OrdinalReference{Index: int(index)}
+	ordinal.setSpan(lit.Span())
+	return ordinal
+}
+
+// parseGroupingElement parses a GROUP BY item: ROLLUP(...), CUBE(...),
+// GROUPING SETS(...) or a plain expression
+func (p *Parser) parseGroupingElement() (Expression, error) {
+	start := p.pos()
+
+	switch {
+	case p.curIdentIs("ROLLUP") && p.peekTokenIs(lexer.LPAREN):
+		p.nextToken()
+		groups, err := p.parseGroupingList()
+		if err != nil {
+			return nil, err
+		}
+		rollup := &RollupExpression{Groups: groups}
+		p.finish(rollup, start)
+		return rollup, nil
+	case p.curIdentIs("CUBE") && p.peekTokenIs(lexer.LPAREN):
+		p.nextToken()
+		groups, err := p.parseGroupingList()
+		if err != nil {
+			return nil, err
+		}
+		cube := &CubeExpression{Groups: groups}
+		p.finish(cube, start)
+		return cube, nil
+	case p.curIdentIs("GROUPING") && p.peekIdentIs("SETS"):
+		p.nextToken()
+		if !p.expectPeek(lexer.LPAREN) {
+			return nil, fmt.Errorf("expected '(' after GROUPING SETS, got %s", p.peekToken.Literal)
+		}
+		groups, err := p.parseGroupingList()
+		if err != nil {
+			return nil, err
+		}
+		sets := &GroupingSetsExpression{Sets: groups}
+		p.finish(sets, start)
+		return sets, nil
+	default:
+		return p.parseExpression()
+	}
+}
+
+// parseGroupingList parses a parenthesized list of groupings, where each
+// grouping is a single element or a parenthesized (possibly empty) column list
+func (p *Parser) parseGroupingList() ([][]Expression, error) {
+	if !p.curTokenIs(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' before grouping list, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+
+	var groups [][]Expression
+	for {
+		var group []Expression
+
+		if p.curTokenIs(lexer.LPAREN) {
+			p.nextToken()
+			for !p.curTokenIs(lexer.RPAREN) {
+				expr, err := p.parseExpression()
+				if err != nil {
+					return nil, err
+				}
+				group = append(group, expr)
+
+				if !p.curTokenIs(lexer.COMMA) {
+					break
+				}
+				p.nextToken()
+			}
+			if !p.curTokenIs(lexer.RPAREN) {
+				return nil, fmt.Errorf("expected ')' to close grouping, got %s", p.curToken.Literal)
+			}
+			p.nextToken()
+			if group == nil {
+				group = []Expression{} // empty grouping set ()
+			}
+		} else {
+			expr, err := p.parseGroupingElement()
+			if err != nil {
+				return nil, err
+			}
+			group = []Expression{expr}
+		}
+		groups = append(groups, group)
+
+		if !p.curTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' to close grouping list, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+
+	return groups, nil
+}
+
+func (p *Parser) parseOrderByClause() ([]*OrderByClause, error) {
+	if !p.curTokenIs(lexer.ORDER) {
+		return nil, fmt.Errorf("expected ORDER, got %s", p.curToken.Literal)
+	}
+
+	if !p.expectPeek(lexer.BY) {
+		return nil, fmt.Errorf("expected BY after ORDER")
+	}
+
+	p.nextToken()
+
+	var clauses []*OrderByClause
+
+	// Parse first clause
+	clause, err := p.parseOrderByItem()
+	if err != nil {
+		return nil, err
+	}
+	clauses = append(clauses, clause)
+
+	// Parse additional clauses
+	for p.curTokenIs(lexer.COMMA) {
+		p.nextToken()
+		clause, err := p.parseOrderByItem()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+
+	return clauses, nil
+}
+
+func (p *Parser) parseOrderByItem() (*OrderByClause, error) {
+	start := p.pos()
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	clause := &OrderByClause{
+		Expression: expr,
+		Direction:  "ASC", // Default
+	}
+
+	// The collation of the whole sort key belongs to the clause
+	if collate, ok := expr.(*CollateExpression); ok {
+		clause.Expression = collate.Expr
+		clause.Collation = collate.Collation
+	}
+
+	// Check for ASC/DESC
+	if p.curTokenIs(lexer.IDENT) {
+		direction := strings.ToUpper(p.curToken.Literal)
+		if direction == "ASC" || direction == "DESC" {
+			clause.Direction = direction
+			p.nextToken()
+		}
+	}
+
+	// NULLS FIRST / NULLS LAST
+	if p.curIdentIs("NULLS") {
+		p.nextToken()
+		if !p.curIdentIs("FIRST") && !p.curIdentIs("LAST") {
+			return nil, fmt.Errorf("expected FIRST or LAST after NULLS, got %s", p.curToken.Literal)
+		}
+		clause.Nulls = strings.ToUpper(p.curToken.Literal)
+		p.nextToken()
+	}
+
+	p.finish(clause, start)
+	return clause, nil
+}
+
+func (p *Parser) parseOffsetFetchClause() (*OffsetFetchClause, error) {
+	if !p.curTokenIs(lexer.OFFSET) {
+		return nil, fmt.Errorf("expected OFFSET, got %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+	p.nextToken()
+
+	offset, err := p.parseRowCount("OFFSET")
+	if err != nil {
+		return nil, err
+	}
+
+	clause := &OffsetFetchClause{Offset: offset}
+
+	if !p.curIdentIs("FETCH") {
+		p.finish(clause, start)
+		return clause, nil
+	}
+
+	p.nextToken()
+
+	// FETCH NEXT and FETCH FIRST are synonyms
+	if !p.curIdentIs("NEXT") && !p.curIdentIs("FIRST") {
+		return nil, fmt.Errorf("expected NEXT or FIRST after FETCH, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	fetch, err := p.parseRowCount("FETCH")
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.curIdentIs("ONLY") {
+		return nil, fmt.Errorf("expected ONLY after FETCH count, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	clause.Fetch = fetch
+	clause.HasFetch = true
+
+	p.finish(clause, start)
+	return clause, nil
+}
+
+// parseRowCount parses "n ROWS" or "n ROW" as used by OFFSET and FETCH
+func (p *Parser) parseRowCount(clause string) (int, error) {
+	if !p.curTokenIs(lexer.NUMBER) {
+		return 0, fmt.Errorf("expected number after %s, got %s", clause, p.curToken.Literal)
+	}
+
+	count, err := strconv.Atoi(p.curToken.Literal)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s count: %s", clause, p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	if !p.curIdentIs("ROWS") && !p.curIdentIs("ROW") {
+		return 0, fmt.Errorf("expected ROWS after %s count, got %s", clause, p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	return count, nil
+}
+
+// curIdentIs reports whether the current token is the given non-reserved keyword
+func (p *Parser) curIdentIs(keyword string) bool {
+	return p.curTokenIs(lexer.IDENT) && strings.EqualFold(p.curToken.Literal, keyword)
+}
+
+// peekIdentIs reports whether the next token is the given non-reserved keyword
+func (p *Parser) peekIdentIs(keyword string) bool {
+	return p.peekTokenIs(lexer.IDENT) && strings.EqualFold(p.peekToken.Literal, keyword)
+}
+
+func (p *Parser) parseLimitClause() (*LimitClause, error) {
+	if !p.curTokenIs(lexer.LIMIT) {
+		return nil, fmt.Errorf("expected LIMIT, got %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+	p.nextToken()
+
+	// Parse count
+	if !p.curTokenIs(lexer.NUMBER) {
+		return nil, fmt.Errorf("expected number after LIMIT, got %s", p.curToken.Literal)
+	}
+
+	count, err := strconv.Atoi(p.curToken.Literal)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LIMIT count: %s", p.curToken.Literal)
+	}
+
+	clause := &LimitClause{
+		Count:  count,
+		Offset: 0, // Default
+	}
+
+	p.nextToken()
+
+	// Check for OFFSET (MySQL/PostgreSQL style: LIMIT count OFFSET offset)
+	if p.curTokenIs(lexer.OFFSET) {
+		p.nextToken()
+		if !p.curTokenIs(lexer.NUMBER) {
+			return nil, fmt.Errorf("expected number after OFFSET, got %s", p.curToken.Literal)
+		}
+
+		offset, err := strconv.Atoi(p.curToken.Literal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OFFSET value: %s", p.curToken.Literal)
+		}
+
+		clause.Offset = offset
+		p.nextToken()
+	}
+
+	p.finish(clause, start)
+	return clause, nil
+}
+
+// Operator precedence levels, from loosest to tightest binding
+const (
+	precLowest int = iota
+	precOr
+	precAnd
+	precComparison // =, <, >, LIKE, IN, BETWEEN
+	precBitwise    // &, |, ^
+	precSum        // +, - (also string concatenation)
+	precProduct    // *, /, %
+	precPrefix     // unary -, +, ~
+)
+
+var precedences = map[lexer.TokenType]int{
+	lexer.OR:        precOr,
+	lexer.AND:       precAnd,
+	lexer.ASSIGN:    precComparison,
+	lexer.EQ:        precComparison,
+	lexer.NOT_EQ:    precComparison,
+	lexer.LT:        precComparison,
+	lexer.GT:        precComparison,
+	lexer.LTE:       precComparison,
+	lexer.GTE:       precComparison,
+	lexer.LIKE:      precComparison,
+	lexer.IN:        precComparison,
+	lexer.BETWEEN:   precComparison,
+	lexer.NOT:       precComparison, // NOT IN, NOT BETWEEN, NOT LIKE
+	lexer.IS:        precComparison,
+	lexer.AMPERSAND: precBitwise,
+	lexer.PIPE:      precBitwise,
+	lexer.CARET:     precBitwise,
+	lexer.PLUS:      precSum,
+	lexer.MINUS:     precSum,
+	lexer.ASTERISK:  precProduct,
+	lexer.SLASH:     precProduct,
+	lexer.PERCENT:   precProduct,
+}
+
+func (p *Parser) curPrecedence() int {
+	if prec, ok := precedences[p.curToken.Type]; ok {
+		return prec
+	}
+	return precLowest
+}
+
+// Basic expression parsing
+func (p *Parser) parseExpression() (Expression, error) {
+	return p.parseScalarExpression(precLowest)
+}
+
+// parseScalarExpression parses operators binding tighter than the given
+// precedence, rejecting a row value, which can only be compared or used with IN
+func (p *Parser) parseScalarExpression(precedence int) (Expression, error) {
+	expr, err := p.parseInfixExpression(precedence)
+	if err != nil {
+		return nil, err
+	}
+	if err := scalarOperand(expr); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// scalarOperand rejects a row value where a single value is expected
+func scalarOperand(expr Expression) error {
+	if row, ok := expr.(*RowValueExpression); ok {
+		return fmt.Errorf("row value %s can only be compared or used with IN", row.String())
+	}
+	return nil
+}
+
+// parseInfixExpression parses operators binding tighter than the given precedence
+func (p *Parser) parseInfixExpression(precedence int) (Expression, error) {
+	if err := p.descend(); err != nil {
+		return nil, err
+	}
+	defer p.ascend()
+
+	start := p.pos()
+	left, err := p.parsePrimaryExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	// COLLATE applies to the operand before it, binding tighter than any operator
+	for p.curIdentIs("COLLATE") {
+		if err := scalarOperand(left); err != nil {
+			return nil, err
+		}
+		p.nextToken()
+		if !p.curTokenIs(lexer.IDENT) {
+			return nil, fmt.Errorf("expected collation name after COLLATE, got %s", p.curToken.Literal)
+		}
+		left = &CollateExpression{Expr: left, Collation: p.curToken.Literal}
+		p.nextToken()
+		p.finish(left, start)
+	}
+
+	for p.isInfixOperator(p.curToken.Type) && precedence < p.curPrecedence() {
+		switch p.curToken.Type {
+		case lexer.IN:
+			// Special handling for IN expressions
+			inExpr, err := p.parseInExpression(left, false)
+			if err != nil {
+				return nil, err
+			}
+			left = inExpr
+		case lexer.BETWEEN:
+			if err := scalarOperand(left); err != nil {
+				return nil, err
+			}
+			betweenExpr, err := p.parseBetweenExpression(left, false)
+			if err != nil {
+				return nil, err
+			}
+			left = betweenExpr
+		case lexer.LIKE:
+			if err := scalarOperand(left); err != nil {
+				return nil, err
+			}
+			likeExpr, err := p.parseLikeExpression(left, false)
+			if err != nil {
+				return nil, err
+			}
+			left = likeExpr
+		case lexer.NOT:
+			// NOT IN / NOT BETWEEN / NOT LIKE
+			p.nextToken()
+			var negated Expression
+			var err error
+			switch p.curToken.Type {
+			case lexer.IN:
+				negated, err = p.parseInExpression(left, true)
+			case lexer.LIKE:
+				if err = scalarOperand(left); err == nil {
+					negated, err = p.parseLikeExpression(left, true)
+				}
+			default:
+				if err = scalarOperand(left); err == nil {
+					negated, err = p.parseBetweenExpression(left, true)
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+			left = negated
+		case lexer.IS:
+			if err := scalarOperand(left); err != nil {
+				return nil, err
+			}
+			isNullExpr, err := p.parseIsNullExpression(left)
+			if err != nil {
+				return nil, err
+			}
+			left = isNullExpr
+		default:
+			operator := p.curToken.Literal
+			opPrecedence := p.curPrecedence()
+			p.nextToken()
+
+			right, err := p.parseInfixExpression(opPrecedence)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkRowOperands(left, operator, opPrecedence, right); err != nil {
+				return nil, err
+			}
+
+			expr := GetBinaryExpression() // Use object pool
+			expr.Left = left
+			expr.Operator = operator
+			expr.Right = right
+			left = expr
+		}
+		p.finish(left, start)
+	}
+
+	return left, nil
+}
+
+// checkRowOperands allows a row value only as an operand of a comparison with
+// a row of the same width or a subquery
+func checkRowOperands(left Expression, operator string, precedence int, right Expression) error {
+	leftRow, leftIsRow := left.(*RowValueExpression)
+	rightRow, rightIsRow := right.(*RowValueExpression)
+	if !leftIsRow && !rightIsRow {
+		return nil
+	}
+	if precedence != precComparison {
+		return fmt.Errorf("operator %s cannot be applied to a row value", operator)
+	}
+
+	switch {
+	case leftIsRow && rightIsRow:
+		if len(leftRow.Values) == len(rightRow.Values) {
+			return nil
+		}
+	case leftIsRow:
+		if _, ok := right.(*SubqueryExpression); ok {
+			return nil
+		}
+	default:
+		if _, ok := left.(*SubqueryExpression); ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot compare %s with %s: rows must have the same number of values", left.String(), right.String())
+}
+
+func (p *Parser) parseBetweenExpression(left Expression, not bool) (Expression, error) {
+	if !p.curTokenIs(lexer.BETWEEN) {
+		return nil, fmt.Errorf("expected BETWEEN, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	// Bounds bind tighter than AND so the separating AND is not consumed
+	lower, err := p.parseScalarExpression(precComparison)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.curTokenIs(lexer.AND) {
+		return nil, fmt.Errorf("expected AND in BETWEEN expression, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	upper, err := p.parseScalarExpression(precComparison)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BetweenExpression{
+		Expr:  left,
+		Lower: lower,
+		Upper: upper,
+		Not:   not,
+	}, nil
+}
+
+// Parse [NOT] LIKE pattern [ESCAPE escape]
+func (p *Parser) parseLikeExpression(left Expression, not bool) (Expression, error) {
+	if !p.curTokenIs(lexer.LIKE) {
+		return nil, fmt.Errorf("expected LIKE, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	pattern, err := p.parseScalarExpression(precComparison)
+	if err != nil {
+		return nil, err
+	}
+
+	like := &LikeExpression{Expr: left, Pattern: pattern, Not: not}
+
+	if p.curIdentIs("ESCAPE") {
+		p.nextToken()
+		escape, err := p.parseScalarExpression(precComparison)
+		if err != nil {
+			return nil, err
+		}
+		like.Escape = escape
+	}
+
+	return like, nil
+}
+
+func (p *Parser) parseIsNullExpression(left Expression) (Expression, error) {
+	if !p.curTokenIs(lexer.IS) {
+		return nil, fmt.Errorf("expected IS, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	expr := &IsNullExpression{Expr: left}
+
+	if p.curTokenIs(lexer.NOT) {
+		expr.Negated = true
+		p.nextToken()
+	}
+
+	if !p.curTokenIs(lexer.NULL) {
+		return nil, fmt.Errorf("expected NULL after IS, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	return expr, nil
+}
+
+func (p *Parser) parseInExpression(left Expression, not bool) (Expression, error) {
+	inExpr := &InExpression{
+		Expression: left,
+		Not:        not,
+	}
+
+	// Move past the IN token
+	p.nextToken()
+
+	// Expect opening parenthesis
+	if !p.curTokenIs(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' after IN, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	// Check if this is a subquery (starts with SELECT)
+	if p.curTokenIs(lexer.SELECT) {
+		// Parse subquery
+		subquery, err := p.parseSelectStatement()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse subquery in IN clause: %v", err)
+		}
+
+		inExpr.Subquery = subquery
+	} else {
+		// Parse list of values
+		values := make([]Expression, 0)
+
+		// Parse first value
+		if !p.curTokenIs(lexer.RPAREN) {
+			expr, err := p.parseInfixExpression(precLowest)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, expr)
+
+			// Parse additional values
+			for p.curTokenIs(lexer.COMMA) {
+				p.nextToken()
+				expr, err := p.parseInfixExpression(precLowest)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, expr)
+			}
+		}
+
+		inExpr.Values = values
+
+		// (a, b) IN ((1, 2), ...) compares rows of the same width
+		if row, ok := left.(*RowValueExpression); ok {
+			for _, value := range values {
+				if other, ok := value.(*RowValueExpression); !ok || len(other.Values) != len(row.Values) {
+					return nil, fmt.Errorf("expected rows of %d values in IN list, got %s", len(row.Values), value.String())
+				}
+			}
+		} else {
+			for _, value := range values {
+				if err := scalarOperand(value); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// Expect closing parenthesis
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' after IN values, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	return inExpr, nil
+}
+
+func (p *Parser) parsePrimaryExpression() (Expression, error) {
+	start := p.pos()
+
+	switch p.curToken.Type {
+	case lexer.IDENT:
+		if p.peekTokenIs(lexer.LPAREN) {
+			switch {
+			case p.curIdentIs("CAST"), p.curIdentIs("TRY_CAST"):
+				return p.parseCastExpression()
+			case p.curIdentIs("CONVERT"), p.curIdentIs("TRY_CONVERT"):
+				return p.parseConvertExpression()
+			}
+		}
+		return p.parseIdentifierExpression()
+	case lexer.LEFT, lexer.RIGHT:
+		// LEFT(s, n) and RIGHT(s, n) string functions
+		if p.peekTokenIs(lexer.LPAREN) {
+			return p.parseIdentifierExpression()
+		}
+		return nil, fmt.Errorf("unexpected token in expression: %s", p.curToken.Literal)
+	case lexer.NUMBER:
+		return p.parseNumberLiteral()
+	case lexer.HEX:
+		return p.parseHexLiteral()
+	case lexer.STRING:
+		return p.parseStringLiteral()
+	case lexer.NULL:
+		literal := &Literal{Value: Null}
+		p.nextToken()
+		p.finish(literal, start)
+		return literal, nil
+	case lexer.PARAMETER:
+		param := &Parameter{Name: p.curToken.Literal}
+		p.nextToken()
+		p.finish(param, start)
+		return param, nil
+	case lexer.SYSTEM_VARIABLE:
+		variable := &SystemVariable{Name: p.curToken.Literal}
+		p.nextToken()
+		p.finish(variable, start)
+		return variable, nil
+	case lexer.PLACEHOLDER:
+		p.placeholderCount++
+		param := &Parameter{Position: p.placeholderCount}
+		p.nextToken()
+		p.finish(param, start)
+		return param, nil
+	case lexer.TRUE, lexer.FALSE:
+		literal := &Literal{Value: p.curTokenIs(lexer.TRUE)}
+		p.nextToken()
+		p.finish(literal, start)
+		return literal, nil
+	case lexer.ASTERISK:
+		return p.parseStar(), nil
+	case lexer.LPAREN:
+		return p.parseGroupedExpression()
+	case lexer.CASE:
+		return p.parseCaseExpression()
+	case lexer.EXISTS:
+		return p.parseExistsExpression(false)
+	case lexer.NOT:
+		if p.peekTokenIs(lexer.EXISTS) {
+			p.nextToken()
+			exists, err := p.parseExistsExpression(true)
+			if err != nil {
+				return nil, err
+			}
+			p.finish(exists, start)
+			return exists, nil
+		}
+		return p.parseUnaryExpression()
+	case lexer.MINUS, lexer.PLUS, lexer.TILDE:
+		return p.parseUnaryExpression()
+	case lexer.ILLEGAL:
+		return nil, fmt.Errorf("illegal token %q at line %d, column %d", p.curToken.Literal, p.curToken.Line, p.curToken.Column)
+	default:
+		return nil, fmt.Errorf("unexpected token in expression: %s", p.curToken.Literal)
+	}
+}
+
+// parseUnaryExpression parses a prefix -, +, ~ or NOT operator and its operand.
+// NOT binds looser than comparisons so NOT a = b is NOT (a = b), while
+// sign and ~ operators bind tighter than any binary operator.
+func (p *Parser) parseUnaryExpression() (Expression, error) {
+	expr := &UnaryExpression{Operator: strings.ToUpper(p.curToken.Literal)}
+	start := p.pos()
+
+	precedence := precPrefix
+	if p.curTokenIs(lexer.NOT) {
+		precedence = precAnd
+	}
+	p.nextToken()
+
+	operand, err := p.parseScalarExpression(precedence)
+	if err != nil {
+		return nil, err
+	}
+	expr.Operand = operand
+
+	p.finish(expr, start)
+	return expr, nil
+}
+
+func (p *Parser) parseIdentifierExpression() (Expression, error) {
+	start := p.pos()
+	firstIdent := p.curToken.Literal
+	p.nextToken()
+
+	// Check if it's a qualified column (table.column)
+	if p.curTokenIs(lexer.DOT) {
+		p.nextToken()
+		if !p.curTokenIs(lexer.IDENT) && !p.curTokenIs(lexer.ASTERISK) {
+			return nil, fmt.Errorf("expected column name after dot, got %s", p.curToken.Literal)
+		}
+
+		if p.curTokenIs(lexer.ASTERISK) {
+			expr := &StarExpression{Table: firstIdent}
+			p.nextToken()
+			p.finish(expr, start)
+			return expr, nil
+		}
+
+		expr := GetColumnReference() // Use object pool
+		expr.Table = firstIdent
+		expr.Column = p.curToken.Literal
+		p.nextToken()
+		p.finish(expr, start)
+		return expr, nil
+	}
+
+	// Check if it's a function call
+	if p.curTokenIs(lexer.LPAREN) {
+		if p.isFullTextPredicate(firstIdent) {
+			pred, err := p.parseFullTextPredicate(firstIdent)
+			if err != nil {
+				return nil, err
+			}
+			p.finish(pred, start)
+			return pred, nil
+		}
+
+		call, err := p.parseFunctionCall(firstIdent)
+		if err != nil {
+			return nil, err
+		}
+		p.finish(call, start)
+		return call, nil
+	}
+
+	// It's a simple column reference
+	expr := GetColumnReference() // Use object pool
+	expr.Column = firstIdent
+	p.finish(expr, start)
+	return expr, nil
+}
+
+// Parse CAST(expr AS type) or TRY_CAST(expr AS type)
+func (p *Parser) parseCastExpression() (Expression, error) {
+	cast := &CastExpression{Function: strings.ToUpper(p.curToken.Literal)}
+	cast.Try = strings.HasPrefix(cast.Function, "TRY_")
+	start := p.pos()
+
+	if !p.expectPeek(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' after %s", cast.Function)
+	}
+	p.nextToken()
+
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	cast.Expression = expr
+
+	if !p.curTokenIs(lexer.AS) {
+		return nil, fmt.Errorf("expected AS in %s, got %s", cast.Function, p.curToken.Literal)
+	}
+	p.nextToken()
+
+	dataType, err := p.parseDataType()
+	if err != nil {
+		return nil, err
+	}
+	cast.DataType = dataType
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' to close %s, got %s", cast.Function, p.curToken.Literal)
+	}
+	p.nextToken()
+
+	p.finish(cast, start)
+	return cast, nil
+}
+
+// Parse CONVERT(type, expr [, style]) or TRY_CONVERT(type, expr [, style])
+func (p *Parser) parseConvertExpression() (Expression, error) {
+	cast := &CastExpression{Function: strings.ToUpper(p.curToken.Literal)}
+	cast.Try = strings.HasPrefix(cast.Function, "TRY_")
+	start := p.pos()
+
+	if !p.expectPeek(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' after %s", cast.Function)
+	}
+	p.nextToken()
+
+	dataType, err := p.parseDataType()
+	if err != nil {
+		return nil, err
+	}
+	cast.DataType = dataType
+
+	if !p.curTokenIs(lexer.COMMA) {
+		return nil, fmt.Errorf("expected ',' after data type in %s, got %s", cast.Function, p.curToken.Literal)
+	}
+	p.nextToken()
+
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	cast.Expression = expr
+
+	if p.curTokenIs(lexer.COMMA) {
+		p.nextToken()
+		style, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		cast.Style = style
+	}
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' to close %s, got %s", cast.Function, p.curToken.Literal)
+	}
+	p.nextToken()
+
+	p.finish(cast, start)
+	return cast, nil
+}
+
+// Parse a data type name with optional size, e.g. INT, VARCHAR(50),
+// NVARCHAR(MAX), DECIMAL(10,2)
+func (p *Parser) parseDataType() (*DataType, error) {
+	if !p.curTokenIs(lexer.IDENT) {
+		return nil, fmt.Errorf("expected data type, got %s", p.curToken.Literal)
+	}
+
+	dataType := &DataType{Name: strings.ToUpper(p.curToken.Literal)}
+	start := p.pos()
+	p.nextToken()
+
+	if !p.curTokenIs(lexer.LPAREN) {
+		p.finish(dataType, start)
+		return dataType, nil
+	}
+	p.nextToken()
+
+	if p.curIdentIs("MAX") {
+		dataType.Max = true
+		p.nextToken()
+	} else {
+		var params []int
+		for {
+			if !p.curTokenIs(lexer.NUMBER) {
+				return nil, fmt.Errorf("expected size for data type %s, got %s", dataType.Name, p.curToken.Literal)
+			}
+			size, err := strconv.Atoi(p.curToken.Literal)
+			if err != nil {
+				return nil, fmt.Errorf("invalid size for data type %s: %s", dataType.Name, p.curToken.Literal)
+			}
+			params = append(params, size)
+			p.nextToken()
+
+			if !p.curTokenIs(lexer.COMMA) {
+				break
+			}
+			p.nextToken()
+		}
+
+		switch {
+		case len(params) > 2:
+			return nil, fmt.Errorf("too many size parameters for data type %s", dataType.Name)
+		case len(params) == 2:
+			dataType.Precision, dataType.Scale = params[0], params[1]
+		case dataType.Name == "DECIMAL" || dataType.Name == "NUMERIC":
+			dataType.Precision = params[0]
+		default:
+			dataType.Length = params[0]
+		}
+	}
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' after data type size, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+
+	p.finish(dataType, start)
+	return dataType, nil
+}
+
+func (p *Parser) parseFunctionCall(name string) (Expression, error) {
+	if !p.curTokenIs(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' for function call, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	var arguments []Expression
+
+	// Aggregate with DISTINCT, e.g. COUNT(DISTINCT user_id)
+	distinct := false
+	if p.curTokenIs(lexer.DISTINCT) {
+		distinct = true
+		p.nextToken()
+	}
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		var arg Expression
+		var err error
+		if p.takesDatePart(name) {
+			arg, err = p.parseDatePart()
+		} else {
+			arg, err = p.parseExpression()
+		}
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, arg)
+
+		for p.curTokenIs(lexer.COMMA) {
+			p.nextToken()
+			arg, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			arguments = append(arguments, arg)
+		}
+	}
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' to close function call, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken() // consume the closing paren
+
+	funcCall := &FunctionCall{
+		Name:      name,
+		Distinct:  distinct,
+		Arguments: arguments,
+	}
+
+	if p.curIdentIs("OVER") {
+		over, err := p.parseWindowSpec()
+		if err != nil {
+			return nil, err
+		}
+		funcCall.Over = over
+	}
+
+	return funcCall, nil
+}
+
+// isFullTextPredicate reports whether name is a SQL Server full-text
+// predicate rather than a function
+func (p *Parser) isFullTextPredicate(name string) bool {
+	return p.dialect.Name() == "SQL Server" && (strings.EqualFold(name, "CONTAINS") || strings.EqualFold(name, "FREETEXT"))
+}
+
+// Parse CONTAINS or FREETEXT (column | (column, ...) | *, search [, LANGUAGE language])
+func (p *Parser) parseFullTextPredicate(name string) (*FullTextPredicate, error) {
+	pred := &FullTextPredicate{Function: strings.ToUpper(name)}
+	p.nextToken() // consume '('
+
+	switch {
+	case p.curTokenIs(lexer.ASTERISK):
+		pred.Columns = append(pred.Columns, p.parseStar())
+	case p.curTokenIs(lexer.LPAREN):
+		p.nextToken()
+		for {
+			col, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			pred.Columns = append(pred.Columns, col)
+			if !p.curTokenIs(lexer.COMMA) {
+				break
+			}
+			p.nextToken()
+		}
+		if !p.curTokenIs(lexer.RPAREN) {
+			return nil, fmt.Errorf("expected ')' after %s column list, got %s", pred.Function, p.curToken.Literal)
+		}
+		p.nextToken()
+	default:
+		col, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		pred.Columns = append(pred.Columns, col)
+	}
+
+	if !p.curTokenIs(lexer.COMMA) {
+		return nil, fmt.Errorf("expected ',' before %s search condition, got %s", pred.Function, p.curToken.Literal)
+	}
+	p.nextToken()
+
+	search, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	pred.Search = search
+
+	if p.curTokenIs(lexer.COMMA) {
+		p.nextToken()
+		if !p.curIdentIs("LANGUAGE") {
+			return nil, fmt.Errorf("expected LANGUAGE in %s, got %s", pred.Function, p.curToken.Literal)
+		}
+		p.nextToken()
+		language, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		pred.Language = language
+	}
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' to close %s, got %s", pred.Function, p.curToken.Literal)
+	}
+	p.nextToken()
+
+	return pred, nil
+}
+
+// takesDatePart reports whether the function called name takes a date part as
+// its first argument, e.g. DATEADD(day, 7, order_date) in SQL Server
+func (p *Parser) takesDatePart(name string) bool {
+	return p.dialect.Name() == "SQL Server" && datePartFunctions[strings.ToUpper(name)]
+}
+
+// Parse a date part such as day or dd
+func (p *Parser) parseDatePart() (*DatePart, error) {
+	if !p.curTokenIs(lexer.IDENT) {
+		return nil, fmt.Errorf("expected date part, got %s", p.curToken.Literal)
+	}
+	if _, ok := dateParts[strings.ToUpper(p.curToken.Literal)]; !ok {
+		return nil, fmt.Errorf("unknown date part %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+	part := &DatePart{Name: p.curToken.Literal}
+	p.nextToken()
+	p.finish(part, start)
+	return part, nil
+}
+
+func (p *Parser) parseWindowSpec() (*WindowSpec, error) {
+	if !p.curIdentIs("OVER") {
+		return nil, fmt.Errorf("expected OVER, got %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+	p.nextToken()
+
+	if !p.curTokenIs(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' after OVER, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	spec := &WindowSpec{}
+
+	if p.curIdentIs("PARTITION") {
+		if !p.expectPeek(lexer.BY) {
+			return nil, fmt.Errorf("expected BY after PARTITION")
+		}
+
+		p.nextToken()
+
+		expr, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		spec.PartitionBy = append(spec.PartitionBy, expr)
+
+		for p.curTokenIs(lexer.COMMA) {
+			p.nextToken()
+			expr, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			spec.PartitionBy = append(spec.PartitionBy, expr)
+		}
+	}
+
+	if p.curTokenIs(lexer.ORDER) {
+		orderBy, err := p.parseOrderByClause()
+		if err != nil {
+			return nil, err
+		}
+		spec.OrderBy = orderBy
+	}
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' to close OVER clause, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	p.finish(spec, start)
+	return spec, nil
+}
+
+func (p *Parser) parseExistsExpression(not bool) (Expression, error) {
+	if !p.curTokenIs(lexer.EXISTS) {
+		return nil, fmt.Errorf("expected EXISTS, got %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+	p.nextToken()
+
+	if !p.curTokenIs(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' after EXISTS, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	subquery, err := p.parseSelectStatement()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse subquery in EXISTS: %v", err)
+	}
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' after EXISTS subquery, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	exists := &ExistsExpression{Subquery: subquery, Not: not}
+	p.finish(exists, start)
+	return exists, nil
+}
+
+func (p *Parser) parseCaseExpression() (Expression, error) {
+	if !p.curTokenIs(lexer.CASE) {
+		return nil, fmt.Errorf("expected CASE, got %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+	p.nextToken()
+
+	caseExpr := &CaseExpression{}
+
+	// Simple CASE has an operand before the first WHEN
+	if !p.curTokenIs(lexer.WHEN) {
+		operand, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		caseExpr.Operand = operand
+	}
+
+	for p.curTokenIs(lexer.WHEN) {
+		whenStart := p.pos()
+		p.nextToken()
+
+		condition, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.curTokenIs(lexer.THEN) {
+			return nil, fmt.Errorf("expected THEN in CASE expression, got %s", p.curToken.Literal)
+		}
+
+		p.nextToken()
+
+		result, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		when := &WhenClause{
+			Condition: condition,
+			Result:    result,
+		}
+		p.finish(when, whenStart)
+		caseExpr.WhenClauses = append(caseExpr.WhenClauses, when)
+	}
+
+	if len(caseExpr.WhenClauses) == 0 {
+		return nil, fmt.Errorf("expected WHEN in CASE expression, got %s", p.curToken.Literal)
+	}
+
+	if p.curTokenIs(lexer.ELSE) {
+		p.nextToken()
+		elseExpr, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		caseExpr.Else = elseExpr
+	}
+
+	if !p.curTokenIs(lexer.END) {
+		return nil, fmt.Errorf("expected END to close CASE expression, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	p.finish(caseExpr, start)
+	return caseExpr, nil
+}
+
+func (p *Parser) parseNumberLiteral() (Expression, error) {
+	literal := &Literal{}
+	start := p.pos()
+
+	if strings.ContainsAny(p.curToken.Literal, ".eE") {
+		value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %q as float", p.curToken.Literal)
+		}
+		literal.Value = value
+	} else {
+		value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %q as integer", p.curToken.Literal)
+		}
+		literal.Value = value
+	}
+
+	p.nextToken()
+	p.finish(literal, start)
+	return literal, nil
+}
+
+// parseHexLiteral parses a 0x literal into a []byte value. An odd number of
+// digits is padded with a leading zero, as SQL Server does.
+func (p *Parser) parseHexLiteral() (Expression, error) {
+	start := p.pos()
+	digits := p.curToken.Literal[2:]
+	if len(digits)%2 == 1 {
+		digits = "0" + digits
+	}
+	value, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %q as binary", p.curToken.Literal)
+	}
+
+	literal := &Literal{Value: value}
+	p.nextToken()
+	p.finish(literal, start)
+	return literal, nil
+}
+
+func (p *Parser) parseStringLiteral() (Expression, error) {
+	literal := &Literal{Value: p.curToken.Literal, Unicode: p.curToken.Unicode}
+	start := p.pos()
+	p.nextToken()
+	p.finish(literal, start)
+	return literal, nil
+}
+
+func (p *Parser) parseGroupedExpression() (Expression, error) {
+	start := p.pos()
+	p.nextToken()
+
+	// Scalar subquery: (SELECT ...)
+	if p.curTokenIs(lexer.SELECT) {
+		subquery, err := p.parseSelectStatement()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse subquery: %v", err)
+		}
+
+		if !p.curTokenIs(lexer.RPAREN) {
+			return nil, fmt.Errorf("expected ')' to close subquery, got %s", p.curToken.Literal)
+		}
+
+		p.nextToken()
+
+		expr := &SubqueryExpression{Query: subquery}
+		p.finish(expr, start)
+		return expr, nil
+	}
+
+	exp, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	// A comma makes a row value constructor rather than a grouping
+	if p.curTokenIs(lexer.COMMA) {
+		row := &RowValueExpression{Values: []Expression{exp}}
+		for p.curTokenIs(lexer.COMMA) {
+			p.nextToken()
+			value, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			row.Values = append(row.Values, value)
+		}
+		exp = row
+	}
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' to close grouped expression, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	if row, ok := exp.(*RowValueExpression); ok {
+		p.finish(row, start)
+	}
+	return exp, nil
+}
+
+func (p *Parser) isInfixOperator(tokenType lexer.TokenType) bool {
+	switch tokenType {
+	case lexer.ASSIGN, lexer.EQ, lexer.NOT_EQ, lexer.LT, lexer.GT, lexer.LTE, lexer.GTE,
+		lexer.AND, lexer.OR, lexer.PLUS, lexer.MINUS, lexer.ASTERISK, lexer.SLASH, lexer.PERCENT,
+		lexer.AMPERSAND, lexer.PIPE, lexer.CARET, lexer.LIKE, lexer.IN, lexer.BETWEEN, lexer.IS:
+		return true
+	case lexer.NOT:
+		// NOT is only an infix operator when negating IN, BETWEEN or LIKE
+		return p.peekTokenIs(lexer.IN) || p.peekTokenIs(lexer.BETWEEN) || p.peekTokenIs(lexer.LIKE)
+	default:
+		return false
+	}
+}
+
+// Parse INSERT statement
+func (p *Parser) parseInsertStatement() (*InsertStatement, error) {
+	if !p.curTokenIs(lexer.INSERT) {
+		return nil, fmt.Errorf("expected INSERT, got %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+	p.nextToken()
+
+	// INTO is optional in SQL Server and MySQL
+	if p.curTokenIs(lexer.INTO) {
+		p.nextToken()
+	}
+
+	// The target is a plain table name; a following '(' starts the column list
+	table, err := p.parseTableName()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &InsertStatement{Table: *table}
+
+	if p.curTokenIs(lexer.LPAREN) {
+		columns, err := p.parseColumnList()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Columns = columns
+	}
+
+	if p.curIdentIs("OUTPUT") {
+		output, err := p.parseOutputClause()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Output = output
+	}
+
+	switch p.curToken.Type {
+	case lexer.VALUES:
+		values, err := p.parseValuesList()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Values = values
+
+		if p.curTokenIsOption() {
+			option, err := p.parseOptionClause()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Option = option
+		}
+	case lexer.SELECT:
+		source, err := p.parseSelectStatement()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SELECT in INSERT: %v", err)
+		}
+		stmt.Source = source
+	default:
+		return nil, fmt.Errorf("expected VALUES or SELECT in INSERT, got %s", p.curToken.Literal)
+	}
+
+	p.finish(stmt, start)
+	return stmt, nil
+}
+
+func (p *Parser) parseColumnList() ([]string, error) {
+	if !p.curTokenIs(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' before column list, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	var columns []string
+
+	for {
+		if !p.curTokenIs(lexer.IDENT) {
+			return nil, fmt.Errorf("expected column name in column list, got %s", p.curToken.Literal)
+		}
+		columns = append(columns, p.curToken.Literal)
+		p.nextToken()
+
+		if !p.curTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' after column list, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	return columns, nil
+}
+
+func (p *Parser) parseValuesList() ([][]Expression, error) {
+	if !p.curTokenIs(lexer.VALUES) {
+		return nil, fmt.Errorf("expected VALUES, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	var rows [][]Expression
+
+	for {
+		if !p.curTokenIs(lexer.LPAREN) {
+			return nil, fmt.Errorf("expected '(' after VALUES, got %s", p.curToken.Literal)
+		}
+
+		p.nextToken()
+
+		var row []Expression
+
+		if !p.curTokenIs(lexer.RPAREN) {
+			expr, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, expr)
+
+			for p.curTokenIs(lexer.COMMA) {
+				p.nextToken()
+				expr, err := p.parseExpression()
+				if err != nil {
+					return nil, err
+				}
+				row = append(row, expr)
+			}
+		}
+
+		if !p.curTokenIs(lexer.RPAREN) {
+			return nil, fmt.Errorf("expected ')' after VALUES row, got %s", p.curToken.Literal)
+		}
+
+		p.nextToken()
+		rows = append(rows, row)
+
+		if !p.curTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	return rows, nil
+}
+
+// Parse UPDATE statement
+func (p *Parser) parseUpdateStatement() (*UpdateStatement, error) {
+	if !p.curTokenIs(lexer.UPDATE) {
+		return nil, fmt.Errorf("expected UPDATE, got %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+	p.nextToken()
+
+	table, err := p.parseTableReference()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &UpdateStatement{Table: *table}
+
+	if !p.curTokenIs(lexer.SET) {
+		return nil, fmt.Errorf("expected SET after UPDATE table, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	assignment, err := p.parseAssignment()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Set = append(stmt.Set, assignment)
+
+	for p.curTokenIs(lexer.COMMA) {
+		p.nextToken()
+		assignment, err := p.parseAssignment()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Set = append(stmt.Set, assignment)
+	}
+
+	if p.curIdentIs("OUTPUT") {
+		output, err := p.parseOutputClause()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Output = output
+	}
+
+	// SQL Server allows UPDATE ... SET ... FROM ... JOIN ...
+	if p.curTokenIs(lexer.FROM) {
+		fromClause, err := p.parseFromClause()
+		if err != nil {
+			return nil, err
+		}
+		stmt.From = fromClause
+
+		for p.curTokenIsJoin() {
+			joinClause, err := p.parseJoinClause()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Joins = append(stmt.Joins, joinClause)
+		}
+	}
+
+	if p.curTokenIs(lexer.WHERE) {
+		p.nextToken()
+		whereExpr, err := p.parseSearchCondition()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Where = whereExpr
+	}
+
+	if p.curTokenIsOption() {
+		option, err := p.parseOptionClause()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Option = option
+	}
+
+	p.finish(stmt, start)
+	return stmt, nil
+}
+
+func (p *Parser) parseAssignment() (*Assignment, error) {
+	if !p.curTokenIs(lexer.IDENT) {
+		return nil, fmt.Errorf("expected column name in SET clause, got %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+	column := &ColumnReference{Column: p.curToken.Literal}
+	p.nextToken()
+
+	// Qualified column (alias.column)
+	if p.curTokenIs(lexer.DOT) {
+		p.nextToken()
+		if !p.curTokenIs(lexer.IDENT) {
+			return nil, fmt.Errorf("expected column name after dot, got %s", p.curToken.Literal)
+		}
+		column.Table = column.Column
+		column.Column = p.curToken.Literal
+		p.nextToken()
+	}
+	p.finish(column, start)
+
+	if !p.curTokenIs(lexer.ASSIGN) && !p.curTokenIs(lexer.EQ) {
+		return nil, fmt.Errorf("expected '=' after column %s, got %s", column.String(), p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	value, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	assignment := &Assignment{Column: column, Value: value}
+	p.finish(assignment, start)
+	return assignment, nil
+}
+
+// Parse DELETE statement
+func (p *Parser) parseDeleteStatement() (*DeleteStatement, error) {
+	if !p.curTokenIs(lexer.DELETE) {
+		return nil, fmt.Errorf("expected DELETE, got %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+	p.nextToken()
+
+	stmt := &DeleteStatement{}
+
+	// SQL Server batch deletes: DELETE TOP (n) FROM t
+	if p.curTokenIs(lexer.TOP) {
+		topClause, err := p.parseTopClause()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Top = topClause
+	}
+
+	// FROM is optional in T-SQL (DELETE t WHERE ...)
+	if p.curTokenIs(lexer.FROM) {
+		p.nextToken()
+	}
+
+	table, err := p.parseTableReference()
+	if err != nil {
+		return nil, err
+	}
+	stmt.From = *table
+
+	if p.curIdentIs("OUTPUT") {
+		output, err := p.parseOutputClause()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Output = output
+	}
+
+	if p.curTokenIs(lexer.WHERE) {
+		p.nextToken()
+		whereExpr, err := p.parseSearchCondition()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Where = whereExpr
+	}
+
+	if p.curTokenIsOption() {
+		option, err := p.parseOptionClause()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Option = option
+	}
+
+	p.finish(stmt, start)
+	return stmt, nil
+}
+
+// parseSearchCondition parses the WHERE condition of an UPDATE or DELETE,
+// which may instead name the row a cursor is positioned on
+func (p *Parser) parseSearchCondition() (Expression, error) {
+	if p.curIdentIs("CURRENT") && p.peekIdentIs("OF") {
+		return p.parseCurrentOf()
+	}
+	return p.parseExpression()
+}
+
+// Parse CURRENT OF [GLOBAL] cursor_name or CURRENT OF @cursor_variable
+func (p *Parser) parseCurrentOf() (*CurrentOfClause, error) {
+	start := p.pos()
+	p.nextToken() // CURRENT
+	p.nextToken() // OF
+
+	clause := &CurrentOfClause{}
+	if p.curIdentIs("GLOBAL") && (p.peekTokenIs(lexer.IDENT) || p.peekTokenIs(lexer.PARAMETER)) {
+		clause.Global = true
+		p.nextToken()
+	}
+	if !p.curTokenIs(lexer.IDENT) && !(p.curTokenIs(lexer.PARAMETER) && !clause.Global) {
+		return nil, fmt.Errorf("expected cursor name after CURRENT OF, got %s", p.curToken.Literal)
+	}
+	clause.Cursor = p.curToken.Literal
+	p.nextToken()
+
+	p.finish(clause, start)
+	return clause, nil
+}
+
+// Parse OUTPUT columns [INTO target [(columns)]], where the columns refer to
+// the inserted and deleted pseudo-tables, e.g. OUTPUT inserted.id INTO @ids
+func (p *Parser) parseOutputClause() (*OutputClause, error) {
+	if !p.curIdentIs("OUTPUT") {
+		return nil, fmt.Errorf("expected OUTPUT, got %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+	p.nextToken()
+
+	columns, err := p.parseSelectList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OUTPUT columns: %v", err)
+	}
+	clause := &OutputClause{Columns: columns}
+
+	if p.curTokenIs(lexer.INTO) {
+		p.nextToken()
+		table, err := p.parseTableName()
+		if err != nil {
+			return nil, err
+		}
+		clause.Into = table
+
+		if p.curTokenIs(lexer.LPAREN) {
+			intoColumns, err := p.parseColumnList()
+			if err != nil {
+				return nil, err
+			}
+			clause.IntoColumns = intoColumns
+		}
+	}
+
+	p.finish(clause, start)
+	return clause, nil
+}
+
+// Parse MERGE [INTO] target USING source ON condition WHEN ... THEN ...
+func (p *Parser) parseMergeStatement() (*MergeStatement, error) {
+	if !p.curTokenIs(lexer.MERGE) {
+		return nil, fmt.Errorf("expected MERGE, got %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+	p.nextToken()
+
+	if p.curTokenIs(lexer.INTO) {
+		p.nextToken()
+	}
+
+	target, err := p.parseTableReference()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &MergeStatement{Target: *target}
+
+	if !p.curTokenIs(lexer.USING) {
+		return nil, fmt.Errorf("expected USING after MERGE target, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	source, err := p.parseTableReference()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Source = *source
+
+	if !p.curTokenIs(lexer.ON) {
+		return nil, fmt.Errorf("expected ON after MERGE source, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	condition, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Condition = condition
+
+	for p.curTokenIs(lexer.WHEN) {
+		clause, err := p.parseMergeWhenClause()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Clauses = append(stmt.Clauses, clause)
+	}
+
+	if len(stmt.Clauses) == 0 {
+		return nil, fmt.Errorf("expected WHEN in MERGE statement, got %s", p.curToken.Literal)
+	}
+
+	p.finish(stmt, start)
+	return stmt, nil
+}
+
+// Parse WHEN [NOT] MATCHED [BY TARGET | BY SOURCE] [AND condition] THEN action
+func (p *Parser) parseMergeWhenClause() (*MergeWhenClause, error) {
+	if !p.curTokenIs(lexer.WHEN) {
+		return nil, fmt.Errorf("expected WHEN, got %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+	p.nextToken()
+
+	clause := &MergeWhenClause{Matched: true}
+
+	if p.curTokenIs(lexer.NOT) {
+		clause.Matched = false
+		p.nextToken()
+	}
+
+	if !p.curIdentIs("MATCHED") {
+		return nil, fmt.Errorf("expected MATCHED after WHEN, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	if !clause.Matched && p.curTokenIs(lexer.BY) {
+		p.nextToken()
+		switch {
+		case p.curIdentIs("TARGET"):
+		case p.curIdentIs("SOURCE"):
+			clause.BySource = true
+		default:
+			return nil, fmt.Errorf("expected TARGET or SOURCE after NOT MATCHED BY, got %s", p.curToken.Literal)
+		}
+		p.nextToken()
+	}
+
+	if p.curTokenIs(lexer.AND) {
+		p.nextToken()
+		condition, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		clause.Condition = condition
+	}
+
+	if !p.curTokenIs(lexer.THEN) {
+		return nil, fmt.Errorf("expected THEN in MERGE WHEN clause, got %s", p.curToken.Literal)
+	}
+
+	p.nextToken()
+
+	// NOT MATCHED [BY TARGET] rows only exist in the source, so they can only
+	// be inserted; MATCHED and NOT MATCHED BY SOURCE rows can only be updated
+	// or deleted
+	insertOnly := !clause.Matched && !clause.BySource
+
+	switch {
+	case p.curTokenIs(lexer.UPDATE) && !insertOnly:
+		clause.Action = "UPDATE"
+		if !p.expectPeek(lexer.SET) {
+			return nil, fmt.Errorf("expected SET after UPDATE, got %s", p.peekToken.Literal)
+		}
+		p.nextToken()
+
+		for {
+			assignment, err := p.parseAssignment()
+			if err != nil {
+				return nil, err
+			}
+			clause.Set = append(clause.Set, assignment)
+
+			if !p.curTokenIs(lexer.COMMA) {
+				break
+			}
+			p.nextToken()
+		}
+	case p.curTokenIs(lexer.DELETE) && !insertOnly:
+		clause.Action = "DELETE"
+		p.nextToken()
+	case p.curTokenIs(lexer.INSERT) && insertOnly:
+		clause.Action = "INSERT"
+		p.nextToken()
+
+		if p.curTokenIs(lexer.LPAREN) {
+			columns, err := p.parseColumnList()
+			if err != nil {
+				return nil, err
+			}
+			clause.Columns = columns
+		}
+
+		rows, err := p.parseValuesList()
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) != 1 {
+			return nil, fmt.Errorf("expected a single VALUES row in MERGE INSERT, got %d", len(rows))
+		}
+		clause.Values = rows[0]
+	case insertOnly:
+		return nil, fmt.Errorf("expected INSERT in WHEN NOT MATCHED clause, got %s", p.curToken.Literal)
+	default:
+		return nil, fmt.Errorf("expected UPDATE or DELETE in WHEN MATCHED clause, got %s", p.curToken.Literal)
+	}
+
+	p.finish(clause, start)
+	return clause, nil
+}
+
+// Parse CREATE statement
+func (p *Parser) parseCreateStatement() (Statement, error) {
+	if !p.curTokenIs(lexer.CREATE) {
+		return nil, fmt.Errorf("expected CREATE, got %s", p.curToken.Literal)
+	}
+
+	start := p.pos()
+
+	switch {
+	case p.peekTokenIs(lexer.TABLE):
+		p.nextToken()
+		stmt, err := p.parseCreateTableStatement()
+		if err != nil {
+			return nil, err
+		}
+		p.finish(stmt, start)
+		return stmt, nil
+	case p.peekIdentIs("INDEX"), p.peekIdentIs("UNIQUE"), p.peekIdentIs("CLUSTERED"), p.peekIdentIs("NONCLUSTERED"):
+		p.nextToken()
+		stmt, err := p.parseCreateIndexStatement()
+		if err != nil {
+			return nil, err
+		}
+		p.finish(stmt, start)
+		return stmt, nil
+	case p.peekIdentIs("VIEW"), p.peekTokenIs(lexer.OR):
+		p.nextToken()
+		stmt, err := p.parseCreateViewStatement()
+		if err != nil {
+			return nil, err
+		}
+		p.finish(stmt, start)
+		return stmt, nil
+	default:
+		return nil, fmt.Errorf("unsupported CREATE statement: CREATE %s", p.peekToken.Literal)
+	}
+}
+
+// Parse [UNIQUE] [CLUSTERED | NONCLUSTERED] INDEX name ON table (columns)
+// [INCLUDE (columns)] [WHERE predicate]
+func (p *Parser) parseCreateIndexStatement() (*CreateIndexStatement, error) {
+	stmt := &CreateIndexStatement{}
+
+	if p.curIdentIs("UNIQUE") {
+		stmt.Unique = true
+		p.nextToken()
+	}
+	if p.curIdentIs("CLUSTERED") || p.curIdentIs("NONCLUSTERED") {
+		stmt.Clustered = strings.ToUpper(p.curToken.Literal)
+		p.nextToken()
+	}
+	if !p.curIdentIs("INDEX") {
+		return nil, fmt.Errorf("expected INDEX, got %s", p.curToken.Literal)
+	}
+
+	if !p.expectPeek(lexer.IDENT) {
+		return nil, fmt.Errorf("expected index name, got %s", p.peekToken.Literal)
+	}
+	stmt.Name = p.curToken.Literal
+
+	if !p.expectPeek(lexer.ON) {
+		return nil, fmt.Errorf("expected ON after index name, got %s", p.peekToken.Literal)
+	}
+	p.nextToken()
+
+	table, err := p.parseTableName()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Table = *table
+
+	if !p.curTokenIs(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' after table name, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+
+	for {
+		column, err := p.parseIndexColumn()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Columns = append(stmt.Columns, column)
+
+		if !p.curTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' after index columns, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+
+	if p.curIdentIs("INCLUDE") {
+		p.nextToken()
+		include, err := p.parseColumnList()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Include = include
+	}
+
+	if p.curTokenIs(lexer.WHERE) {
+		p.nextToken()
+		where, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Where = where
+	}
+
+	return stmt, nil
+}
+
+// Parse an index key column: name [ASC | DESC]
+func (p *Parser) parseIndexColumn() (*IndexColumn, error) {
+	if !p.curTokenIs(lexer.IDENT) {
+		return nil, fmt.Errorf("expected column name in index, got %s", p.curToken.Literal)
+	}
+	start := p.pos()
+	column := &IndexColumn{Name: p.curToken.Literal, Direction: "ASC"}
+	p.nextToken()
+
+	if p.curIdentIs("ASC") || p.curIdentIs("DESC") {
+		column.Direction = strings.ToUpper(p.curToken.Literal)
+		p.nextToken()
+	}
+
+	p.finish(column, start)
+	return column, nil
+}
+
+// Parse [OR ALTER] VIEW name [(columns)] [WITH SCHEMABINDING] AS query
+func (p *Parser) parseCreateViewStatement() (*CreateViewStatement, error) {
+	stmt := &CreateViewStatement{}
+
+	if p.curTokenIs(lexer.OR) {
+		if !p.expectPeek(lexer.ALTER) {
+			return nil, fmt.Errorf("expected ALTER after CREATE OR, got %s", p.peekToken.Literal)
+		}
+		stmt.OrAlter = true
+		p.nextToken()
+	}
+	if !p.curIdentIs("VIEW") {
+		return nil, fmt.Errorf("expected VIEW, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+
+	view, err := p.parseTableName()
+	if err != nil {
+		return nil, err
+	}
+	stmt.View = *view
+
+	if p.curTokenIs(lexer.LPAREN) {
+		columns, err := p.parseColumnList()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Columns = columns
+	}
+
+	if p.curTokenIs(lexer.WITH) {
+		p.nextToken()
+		if !p.curIdentIs("SCHEMABINDING") {
+			return nil, fmt.Errorf("unsupported view option: %s", p.curToken.Literal)
+		}
+		stmt.SchemaBinding = true
+		p.nextToken()
+	}
+
+	if !p.curTokenIs(lexer.AS) {
+		return nil, fmt.Errorf("expected AS before view query, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+
+	var query Statement
+	switch p.curToken.Type {
+	case lexer.SELECT:
+		query, err = p.parseQueryExpression()
+	case lexer.WITH:
+		query, err = p.parseWithStatement()
+	default:
+		return nil, fmt.Errorf("expected SELECT after AS, got %s", p.curToken.Literal)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch query.(type) {
+	case *SelectStatement, *SetOperation:
+	default:
+		return nil, fmt.Errorf("view must be defined by a query, got %s", query.Type())
+	}
+	stmt.Query = query
+
+	return stmt, nil
+}
+
+// Parse CREATE TABLE name (column definitions and table constraints)
+func (p *Parser) parseCreateTableStatement() (*CreateTableStatement, error) {
+	if !p.curTokenIs(lexer.TABLE) {
+		return nil, fmt.Errorf("expected TABLE, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+
+	stmt := &CreateTableStatement{}
+
+	table, err := p.parseTableName()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Table = *table
+
+	if !p.curTokenIs(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected '(' after table name, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+
+	for {
+		if p.isTableConstraintStart() {
+			constraint, err := p.parseTableConstraint()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Constraints = append(stmt.Constraints, constraint)
+		} else {
+			column, err := p.parseColumnDefinition()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Columns = append(stmt.Columns, column)
+		}
+
+		if !p.curTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ')' to close column definitions, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+
+	return stmt, nil
+}
+
+func (p *Parser) isTableConstraintStart() bool {
+	switch {
+	case p.curIdentIs("CONSTRAINT"):
+		return true
+	case p.curIdentIs("PRIMARY"), p.curIdentIs("FOREIGN"):
+		return p.peekIdentIs("KEY")
+	case p.curIdentIs("UNIQUE"), p.curIdentIs("CHECK"):
+		return p.peekTokenIs(lexer.LPAREN)
+	}
+	return false
+}
+
+// Parse a column definition: name type [NULL | NOT NULL] [DEFAULT expr]
+// [IDENTITY[(seed, increment)]] [PRIMARY KEY] [UNIQUE] [column constraints]
+func (p *Parser) parseColumnDefinition() (*ColumnDefinition, error) {
+	if !p.curTokenIs(lexer.IDENT) {
+		return nil, fmt.Errorf("expected column name, got %s", p.curToken.Literal)
+	}
+
+	column := &ColumnDefinition{Name: p.curToken.Literal}
+	start := p.pos()
+	p.nextToken()
+
+	dataType, err := p.parseDataType()
+	if err != nil {
+		return nil, err
+	}
+	column.DataType = dataType
+
+	for {
+		switch {
+		case p.curTokenIs(lexer.NULL):
+			column.Null = true
+			p.nextToken()
+		case p.curTokenIs(lexer.NOT):
+			if !p.expectPeek(lexer.NULL) {
+				return nil, fmt.Errorf("expected NULL after NOT, got %s", p.peekToken.Literal)
+			}
+			column.NotNull = true
+			p.nextToken()
+		case p.curIdentIs("DEFAULT"):
+			p.nextToken()
+			value, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			column.Default = value
+		case p.curIdentIs("IDENTITY"):
+			identity, err := p.parseIdentitySpec()
+			if err != nil {
+				return nil, err
+			}
+			column.Identity = identity
+		case p.curIdentIs("PRIMARY"):
+			if !p.peekIdentIs("KEY") {
+				return nil, fmt.Errorf("expected KEY after PRIMARY, got %s", p.peekToken.Literal)
+			}
+			p.nextToken()
+			p.nextToken()
+			column.PrimaryKey = true
+		case p.curIdentIs("UNIQUE"):
+			column.Unique = true
+			p.nextToken()
+		case p.curIdentIs("CONSTRAINT"), p.curIdentIs("CHECK"), p.curIdentIs("REFERENCES"):
+			constraint, err := p.parseColumnConstraint()
+			if err != nil {
+				return nil, err
+			}
+			column.Constraints = append(column.Constraints, constraint)
+		default:
+			p.finish(column, start)
+			return column, nil
+		}
+	}
+}
+
+// Parse IDENTITY or IDENTITY(seed, increment), optionally followed by
+// NOT FOR REPLICATION
+func (p *Parser) parseIdentitySpec() (*IdentitySpec, error) {
+	identity := &IdentitySpec{Seed: 1, Increment: 1}
+	start := p.pos()
+	p.nextToken()
+
+	if p.curTokenIs(lexer.LPAREN) {
+		p.nextToken()
+		seed, err := p.parseIdentityValue("seed")
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.curTokenIs(lexer.COMMA) {
+			return nil, fmt.Errorf("expected ',' in IDENTITY, got %s", p.curToken.Literal)
+		}
+		p.nextToken()
+		increment, err := p.parseIdentityValue("increment")
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.curTokenIs(lexer.RPAREN) {
+			return nil, fmt.Errorf("expected ')' to close IDENTITY, got %s", p.curToken.Literal)
+		}
+		p.nextToken()
+
+		identity.Seed = seed
+		identity.Increment = increment
+	}
+
+	if p.curTokenIs(lexer.NOT) && p.peekIdentIs("FOR") {
+		p.nextToken()
+		if !p.peekIdentIs("REPLICATION") {
+			return nil, fmt.Errorf("expected REPLICATION after NOT FOR, got %s", p.peekToken.Literal)
+		}
+		p.nextToken()
+		p.nextToken()
+		identity.NotForReplication = true
+	}
+
+	p.finish(identity, start)
+	return identity, nil
+}
+
+// Parse an optionally negative IDENTITY seed or increment
+func (p *Parser) parseIdentityValue(what string) (int, error) {
+	negative := p.curTokenIs(lexer.MINUS)
+	if negative {
+		p.nextToken()
+	}
+	if !p.curTokenIs(lexer.NUMBER) {
+		return 0, fmt.Errorf("expected IDENTITY %s, got %s", what, p.curToken.Literal)
+	}
+	value, err := strconv.Atoi(p.curToken.Literal)
+	if err != nil {
+		return 0, fmt.Errorf("invalid IDENTITY %s: %s", what, p.curToken.Literal)
+	}
+	p.nextToken()
+
+	if negative {
+		value = -value
+	}
+	return value, nil
+}
+
+// Parse a table constraint: [CONSTRAINT name] PRIMARY KEY (cols) | UNIQUE (cols)
+// | CHECK (expr) | FOREIGN KEY (cols) REFERENCES table (cols) [ON DELETE action]
+// [ON UPDATE action]
+func (p *Parser) parseTableConstraint() (*TableConstraint, error) {
+	constraint := &TableConstraint{}
+	start := p.pos()
+
+	if err := p.parseConstraintName(constraint); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.curIdentIs("PRIMARY") && p.peekIdentIs("KEY"):
+		constraint.Kind = "PRIMARY KEY"
+		p.nextToken()
+	case p.curIdentIs("FOREIGN") && p.peekIdentIs("KEY"):
+		constraint.Kind = "FOREIGN KEY"
+		p.nextToken()
+	case p.curIdentIs("UNIQUE"):
+		constraint.Kind = "UNIQUE"
+	case p.curIdentIs("CHECK"):
+		if err := p.parseCheckConstraint(constraint); err != nil {
+			return nil, err
+		}
+		p.finish(constraint, start)
+		return constraint, nil
+	default:
+		return nil, fmt.Errorf("expected PRIMARY KEY, FOREIGN KEY, UNIQUE or CHECK, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+
+	columns, err := p.parseColumnList()
+	if err != nil {
+		return nil, err
+	}
+	constraint.Columns = columns
+
+	if constraint.Kind != "FOREIGN KEY" {
+		p.finish(constraint, start)
+		return constraint, nil
+	}
+
+	if !p.curIdentIs("REFERENCES") {
+		return nil, fmt.Errorf("expected REFERENCES after FOREIGN KEY columns, got %s", p.curToken.Literal)
+	}
+	if err := p.parseReferences(constraint); err != nil {
+		return nil, err
+	}
+
+	p.finish(constraint, start)
+	return constraint, nil
+}
+
+// Parse a column constraint: [CONSTRAINT name] PRIMARY KEY | UNIQUE
+// | CHECK (expr) | DEFAULT expr | REFERENCES table [(cols)] [ON DELETE action]
+// [ON UPDATE action]
+func (p *Parser) parseColumnConstraint() (*TableConstraint, error) {
+	constraint := &TableConstraint{}
+	start := p.pos()
+
+	if err := p.parseConstraintName(constraint); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.curIdentIs("PRIMARY"):
+		if !p.peekIdentIs("KEY") {
+			return nil, fmt.Errorf("expected KEY after PRIMARY, got %s", p.peekToken.Literal)
+		}
+		constraint.Kind = "PRIMARY KEY"
+		p.nextToken()
+		p.nextToken()
+	case p.curIdentIs("UNIQUE"):
+		constraint.Kind = "UNIQUE"
+		p.nextToken()
+	case p.curIdentIs("CHECK"):
+		if err := p.parseCheckConstraint(constraint); err != nil {
+			return nil, err
+		}
+	case p.curIdentIs("DEFAULT"):
+		constraint.Kind = "DEFAULT"
+		p.nextToken()
+		value, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		constraint.Expr = value
+	case p.curIdentIs("REFERENCES"):
+		constraint.Kind = "FOREIGN KEY"
+		if err := p.parseReferences(constraint); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("expected PRIMARY KEY, UNIQUE, CHECK, DEFAULT or REFERENCES, got %s", p.curToken.Literal)
+	}
+
+	p.finish(constraint, start)
+	return constraint, nil
+}
+
+// Parse an optional CONSTRAINT name prefix
+func (p *Parser) parseConstraintName(constraint *TableConstraint) error {
+	if !p.curIdentIs("CONSTRAINT") {
+		return nil
+	}
+	if !p.expectPeek(lexer.IDENT) {
+		return fmt.Errorf("expected constraint name, got %s", p.peekToken.Literal)
+	}
+	constraint.Name = p.curToken.Literal
+	p.nextToken()
+	return nil
+}
+
+// Parse CHECK (expr)
+func (p *Parser) parseCheckConstraint(constraint *TableConstraint) error {
+	constraint.Kind = "CHECK"
+	if !p.expectPeek(lexer.LPAREN) {
+		return fmt.Errorf("expected '(' after CHECK, got %s", p.peekToken.Literal)
+	}
+	p.nextToken()
+
+	condition, err := p.parseExpression()
+	if err != nil {
+		return err
+	}
+	constraint.Expr = condition
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		return fmt.Errorf("expected ')' to close CHECK, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+	return nil
+}
+
+// Parse REFERENCES table [(cols)] followed by ON DELETE and ON UPDATE actions
+func (p *Parser) parseReferences(constraint *TableConstraint) error {
+	p.nextToken()
+
+	table, err := p.parseTableName()
+	if err != nil {
+		return err
+	}
+	constraint.References = table
+
+	if p.curTokenIs(lexer.LPAREN) {
+		refColumns, err := p.parseColumnList()
+		if err != nil {
+			return err
+		}
+		constraint.ReferencedColumns = refColumns
+	}
+
+	for p.curTokenIs(lexer.ON) {
+		p.nextToken()
+		event := p.curToken.Type
+		if event != lexer.DELETE && event != lexer.UPDATE {
+			return fmt.Errorf("expected DELETE or UPDATE after ON, got %s", p.curToken.Literal)
+		}
+		p.nextToken()
+
+		action, err := p.parseReferentialAction()
+		if err != nil {
+			return err
+		}
+		if event == lexer.DELETE {
+			constraint.OnDelete = action
+		} else {
+			constraint.OnUpdate = action
+		}
+	}
+	return nil
+}
+
+// Parse CASCADE | SET NULL | SET DEFAULT | NO ACTION | RESTRICT
+func (p *Parser) parseReferentialAction() (string, error) {
+	var action string
+	switch {
+	case p.curIdentIs("CASCADE"), p.curIdentIs("RESTRICT"):
+		action = strings.ToUpper(p.curToken.Literal)
+	case p.curTokenIs(lexer.SET):
+		p.nextToken()
+		switch {
+		case p.curTokenIs(lexer.NULL):
+			action = "SET NULL"
+		case p.curIdentIs("DEFAULT"):
+			action = "SET DEFAULT"
+		default:
+			return "", fmt.Errorf("expected NULL or DEFAULT after SET, got %s", p.curToken.Literal)
+		}
+	case p.curIdentIs("NO"):
+		if !p.peekIdentIs("ACTION") {
+			return "", fmt.Errorf("expected ACTION after NO, got %s", p.peekToken.Literal)
+		}
+		p.nextToken()
+		action = "NO ACTION"
+	default:
+		return "", fmt.Errorf("expected CASCADE, SET NULL, SET DEFAULT, NO ACTION or RESTRICT, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+	return action, nil
+}
+
+// Parse ALTER TABLE name followed by one action: ADD column | ADD constraint
+// | DROP COLUMN name | DROP CONSTRAINT name | ALTER COLUMN column
+func (p *Parser) parseAlterTableStatement() (*AlterTableStatement, error) {
+	if !p.curTokenIs(lexer.ALTER) {
+		return nil, fmt.Errorf("expected ALTER, got %s", p.curToken.Literal)
+	}
+	start := p.pos()
+	if !p.expectPeek(lexer.TABLE) {
+		return nil, fmt.Errorf("unsupported ALTER statement: ALTER %s", p.peekToken.Literal)
+	}
+	p.nextToken()
+
+	stmt := &AlterTableStatement{}
+
+	table, err := p.parseTableName()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Table = *table
+
+	switch {
+	case p.curIdentIs("ADD"):
+		p.nextToken()
+		if p.isTableConstraintStart() {
+			constraint, err := p.parseTableConstraint()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Action = "ADD CONSTRAINT"
+			stmt.Constraint = constraint
+			break
+		}
+		// COLUMN is optional (and not allowed by SQL Server)
+		if p.curIdentIs("COLUMN") {
+			p.nextToken()
+		}
+		column, err := p.parseColumnDefinition()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Action = "ADD COLUMN"
+		stmt.Column = column
+	case p.curTokenIs(lexer.DROP):
+		p.nextToken()
+		switch {
+		case p.curIdentIs("COLUMN"):
+			stmt.Action = "DROP COLUMN"
+		case p.curIdentIs("CONSTRAINT"):
+			stmt.Action = "DROP CONSTRAINT"
+		default:
+			return nil, fmt.Errorf("expected COLUMN or CONSTRAINT after DROP, got %s", p.curToken.Literal)
+		}
+		if !p.expectPeek(lexer.IDENT) {
+			return nil, fmt.Errorf("expected name after %s, got %s", stmt.Action, p.peekToken.Literal)
+		}
+		stmt.Name = p.curToken.Literal
+		p.nextToken()
+	case p.curTokenIs(lexer.ALTER):
+		p.nextToken()
+		if !p.curIdentIs("COLUMN") {
+			return nil, fmt.Errorf("expected COLUMN after ALTER, got %s", p.curToken.Literal)
+		}
+		p.nextToken()
+		column, err := p.parseColumnDefinition()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Action = "ALTER COLUMN"
+		stmt.Column = column
+	default:
+		return nil, fmt.Errorf("expected ADD, DROP or ALTER COLUMN in ALTER TABLE, got %s", p.curToken.Literal)
+	}
+
+	p.finish(stmt, start)
+	return stmt, nil
+}
+
+// Parse DROP {TABLE | VIEW | INDEX | PROCEDURE} [IF EXISTS] name [, name ...] [ON table]
+func (p *Parser) parseDropStatement() (*DropStatement, error) {
+	if !p.curTokenIs(lexer.DROP) {
+		return nil, fmt.Errorf("expected DROP, got %s", p.curToken.Literal)
+	}
+	start := p.pos()
+	p.nextToken()
+
+	stmt := &DropStatement{}
+
+	switch {
+	case p.curTokenIs(lexer.TABLE):
+		stmt.ObjectType = "TABLE"
+	case p.curIdentIs("VIEW"):
+		stmt.ObjectType = "VIEW"
+	case p.curIdentIs("INDEX"):
+		stmt.ObjectType = "INDEX"
+	case p.curIdentIs("PROCEDURE"), p.curIdentIs("PROC"):
+		stmt.ObjectType = "PROCEDURE"
+	default:
+		return nil, fmt.Errorf("unsupported DROP statement: DROP %s", p.curToken.Literal)
+	}
+	p.nextToken()
+
+	if p.curIdentIs("IF") {
+		if !p.expectPeek(lexer.EXISTS) {
+			return nil, fmt.Errorf("expected EXISTS after IF, got %s", p.peekToken.Literal)
+		}
+		stmt.IfExists = true
+		p.nextToken()
+	}
+
+	for {
+		name, err := p.parseTableName()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Objects = append(stmt.Objects, *name)
+
+		if !p.curTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	// SQL Server: DROP INDEX name ON table
+	if stmt.ObjectType == "INDEX" && p.curTokenIs(lexer.ON) {
+		p.nextToken()
+		table, err := p.parseTableName()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Table = table
+	}
+
+	p.finish(stmt, start)
+	return stmt, nil
+}
+
+// Parse GRANT | DENY | REVOKE [GRANT OPTION FOR] permissions
+// [ON [class::]object] {TO | FROM} principals [WITH GRANT OPTION] [CASCADE]
+func (p *Parser) parsePermissionStatement() (*PermissionStatement, error) {
+	if !p.curTokenIs(lexer.GRANT) && !p.curTokenIs(lexer.DENY) && !p.curTokenIs(lexer.REVOKE) {
+		return nil, fmt.Errorf("expected GRANT, DENY or REVOKE, got %s", p.curToken.Literal)
+	}
+	start := p.pos()
+	stmt := &PermissionStatement{Action: strings.ToUpper(p.curToken.Literal)}
+	p.nextToken()
+
+	if stmt.Action == "REVOKE" && p.curTokenIs(lexer.GRANT) && p.peekIdentIs("OPTION") {
+		p.nextToken()
+		p.nextToken()
+		if !p.curIdentIs("FOR") {
+			return nil, fmt.Errorf("expected FOR after GRANT OPTION, got %s", p.curToken.Literal)
+		}
+		stmt.GrantOption = true
+		p.nextToken()
+	}
+
+	for {
+		permission, err := p.parsePermission()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Permissions = append(stmt.Permissions, permission)
+
+		if !p.curTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if p.curTokenIs(lexer.ON) {
+		p.nextToken()
+		if p.curTokenIs(lexer.IDENT) && p.peekTokenIs(lexer.DOUBLE_COLON) {
+			stmt.ObjectClass = strings.ToUpper(p.curToken.Literal)
+			p.nextToken()
+			p.nextToken()
+		}
+		object, err := p.parseTableName()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Object = object
+	}
+
+	if !p.curIdentIs("TO") && !(stmt.Action == "REVOKE" && p.curTokenIs(lexer.FROM)) {
+		return nil, fmt.Errorf("expected TO, got %s", p.curToken.Literal)
+	}
+	p.nextToken()
+
+	for {
+		if !p.curTokenIs(lexer.IDENT) {
+			return nil, fmt.Errorf("expected principal name, got %s", p.curToken.Literal)
+		}
+		stmt.Principals = append(stmt.Principals, p.curToken.Literal)
+		p.nextToken()
+
+		if !p.curTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if stmt.Action == "GRANT" && p.curTokenIs(lexer.WITH) {
+		if !p.expectPeek(lexer.GRANT) || !p.peekIdentIs("OPTION") {
+			return nil, fmt.Errorf("expected GRANT OPTION after WITH, got %s", p.peekToken.Literal)
+		}
+		stmt.GrantOption = true
+		p.nextToken()
+		p.nextToken()
+	}
+
+	if stmt.Action != "GRANT" && p.curIdentIs("CASCADE") {
+		stmt.Cascade = true
+		p.nextToken()
+	}
+
+	p.finish(stmt, start)
+	return stmt, nil
+}
+
+// parsePermission parses a permission name of one or more words, such as
+// SELECT, VIEW DEFINITION or ALTER ANY USER
+func (p *Parser) parsePermission() (string, error) {
+	var words []string
+	for !p.curTokenIs(lexer.ON) && !p.curTokenIs(lexer.FROM) && !p.curIdentIs("TO") && p.curTokenIsWord() {
+		words = append(words, strings.ToUpper(p.curToken.Literal))
+		p.nextToken()
+	}
+	if len(words) == 0 {
+		return "", fmt.Errorf("expected permission, got %s", p.curToken.Literal)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// curTokenIsWord reports whether the current token is an identifier or a
+// keyword
+func (p *Parser) curTokenIsWord() bool {
+	return p.curTokenIs(lexer.IDENT) || lexer.LookupIdent(strings.ToUpper(p.curToken.Literal)) != lexer.IDENT
 }
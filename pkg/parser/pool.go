@@ -42,15 +42,22 @@ var (
 func GetSelectStatement() *SelectStatement {
 	stmt := selectStatementPool.Get().(*SelectStatement)
 	// Reset the statement
+	stmt.BaseNode = BaseNode{}
+	stmt.With = nil
 	stmt.Distinct = false
 	stmt.Top = nil
 	stmt.Columns = stmt.Columns[:0]
+	stmt.Into = nil
 	stmt.From = nil
 	stmt.Joins = stmt.Joins[:0]
 	stmt.Where = nil
 	stmt.GroupBy = nil
 	stmt.Having = nil
 	stmt.OrderBy = nil
+	stmt.Limit = nil
+	stmt.Offset = nil
+	stmt.For = nil
+	stmt.Option = nil
 	return stmt
 }
 
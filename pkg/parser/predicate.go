@@ -0,0 +1,19 @@
+package parser
+
+import "strings"
+
+// ToConjunctiveList splits a condition on its top-level ANDs into the
+// predicates that must all hold, in source order. Parenthesized ANDs are
+// flattened too, as (a AND b) AND c parses to the same tree as a AND b AND c.
+// OR expressions and anything under NOT are kept as single predicates, e.g.
+// a = 1 AND (b = 2 OR c = 3) gives [a = 1, b = 2 OR c = 3]. A nil condition
+// gives an empty list.
+func ToConjunctiveList(expr Expression) []Expression {
+	if expr == nil {
+		return nil
+	}
+	if be, ok := expr.(*BinaryExpression); ok && strings.EqualFold(be.Operator, "AND") {
+		return append(ToConjunctiveList(be.Left), ToConjunctiveList(be.Right)...)
+	}
+	return []Expression{expr}
+}
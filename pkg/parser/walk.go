@@ -0,0 +1,453 @@
+package parser
+
+// Visitor is called by Walk for each node in the AST.
+// If Visit returns a non-nil visitor w, Walk visits each child of the node
+// with w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order, starting with node
+func Walk(node Node, v Visitor) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		for _, stmt := range n.Statements {
+			Walk(stmt, v)
+		}
+
+	case *SelectStatement:
+		if n.With != nil {
+			Walk(n.With, v)
+		}
+		if n.Top != nil {
+			Walk(n.Top, v)
+		}
+		walkExpressions(n.Columns, v)
+		if n.Into != nil {
+			Walk(n.Into, v)
+		}
+		if n.From != nil {
+			Walk(n.From, v)
+		}
+		for _, join := range n.Joins {
+			Walk(join, v)
+		}
+		if n.Where != nil {
+			Walk(n.Where, v)
+		}
+		if n.GroupBy != nil {
+			Walk(n.GroupBy, v)
+		}
+		if n.Having != nil {
+			Walk(n.Having, v)
+		}
+		for _, item := range n.OrderBy {
+			Walk(item, v)
+		}
+		if n.Limit != nil {
+			Walk(n.Limit, v)
+		}
+		if n.Offset != nil {
+			Walk(n.Offset, v)
+		}
+		if n.For != nil {
+			Walk(n.For, v)
+		}
+		if n.Option != nil {
+			Walk(n.Option, v)
+		}
+
+	case *SetOperation:
+		if n.With != nil {
+			Walk(n.With, v)
+		}
+		Walk(n.Left, v)
+		Walk(n.Right, v)
+
+	case *WithClause:
+		for _, cte := range n.CTEs {
+			Walk(cte, v)
+		}
+
+	case *CommonTableExpression:
+		Walk(n.Query, v)
+
+	case *FromClause:
+		for i := range n.Tables {
+			Walk(&n.Tables[i], v)
+		}
+
+	case *TableReference:
+		if n.Function != nil {
+			Walk(n.Function, v)
+		}
+		if n.Subquery != nil {
+			Walk(n.Subquery, v)
+		}
+		if n.Values != nil {
+			Walk(n.Values, v)
+		}
+		if n.TableSample != nil {
+			Walk(n.TableSample, v)
+		}
+		if n.Pivot != nil {
+			Walk(n.Pivot, v)
+		}
+		if n.Unpivot != nil {
+			Walk(n.Unpivot, v)
+		}
+
+	case *ValuesClause:
+		for _, row := range n.Rows {
+			walkExpressions(row, v)
+		}
+
+	case *PivotClause:
+		Walk(n.Aggregate, v)
+		Walk(n.Column, v)
+
+	case *TableSample:
+		Walk(n.Size, v)
+		if n.Repeatable != nil {
+			Walk(n.Repeatable, v)
+		}
+
+	case *JoinClause:
+		Walk(&n.Table, v)
+		if n.Condition != nil {
+			Walk(n.Condition, v)
+		}
+
+	case *BinaryExpression:
+		Walk(n.Left, v)
+		Walk(n.Right, v)
+
+	case *UnaryExpression:
+		Walk(n.Operand, v)
+
+	case *FunctionCall:
+		walkExpressions(n.Arguments, v)
+		if n.Over != nil {
+			Walk(n.Over, v)
+		}
+
+	case *WindowSpec:
+		walkExpressions(n.PartitionBy, v)
+		for _, item := range n.OrderBy {
+			Walk(item, v)
+		}
+
+	case *AliasedExpression:
+		Walk(n.Expression, v)
+
+	case *CastExpression:
+		Walk(n.Expression, v)
+		if n.DataType != nil {
+			Walk(n.DataType, v)
+		}
+		if n.Style != nil {
+			Walk(n.Style, v)
+		}
+
+	case *GroupByClause:
+		walkExpressions(n.Items, v)
+
+	case *RollupExpression:
+		for _, group := range n.Groups {
+			walkExpressions(group, v)
+		}
+
+	case *CubeExpression:
+		for _, group := range n.Groups {
+			walkExpressions(group, v)
+		}
+
+	case *GroupingSetsExpression:
+		for _, set := range n.Sets {
+			walkExpressions(set, v)
+		}
+
+	case *OrderByClause:
+		Walk(n.Expression, v)
+
+	case *InsertStatement:
+		if n.With != nil {
+			Walk(n.With, v)
+		}
+		Walk(&n.Table, v)
+		if n.Output != nil {
+			Walk(n.Output, v)
+		}
+		for _, row := range n.Values {
+			walkExpressions(row, v)
+		}
+		if n.Source != nil {
+			Walk(n.Source, v)
+		}
+		if n.Option != nil {
+			Walk(n.Option, v)
+		}
+
+	case *UpdateStatement:
+		if n.With != nil {
+			Walk(n.With, v)
+		}
+		Walk(&n.Table, v)
+		for _, assignment := range n.Set {
+			Walk(assignment, v)
+		}
+		if n.Output != nil {
+			Walk(n.Output, v)
+		}
+		if n.From != nil {
+			Walk(n.From, v)
+		}
+		for _, join := range n.Joins {
+			Walk(join, v)
+		}
+		if n.Where != nil {
+			Walk(n.Where, v)
+		}
+		if n.Option != nil {
+			Walk(n.Option, v)
+		}
+
+	case *Assignment:
+		if n.Column != nil {
+			Walk(n.Column, v)
+		}
+		Walk(n.Value, v)
+
+	case *DeleteStatement:
+		if n.With != nil {
+			Walk(n.With, v)
+		}
+		if n.Top != nil {
+			Walk(n.Top, v)
+		}
+		Walk(&n.From, v)
+		if n.Output != nil {
+			Walk(n.Output, v)
+		}
+		if n.Where != nil {
+			Walk(n.Where, v)
+		}
+		if n.Option != nil {
+			Walk(n.Option, v)
+		}
+
+	case *OutputClause:
+		walkExpressions(n.Columns, v)
+		if n.Into != nil {
+			Walk(n.Into, v)
+		}
+
+	case *ForClause:
+		if n.ElementName != nil {
+			Walk(n.ElementName, v)
+		}
+		for _, option := range n.Options {
+			Walk(option, v)
+		}
+
+	case *ForOption:
+		if n.Argument != nil {
+			Walk(n.Argument, v)
+		}
+
+	case *OptionClause:
+		for _, hint := range n.Hints {
+			Walk(hint, v)
+		}
+
+	case *QueryHint:
+		if n.Value != nil {
+			Walk(n.Value, v)
+		}
+		walkExpressions(n.Arguments, v)
+		for _, variable := range n.Variables {
+			Walk(variable, v)
+		}
+
+	case *OptimizeForVariable:
+		if n.Value != nil {
+			Walk(n.Value, v)
+		}
+
+	case *MergeStatement:
+		if n.With != nil {
+			Walk(n.With, v)
+		}
+		Walk(&n.Target, v)
+		Walk(&n.Source, v)
+		Walk(n.Condition, v)
+		for _, clause := range n.Clauses {
+			Walk(clause, v)
+		}
+
+	case *MergeWhenClause:
+		if n.Condition != nil {
+			Walk(n.Condition, v)
+		}
+		for _, assignment := range n.Set {
+			Walk(assignment, v)
+		}
+		walkExpressions(n.Values, v)
+
+	case *CreateTableStatement:
+		Walk(&n.Table, v)
+		for _, column := range n.Columns {
+			Walk(column, v)
+		}
+		for _, constraint := range n.Constraints {
+			Walk(constraint, v)
+		}
+
+	case *AlterTableStatement:
+		Walk(&n.Table, v)
+		if n.Column != nil {
+			Walk(n.Column, v)
+		}
+		if n.Constraint != nil {
+			Walk(n.Constraint, v)
+		}
+
+	case *CreateIndexStatement:
+		Walk(&n.Table, v)
+		for _, column := range n.Columns {
+			Walk(column, v)
+		}
+		if n.Where != nil {
+			Walk(n.Where, v)
+		}
+
+	case *CreateViewStatement:
+		Walk(&n.View, v)
+		Walk(n.Query, v)
+
+	case *DropStatement:
+		for i := range n.Objects {
+			Walk(&n.Objects[i], v)
+		}
+		if n.Table != nil {
+			Walk(n.Table, v)
+		}
+
+	case *PermissionStatement:
+		if n.Object != nil {
+			Walk(n.Object, v)
+		}
+
+	case *ColumnDefinition:
+		if n.DataType != nil {
+			Walk(n.DataType, v)
+		}
+		if n.Default != nil {
+			Walk(n.Default, v)
+		}
+		if n.Identity != nil {
+			Walk(n.Identity, v)
+		}
+		for _, constraint := range n.Constraints {
+			Walk(constraint, v)
+		}
+
+	case *TableConstraint:
+		if n.Expr != nil {
+			Walk(n.Expr, v)
+		}
+		if n.References != nil {
+			Walk(n.References, v)
+		}
+
+	case *RowValueExpression:
+		walkExpressions(n.Values, v)
+
+	case *InExpression:
+		Walk(n.Expression, v)
+		walkExpressions(n.Values, v)
+		if n.Subquery != nil {
+			Walk(n.Subquery, v)
+		}
+
+	case *ExistsExpression:
+		Walk(n.Subquery, v)
+
+	case *SubqueryExpression:
+		Walk(n.Query, v)
+
+	case *BetweenExpression:
+		Walk(n.Expr, v)
+		Walk(n.Lower, v)
+		Walk(n.Upper, v)
+
+	case *LikeExpression:
+		Walk(n.Expr, v)
+		Walk(n.Pattern, v)
+		if n.Escape != nil {
+			Walk(n.Escape, v)
+		}
+
+	case *CollateExpression:
+		Walk(n.Expr, v)
+
+	case *FullTextPredicate:
+		for _, col := range n.Columns {
+			Walk(col, v)
+		}
+		Walk(n.Search, v)
+		if n.Language != nil {
+			Walk(n.Language, v)
+		}
+
+	case *IsNullExpression:
+		Walk(n.Expr, v)
+
+	case *CaseExpression:
+		if n.Operand != nil {
+			Walk(n.Operand, v)
+		}
+		for _, when := range n.WhenClauses {
+			Walk(when, v)
+		}
+		if n.Else != nil {
+			Walk(n.Else, v)
+		}
+
+	case *WhenClause:
+		Walk(n.Condition, v)
+		Walk(n.Result, v)
+
+	case *ColumnReference, *Literal, *Parameter, *SystemVariable, *DatePart, *CurrentOfClause, *OrdinalReference, *StarExpression, *DataType, *IndexColumn,
+		*IdentitySpec, *TopClause, *LimitClause, *OffsetFetchClause:
+		// leaf nodes
+	}
+
+	v.Visit(nil)
+}
+
+func walkExpressions(exprs []Expression, v Visitor) {
+	for _, expr := range exprs {
+		Walk(expr, v)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order, calling f for each node.
+// If f returns false, the children of that node are skipped. After all
+// children are visited, f is called with nil.
+func Inspect(node Node, f func(Node) bool) {
+	Walk(node, inspector(f))
+}
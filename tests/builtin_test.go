@@ -0,0 +1,205 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/analyzer"
+	"github.com/Chahine-tech/sql-parser-go/pkg/dialect"
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+func TestVariadicBuiltinFunctions(t *testing.T) {
+	tests := []struct {
+		sql  string
+		name string
+		args int
+	}{
+		{"SELECT CONCAT(first, ' ', last, suffix) FROM t", "CONCAT", 4},
+		{"SELECT ISNULL(nickname, name) FROM t", "ISNULL", 2},
+		{"SELECT COALESCE(a, b, c, 0) FROM t", "COALESCE", 4},
+		{"SELECT LEFT(name, 3) FROM t", "LEFT", 2},
+		{"SELECT RIGHT(name, 3) FROM t", "RIGHT", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			call, ok := stmt.Columns[0].(*parser.FunctionCall)
+			if !ok {
+				t.Fatalf("expected FunctionCall, got %T", stmt.Columns[0])
+			}
+			if !strings.EqualFold(call.Name, tt.name) || len(call.Arguments) != tt.args {
+				t.Errorf("expected %s with %d arguments, got %s with %d", tt.name, tt.args, call.Name, len(call.Arguments))
+			}
+			if got := stmt.String(); got != tt.sql {
+				t.Errorf("expected %q, got %q", tt.sql, got)
+			}
+		})
+	}
+}
+
+func TestDatePartArguments(t *testing.T) {
+	tests := []struct {
+		sql  string
+		name string
+		part string
+		args int
+	}{
+		{"SELECT DATEADD(day, 7, order_date) FROM t", "day", "DAY", 3},
+		{"SELECT DATEDIFF(mm, start_date, end_date) FROM t", "mm", "MONTH", 3},
+		{"SELECT datepart(YYYY, created) FROM t", "YYYY", "YEAR", 2},
+		{"SELECT DATENAME(dw, created) FROM t", "dw", "WEEKDAY", 2},
+		{"SELECT DATEDIFF_BIG(mcs, a, b) FROM t", "mcs", "MICROSECOND", 3},
+		{"SELECT DATETRUNC(iso_week, created) FROM t", "iso_week", "ISO_WEEK", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			call := stmt.Columns[0].(*parser.FunctionCall)
+			if len(call.Arguments) != tt.args {
+				t.Fatalf("expected %d arguments, got %d", tt.args, len(call.Arguments))
+			}
+			part, ok := call.Arguments[0].(*parser.DatePart)
+			if !ok {
+				t.Fatalf("expected DatePart, got %T", call.Arguments[0])
+			}
+			if part.Name != tt.name || part.Part() != tt.part {
+				t.Errorf("expected %s (%s), got %s (%s)", tt.name, tt.part, part.Name, part.Part())
+			}
+			if got := stmt.String(); got != tt.sql {
+				t.Errorf("expected %q, got %q", tt.sql, got)
+			}
+
+			// The date part is not a column of the query
+			analysis := analyzer.New().Analyze(stmt)
+			for _, col := range analysis.Columns {
+				if col.Name == tt.name {
+					t.Errorf("date part %s reported as a column", tt.name)
+				}
+			}
+		})
+	}
+
+	for _, sql := range []string{
+		"SELECT DATEADD(fortnight, 1, d) FROM t",
+		"SELECT DATEADD('day', 1, d) FROM t",
+		"SELECT DATEDIFF(@part, a, b) FROM t",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected an error for %q", sql)
+		}
+	}
+
+	// Other dialects parse the first argument as an expression
+	stmt, err := parser.NewWithDialect(context.Background(), "SELECT DATEDIFF(day, created) FROM t", dialect.GetDialect("mysql")).ParseStatement()
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := stmt.(*parser.SelectStatement).Columns[0].(*parser.FunctionCall)
+	if _, ok := call.Arguments[0].(*parser.ColumnReference); !ok {
+		t.Errorf("expected ColumnReference, got %T", call.Arguments[0])
+	}
+}
+
+func TestLookupBuiltinFunction(t *testing.T) {
+	sqlServer := dialect.GetDialect("sqlserver")
+	mysql := dialect.GetDialect("mysql")
+
+	tests := []struct {
+		name     string
+		d        dialect.Dialect
+		found    bool
+		min, max int
+	}{
+		{"isnull", sqlServer, true, 2, 2},
+		{"ISNULL", mysql, true, 1, 1},
+		{"ISNULL", nil, false, 0, 0},
+		{"coalesce", nil, true, 1, -1},
+		{"COALESCE", sqlServer, true, 2, -1},
+		{"Concat", mysql, true, 1, -1},
+		{"my_udf", sqlServer, false, 0, 0},
+	}
+
+	for _, tt := range tests {
+		f, ok := parser.LookupBuiltinFunction(tt.name, tt.d)
+		if ok != tt.found || f.MinArgs != tt.min || f.MaxArgs != tt.max {
+			t.Errorf("%s: expected %v %d..%d, got %v %+v", tt.name, tt.found, tt.min, tt.max, ok, f)
+		}
+	}
+}
+
+func TestIsWindowOnlyFunction(t *testing.T) {
+	for _, name := range []string{"ROW_NUMBER", "rank", "Dense_Rank", "LAG", "ntile"} {
+		if !parser.IsWindowOnlyFunction(name) {
+			t.Errorf("expected %s to be window-only", name)
+		}
+	}
+	for _, name := range []string{"COUNT", "SUM", "my_udf", ""} {
+		if parser.IsWindowOnlyFunction(name) {
+			t.Errorf("expected %s not to be window-only", name)
+		}
+	}
+}
+
+func TestValidateFunctionCalls(t *testing.T) {
+	tests := []struct {
+		sql     string
+		dialect string
+		errors  []string
+	}{
+		{"SELECT ISNULL(a, b), COALESCE(a, b, c), CONCAT(a, b, c, d) FROM t", "sqlserver", nil},
+		{"SELECT ISNULL(a, b, c) FROM t", "sqlserver", []string{"ISNULL takes 2 arguments, got 3"}},
+		{"SELECT ISNULL(a) FROM t", "mysql", nil},
+		{"SELECT ISNULL(a, b) FROM t", "mysql", []string{"ISNULL takes 1 argument, got 2"}},
+		{"SELECT COALESCE(a) FROM t", "sqlserver", []string{"COALESCE takes at least 2 arguments, got 1"}},
+		{"SELECT COALESCE(a) FROM t", "postgresql", nil},
+		{"SELECT ROUND(a) FROM t", "sqlserver", []string{"ROUND takes 2 to 3 arguments, got 1"}},
+		{"SELECT CONCAT() FROM t", "postgresql", []string{"CONCAT takes at least 1 argument, got 0"}},
+		{"SELECT my_udf(a, b, c) FROM t", "sqlserver", nil},
+		// Window-only functions need OVER
+		{"SELECT ROW_NUMBER() OVER (ORDER BY a), RANK() OVER (PARTITION BY b ORDER BY a) FROM t", "sqlserver", nil},
+		{"SELECT row_number() FROM t", "sqlserver", []string{"ROW_NUMBER requires an OVER clause"}},
+		{"SELECT a FROM t ORDER BY DENSE_RANK(), CUME_DIST()", "postgresql", []string{
+			"DENSE_RANK requires an OVER clause",
+			"CUME_DIST requires an OVER clause",
+		}},
+		{"SELECT RANK(a) FROM t", "sqlserver", []string{
+			"RANK takes 0 arguments, got 1",
+			"RANK requires an OVER clause",
+		}},
+		// Calls are checked throughout the statement
+		{"SELECT a FROM t WHERE NULLIF(a) = 1 AND b IN (SELECT LEFT(c) FROM s)", "sqlserver", []string{
+			"NULLIF takes 2 arguments, got 1",
+			"LEFT takes 2 arguments, got 1",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			d := dialect.GetDialect(tt.dialect)
+			stmt, err := parser.NewWithDialect(context.Background(), tt.sql, d).ParseStatement()
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+
+			err = parser.ValidateFunctionCalls(stmt, d)
+			var got []string
+			if err != nil {
+				for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+					var parseErr *parser.ParseError
+					if !errors.As(e, &parseErr) {
+						t.Fatalf("expected *ParseError, got %T", e)
+					}
+					got = append(got, parseErr.Message)
+				}
+			}
+			if strings.Join(got, "; ") != strings.Join(tt.errors, "; ") {
+				t.Errorf("expected %q, got %q", tt.errors, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+// nodePointers collects every node reachable from root
+func nodePointers(root parser.Node) map[parser.Node]bool {
+	nodes := make(map[parser.Node]bool)
+	parser.Inspect(root, func(node parser.Node) bool {
+		if node != nil {
+			nodes[node] = true
+		}
+		return true
+	})
+	return nodes
+}
+
+func TestCloneStatements(t *testing.T) {
+	tests := []string{
+		"WITH recent AS (SELECT user_id FROM orders WHERE created > @since) SELECT DISTINCT TOP 10 u.id, COUNT(*) AS n FROM users u JOIN recent r ON r.user_id = u.id WHERE u.flags & 0x0F = 1 GROUP BY u.id HAVING COUNT(*) > 2 ORDER BY 2 DESC",
+		"SELECT id FROM a UNION ALL SELECT id FROM b",
+		"INSERT INTO archive (id, name) SELECT id, name FROM users WHERE active = 0",
+		"UPDATE users SET score = CASE WHEN bonus IS NULL THEN 0 ELSE bonus END WHERE id IN (1, 2, 3)",
+		"DELETE FROM sessions WHERE expires < GETDATE()",
+		"MERGE INTO target t USING source s ON t.id = s.id WHEN MATCHED THEN UPDATE SET t.name = s.name WHEN NOT MATCHED THEN INSERT (id, name) VALUES (s.id, s.name)",
+		"CREATE TABLE users (id INT IDENTITY(1,1) PRIMARY KEY, name VARCHAR(50) NOT NULL)",
+		"ALTER TABLE users ADD COLUMN email VARCHAR(100)",
+		"CREATE VIEW active_users AS SELECT id FROM users WHERE active = 1",
+		"CREATE UNIQUE INDEX ix_users ON users (email DESC) INCLUDE (name) WHERE active = 1",
+		"DROP TABLE IF EXISTS users",
+	}
+
+	for _, sql := range tests {
+		t.Run(sql, func(t *testing.T) {
+			stmt := parseSQL(t, sql)
+			clone := stmt.Clone()
+
+			if clone.String() != stmt.String() {
+				t.Errorf("expected %q, got %q", stmt.String(), clone.String())
+			}
+			start, end := stmt.Span()
+			if cloneStart, cloneEnd := clone.Span(); cloneStart != start || cloneEnd != end {
+				t.Errorf("span not copied")
+			}
+
+			original := nodePointers(stmt)
+			for node := range nodePointers(clone) {
+				if original[node] {
+					t.Fatalf("clone shares %s node %s with the original", node.Type(), node)
+				}
+			}
+		})
+	}
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	sql := "SELECT u.id, u.name FROM users u JOIN orders o ON o.user_id = u.id WHERE u.id = 0x0A0B"
+	stmt := parseSQL(t, sql).(*parser.SelectStatement)
+	want := stmt.String()
+
+	clone := stmt.Clone().(*parser.SelectStatement)
+	clone.Columns[0].(*parser.ColumnReference).Column = "email"
+	clone.Columns = append(clone.Columns, &parser.StarExpression{})
+	clone.From.Tables[0].Name = "accounts"
+	clone.Joins[0].Condition.(*parser.BinaryExpression).Operator = ">"
+	where := clone.Where.(*parser.BinaryExpression)
+	where.Right.(*parser.Literal).Value.([]byte)[0] = 0xFF
+
+	if got := stmt.String(); got != want {
+		t.Errorf("mutating the clone changed the original: %q", got)
+	}
+}
+
+func TestCloneSurvivesPoolReuse(t *testing.T) {
+	stmt := parseSQL(t, "SELECT a, b FROM t JOIN s ON s.id = t.id WHERE a = 1").(*parser.SelectStatement)
+	clone := parser.CloneNode(stmt).(*parser.SelectStatement)
+	want := clone.String()
+
+	// Recycle the original's pooled nodes and reuse them
+	for _, join := range stmt.Joins {
+		parser.PutJoinClause(join)
+	}
+	parser.PutBinaryExpression(stmt.Where.(*parser.BinaryExpression))
+	parser.PutSelectStatement(stmt)
+	for i := 0; i < 10; i++ {
+		_ = parseSQL(t, "SELECT x FROM y JOIN z ON z.k = y.k WHERE x > 2")
+	}
+
+	if got := clone.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestCloneNode(t *testing.T) {
+	if parser.CloneNode(nil) != nil {
+		t.Error("expected nil clone of nil")
+	}
+
+	program, err := parser.New("SELECT 1; SELECT 2").ParseProgram()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := parser.CloneNode(program).(*parser.Program)
+	if clone == program || clone.Statements[0] == program.Statements[0] || clone.String() != program.String() {
+		t.Error("expected an independent copy of the program")
+	}
+}
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/analyzer"
+)
+
+func TestComplexityScore(t *testing.T) {
+	sql := `SELECT u.name,
+			CASE WHEN u.age > 60 THEN 'senior' ELSE CASE WHEN u.age < 18 THEN 'minor' ELSE 'adult' END END,
+			COUNT(o.id)
+		FROM users u
+		JOIN orders o ON o.user_id = u.id
+		LEFT JOIN (SELECT user_id FROM bans) b ON b.user_id = u.id
+		WHERE (u.active = 1 AND u.verified = 1) OR u.id IN (SELECT user_id FROM admins WHERE level > 2)
+		GROUP BY u.name
+		HAVING COUNT(o.id) > 5`
+
+	total, breakdown := analyzer.ComplexityScore(parseSQL(t, sql))
+
+	want := map[string]int{
+		analyzer.ComplexityJoins:          4, // two joins
+		analyzer.ComplexitySubqueries:     6, // derived table and IN subquery
+		analyzer.ComplexitySetOperations:  0,
+		analyzer.ComplexityCaseExpression: 3, // outer CASE 1, nested CASE 2
+		analyzer.ComplexityFunctionCalls:  2, // COUNT twice
+		analyzer.ComplexityPredicateDepth: 5, // WHERE 3, HAVING 1, subquery WHERE 1
+	}
+	if !reflect.DeepEqual(breakdown, want) {
+		t.Errorf("unexpected breakdown:\n got %v\nwant %v", breakdown, want)
+	}
+	if total != 20 {
+		t.Errorf("expected total 20, got %d", total)
+	}
+}
+
+func TestComplexityScoreSetOperations(t *testing.T) {
+	total, breakdown := analyzer.ComplexityScore(parseSQL(t, "SELECT id FROM a UNION SELECT id FROM b UNION ALL SELECT id FROM c"))
+
+	// Branches of a set operation are not subqueries
+	if breakdown[analyzer.ComplexitySubqueries] != 0 {
+		t.Errorf("expected no subqueries, got %d", breakdown[analyzer.ComplexitySubqueries])
+	}
+	if breakdown[analyzer.ComplexitySetOperations] != 4 || total != 4 {
+		t.Errorf("expected 4 points for two set operations, got %v (total %d)", breakdown, total)
+	}
+
+	total, _ = analyzer.ComplexityScore(parseSQL(t, "SELECT id FROM users"))
+	if total != 0 {
+		t.Errorf("expected a simple query to score 0, got %d", total)
+	}
+}
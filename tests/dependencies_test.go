@@ -0,0 +1,252 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/analyzer"
+)
+
+func TestExtractDependenciesResolvesAliases(t *testing.T) {
+	sql := `SELECT u.name, o.total FROM dbo.users u
+		JOIN sales.orders o ON o.user_id = u.id
+		WHERE u.id IN (SELECT user_id FROM blacklist) AND EXISTS (SELECT 1 FROM audit a WHERE a.user_id = u.id)`
+
+	deps := analyzer.ExtractDependencies(parseSQL(t, sql))
+
+	wantTables := []analyzer.TableDependency{
+		{Schema: "dbo", Name: "users"},
+		{Schema: "sales", Name: "orders"},
+		{Name: "blacklist"},
+		{Name: "audit"},
+	}
+	if !reflect.DeepEqual(deps.Tables, wantTables) {
+		t.Errorf("unexpected tables:\n got %+v\nwant %+v", deps.Tables, wantTables)
+	}
+
+	wantColumns := []analyzer.ColumnDependency{
+		{Schema: "dbo", Table: "users", Column: "name", Kind: analyzer.SourceTable},
+		{Schema: "sales", Table: "orders", Column: "total", Kind: analyzer.SourceTable},
+		{Schema: "sales", Table: "orders", Column: "user_id", Kind: analyzer.SourceTable},
+		{Schema: "dbo", Table: "users", Column: "id", Kind: analyzer.SourceTable},
+		{Table: "blacklist", Column: "user_id", Kind: analyzer.SourceTable},
+		{Table: "audit", Column: "user_id", Kind: analyzer.SourceTable},
+	}
+	if !reflect.DeepEqual(deps.Columns, wantColumns) {
+		t.Errorf("unexpected columns:\n got %+v\nwant %+v", deps.Columns, wantColumns)
+	}
+}
+
+func TestExtractDependenciesCTEsAndDerivedTables(t *testing.T) {
+	sql := `WITH recent AS (SELECT id, user_id FROM orders)
+		SELECT r.id, d.cnt FROM recent r
+		JOIN (SELECT user_id, COUNT(*) AS cnt FROM logins GROUP BY user_id) AS d ON d.user_id = r.user_id`
+
+	deps := analyzer.ExtractDependencies(parseSQL(t, sql))
+
+	wantTables := []analyzer.TableDependency{{Name: "orders"}, {Name: "logins"}}
+	if !reflect.DeepEqual(deps.Tables, wantTables) {
+		t.Errorf("unexpected tables: %+v", deps.Tables)
+	}
+	if !reflect.DeepEqual(deps.CTEs, []string{"recent"}) {
+		t.Errorf("unexpected CTEs: %v", deps.CTEs)
+	}
+	if !reflect.DeepEqual(deps.DerivedTables, []string{"d"}) {
+		t.Errorf("unexpected derived tables: %v", deps.DerivedTables)
+	}
+
+	want := map[string]analyzer.SourceKind{"recent.id": analyzer.SourceCTE, "d.cnt": analyzer.SourceDerived}
+	for _, col := range deps.Columns {
+		if kind, ok := want[col.Table+"."+col.Column]; ok && col.Kind != kind {
+			t.Errorf("expected %s.%s to be %s, got %s", col.Table, col.Column, kind, col.Kind)
+		}
+	}
+}
+
+func TestExtractDependenciesCTEInDerivedTable(t *testing.T) {
+	sql := "WITH c AS (SELECT 1 AS x) SELECT d.x FROM (SELECT x FROM c) AS d"
+
+	deps := analyzer.ExtractDependencies(parseSQL(t, sql))
+	if len(deps.Tables) != 0 {
+		t.Errorf("expected no physical tables, got %+v", deps.Tables)
+	}
+	for _, col := range deps.Columns {
+		if col.Table == "c" && col.Kind != analyzer.SourceCTE {
+			t.Errorf("expected c.%s to come from the CTE, got %s", col.Column, col.Kind)
+		}
+	}
+}
+
+func TestExtractDependenciesValuesTable(t *testing.T) {
+	sql := "SELECT u.name, v.label FROM users u JOIN (VALUES (1, 'one'), (2, 'two')) AS v(id, label) ON v.id = u.level"
+
+	deps := analyzer.ExtractDependencies(parseSQL(t, sql))
+	if !reflect.DeepEqual(deps.Tables, []analyzer.TableDependency{{Name: "users"}}) {
+		t.Errorf("unexpected tables: %+v", deps.Tables)
+	}
+	if !reflect.DeepEqual(deps.DerivedTables, []string{"v"}) {
+		t.Errorf("unexpected derived tables: %v", deps.DerivedTables)
+	}
+	for _, col := range deps.Columns {
+		if col.Table == "v" && col.Kind != analyzer.SourceDerived {
+			t.Errorf("expected v.%s to be derived, got %s", col.Column, col.Kind)
+		}
+	}
+}
+
+func TestExtractDependenciesModificationStatements(t *testing.T) {
+	deps := analyzer.ExtractDependencies(parseSQL(t, "UPDATE u SET name = s.name FROM users u JOIN staging s ON s.id = u.id"))
+
+	wantTables := []analyzer.TableDependency{{Name: "users"}, {Name: "staging"}}
+	if !reflect.DeepEqual(deps.Tables, wantTables) {
+		t.Errorf("unexpected tables: %+v", deps.Tables)
+	}
+	if deps.Columns[0] != (analyzer.ColumnDependency{Table: "users", Column: "name", Kind: analyzer.SourceTable}) {
+		t.Errorf("expected SET column to resolve to users, got %+v", deps.Columns[0])
+	}
+
+	deps = analyzer.ExtractDependencies(parseSQL(t, "DELETE FROM logs WHERE created < 10"))
+	if len(deps.Columns) != 1 || deps.Columns[0].Table != "logs" {
+		t.Errorf("expected unqualified column to resolve to logs, got %+v", deps.Columns)
+	}
+}
+
+func TestExtractDependenciesCrossDatabase(t *testing.T) {
+	sql := `SELECT o.id FROM sales.dbo.orders o JOIN archive..orders a ON a.id = o.id JOIN srv2.hr.dbo.staff s ON s.id = o.staff_id`
+
+	deps := analyzer.ExtractDependencies(parseSQL(t, sql))
+
+	wantTables := []analyzer.TableDependency{
+		{Database: "sales", Schema: "dbo", Name: "orders"},
+		{Database: "archive", Name: "orders"},
+		{Server: "srv2", Database: "hr", Schema: "dbo", Name: "staff"},
+	}
+	if !reflect.DeepEqual(deps.Tables, wantTables) {
+		t.Errorf("unexpected tables:\n got %+v\nwant %+v", deps.Tables, wantTables)
+	}
+}
+
+func TestExtractDependenciesCreateView(t *testing.T) {
+	sql := `CREATE VIEW dbo.order_totals AS SELECT c.name, SUM(o.amount) AS total FROM customers c JOIN orders o ON o.customer_id = c.id GROUP BY c.name`
+
+	deps := analyzer.ExtractDependencies(parseSQL(t, sql))
+
+	wantTables := []analyzer.TableDependency{{Name: "customers"}, {Name: "orders"}}
+	if !reflect.DeepEqual(deps.Tables, wantTables) {
+		t.Errorf("unexpected tables: %+v", deps.Tables)
+	}
+}
+
+func TestExtractDependenciesCreateIndex(t *testing.T) {
+	sql := "CREATE INDEX ix_orders ON dbo.orders (customer_id) INCLUDE (total) WHERE status = 'open'"
+
+	deps := analyzer.ExtractDependencies(parseSQL(t, sql))
+
+	wantColumns := []analyzer.ColumnDependency{
+		{Schema: "dbo", Table: "orders", Column: "customer_id", Kind: analyzer.SourceTable},
+		{Schema: "dbo", Table: "orders", Column: "total", Kind: analyzer.SourceTable},
+		{Schema: "dbo", Table: "orders", Column: "status", Kind: analyzer.SourceTable},
+	}
+	if !reflect.DeepEqual(deps.Columns, wantColumns) {
+		t.Errorf("unexpected columns:\n got %+v\nwant %+v", deps.Columns, wantColumns)
+	}
+}
+
+func TestExtractDependenciesOutputClause(t *testing.T) {
+	sql := "UPDATE dbo.users SET name = 'x' OUTPUT deleted.name, inserted.email INTO audit (old_name, email) WHERE id = 1"
+
+	deps := analyzer.ExtractDependencies(parseSQL(t, sql))
+
+	wantTables := []analyzer.TableDependency{
+		{Schema: "dbo", Name: "users"},
+		{Name: "audit"},
+	}
+	if !reflect.DeepEqual(deps.Tables, wantTables) {
+		t.Errorf("unexpected tables:\n got %+v\nwant %+v", deps.Tables, wantTables)
+	}
+
+	// inserted and deleted resolve to the UPDATE target
+	wantColumns := []analyzer.ColumnDependency{
+		{Schema: "dbo", Table: "users", Column: "name", Kind: analyzer.SourceTable},
+		{Schema: "dbo", Table: "users", Column: "email", Kind: analyzer.SourceTable},
+		{Table: "audit", Column: "old_name", Kind: analyzer.SourceTable},
+		{Table: "audit", Column: "email", Kind: analyzer.SourceTable},
+		{Schema: "dbo", Table: "users", Column: "id", Kind: analyzer.SourceTable},
+	}
+	if !reflect.DeepEqual(deps.Columns, wantColumns) {
+		t.Errorf("unexpected columns:\n got %+v\nwant %+v", deps.Columns, wantColumns)
+	}
+}
+
+func TestExtractDependenciesPivot(t *testing.T) {
+	sql := "SELECT p.region, p.[Jan] FROM dbo.sales AS s PIVOT (SUM(s.amount) FOR s.month IN ([Jan], [Feb])) AS p"
+
+	deps := analyzer.ExtractDependencies(parseSQL(t, sql))
+
+	wantColumns := []analyzer.ColumnDependency{
+		{Schema: "dbo", Table: "sales", Column: "amount", Kind: analyzer.SourceTable},
+		{Schema: "dbo", Table: "sales", Column: "month", Kind: analyzer.SourceTable},
+		{Table: "p", Column: "region", Kind: analyzer.SourceDerived},
+		{Table: "p", Column: "Jan", Kind: analyzer.SourceDerived},
+	}
+	if !reflect.DeepEqual(deps.Columns, wantColumns) {
+		t.Errorf("unexpected columns:\n got %+v\nwant %+v", deps.Columns, wantColumns)
+	}
+	if !reflect.DeepEqual(deps.DerivedTables, []string{"p"}) {
+		t.Errorf("expected derived table p, got %v", deps.DerivedTables)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		sql      string
+		kind     string
+		readOnly bool
+		reads    []string
+		writes   []string
+	}{
+		{"SELECT u.id FROM users u JOIN orders o ON o.user_id = u.id", "SELECT", true, []string{"users", "orders"}, nil},
+		{"WITH recent AS (SELECT id FROM orders) SELECT id FROM recent UNION SELECT id FROM archive", "SELECT", true, []string{"orders", "archive"}, nil},
+		{"WITH c AS (SELECT id FROM orders) SELECT d.id FROM (SELECT id FROM c) AS d", "SELECT", true, []string{"orders"}, nil},
+		{"SELECT id INTO #copy FROM users", "SELECT", false, []string{"users"}, []string{"#copy"}},
+		{"INSERT INTO archive (id) SELECT id FROM orders WHERE id IN (SELECT order_id FROM returns)", "INSERT", false, []string{"orders", "returns"}, []string{"archive"}},
+		{"UPDATE u SET name = r.name FROM dbo.users u JOIN roles r ON r.id = u.role_id", "UPDATE", false, []string{"roles"}, []string{"dbo.users"}},
+		{"UPDATE users SET total = (SELECT SUM(amount) FROM orders WHERE orders.user_id = users.id)", "UPDATE", false, []string{"orders"}, []string{"users"}},
+		{"UPDATE t SET a = 1 OUTPUT deleted.a INTO audit WHERE id IN (SELECT id FROM t)", "UPDATE", false, []string{"t"}, []string{"t", "audit"}},
+		{"DELETE FROM sessions WHERE user_id IN (SELECT id FROM users)", "DELETE", false, []string{"users"}, []string{"sessions"}},
+		{"MERGE INTO users AS t USING staging AS s ON t.id = s.id WHEN MATCHED THEN DELETE", "MERGE", false, []string{"staging"}, []string{"users"}},
+		{"CREATE VIEW dbo.v AS SELECT id FROM dbo.t", "DDL", false, []string{"dbo.t"}, []string{"dbo.v"}},
+		{"CREATE TABLE t (id INT)", "DDL", false, nil, []string{"t"}},
+		{"ALTER TABLE t ADD name VARCHAR(10)", "DDL", false, nil, []string{"t"}},
+		{"DROP TABLE a, b", "DDL", false, nil, []string{"a", "b"}},
+		{"DROP INDEX ix ON dbo.t", "DDL", false, nil, []string{"dbo.t"}},
+		{"GRANT SELECT ON dbo.t TO role", "DCL", false, nil, nil},
+	}
+
+	names := func(deps []analyzer.TableDependency) []string {
+		var out []string
+		for _, dep := range deps {
+			name := dep.Name
+			if dep.Schema != "" {
+				name = dep.Schema + "." + name
+			}
+			out = append(out, name)
+		}
+		return out
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			info := analyzer.Classify(parseSQL(t, tt.sql))
+			if info.Kind != tt.kind || info.ReadOnly != tt.readOnly {
+				t.Errorf("expected %s (read-only %v), got %s (%v)", tt.kind, tt.readOnly, info.Kind, info.ReadOnly)
+			}
+			if got := names(info.Reads); !reflect.DeepEqual(got, tt.reads) {
+				t.Errorf("expected reads %v, got %v", tt.reads, got)
+			}
+			if got := names(info.Writes); !reflect.DeepEqual(got, tt.writes) {
+				t.Errorf("expected writes %v, got %v", tt.writes, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+func TestEqualIgnoresPositionsAndKeywordCase(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"SELECT a FROM t WHERE a = 1 AND b = 2", "select a\n  from t\n where a=1 and b=2"},
+		{"SELECT id FROM a UNION SELECT id FROM b", "SELECT id FROM a   UNION   SELECT id FROM b"},
+		{"UPDATE t SET x = x + 1 WHERE NOT y", "update t set x=x+1 where not y"},
+		{"SELECT flags FROM t WHERE data = 0x0A", "SELECT flags FROM t WHERE data = 0x0a"},
+	}
+
+	for _, tt := range tests {
+		if !parser.Equal(parseSQL(t, tt.a), parseSQL(t, tt.b)) {
+			t.Errorf("expected %q to equal %q", tt.a, tt.b)
+		}
+	}
+}
+
+func TestEqualDetectsDifferences(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"SELECT a FROM t", "SELECT b FROM t"},
+		{"SELECT a FROM t", "SELECT a FROM s"},
+		{"SELECT a FROM t WHERE a = 1", "SELECT a FROM t WHERE a > 1"},
+		{"SELECT a FROM t WHERE a = 1", "SELECT a FROM t WHERE a = 1.0"},
+		{"SELECT a FROM t WHERE a = 1", "SELECT a FROM t WHERE a = '1'"},
+		{"SELECT a FROM t WHERE a = 'x'", "SELECT a FROM t WHERE a = N'x'"},
+		{"SELECT a FROM t WHERE a = 0x01", "SELECT a FROM t WHERE a = 0x02"},
+		{"SELECT a FROM t", "SELECT a FROM t JOIN s ON s.id = t.id"},
+		{"SELECT a FROM t", "SELECT DISTINCT a FROM t"},
+		{"SELECT a FROM t", "DELETE FROM t"},
+		// Names are compared exactly
+		{"SELECT a FROM t", "SELECT A FROM t"},
+	}
+
+	for _, tt := range tests {
+		if parser.Equal(parseSQL(t, tt.a), parseSQL(t, tt.b)) {
+			t.Errorf("expected %q to differ from %q", tt.a, tt.b)
+		}
+	}
+
+	if parser.Equal(parseSQL(t, "SELECT 1"), nil) || !parser.Equal(nil, nil) {
+		t.Error("unexpected comparison with nil")
+	}
+}
+
+func TestEqualLiteralValueTypes(t *testing.T) {
+	tests := []struct {
+		a, b interface{}
+		want bool
+	}{
+		{1, int64(1), true},
+		{int32(7), uint8(7), true},
+		{-1, uint64(1), false},
+		{float32(0.5), 0.5, true},
+		{int64(1), 1.0, false},
+		{"1", int64(1), false},
+		{[]byte{0x0A}, []byte{0x0A}, true},
+		{[]byte{0x0A}, []byte{0x0B}, false},
+		{parser.Null, parser.Null, true},
+		{parser.Null, "NULL", false},
+		{true, true, true},
+	}
+
+	for _, tt := range tests {
+		a, b := &parser.Literal{Value: tt.a}, &parser.Literal{Value: tt.b}
+		if got := parser.Equal(a, b); got != tt.want {
+			t.Errorf("Equal(%#v, %#v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// Reparsing the reconstructed SQL, decoding the JSON encoding and cloning all
+// yield the same tree
+func TestParseStability(t *testing.T) {
+	tests := []string{
+		"SELECT u.id, COUNT(*) AS n FROM users u LEFT JOIN orders o ON o.user_id = u.id WHERE u.active = 1 AND o.total > 10.5 GROUP BY u.id HAVING COUNT(*) > 2 ORDER BY n DESC",
+		"SELECT CASE WHEN a IS NULL THEN 'none' ELSE a END FROM t WHERE b IN (1, 2) OR c BETWEEN 1 AND 5",
+		"WITH c AS (SELECT id FROM t) SELECT id FROM c WHERE EXISTS (SELECT 1 FROM s WHERE s.id = c.id)",
+		"INSERT INTO t (a, b) VALUES (1, 'x')",
+		"DELETE FROM t WHERE a LIKE 'x%' AND flags & 4 = 4",
+	}
+
+	for _, sql := range tests {
+		t.Run(sql, func(t *testing.T) {
+			stmt := parseSQL(t, sql)
+
+			if reparsed := parseSQL(t, stmt.String()); !parser.Equal(stmt, reparsed) {
+				t.Errorf("reparsing %q gave a different tree", stmt.String())
+			}
+
+			data, err := parser.ToJSON(stmt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := parser.FromJSON(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !parser.Equal(stmt, decoded) {
+				t.Error("JSON round trip gave a different tree")
+			}
+
+			if !parser.Equal(stmt, stmt.Clone()) {
+				t.Error("clone differs from the original")
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []string
+	}{
+		{"SELECT a FROM t", "select a from t", nil},
+		{
+			"SELECT id, name FROM users",
+			"SELECT id, name, email FROM users",
+			[]string{"added Columns[2]: email"},
+		},
+		{
+			"SELECT id, name, email FROM users",
+			"SELECT id, email FROM users",
+			[]string{"removed Columns[1]: name"},
+		},
+		{
+			"SELECT id FROM users WHERE age > 18",
+			"SELECT id FROM users WHERE age >= 21",
+			[]string{"changed Where from (age > 18) to (age >= 21)"},
+		},
+		{
+			"SELECT id FROM users WHERE active = 1 AND age > 18",
+			"SELECT id FROM users WHERE active = 1 AND age > 21",
+			[]string{"changed Where.Right.Right from 18 to 21"},
+		},
+		{
+			"SELECT id FROM users",
+			"SELECT id FROM users WHERE active = 1",
+			[]string{"added Where: (active = 1)"},
+		},
+		{
+			"SELECT u.id FROM users u JOIN orders o ON o.user_id = u.id",
+			"SELECT u.id FROM users u LEFT JOIN orders o ON o.user_id = u.id",
+			[]string{"changed Joins[0] from INNER JOIN orders AS o ON (o.user_id = u.id) to LEFT JOIN orders AS o ON (o.user_id = u.id)"},
+		},
+		{
+			"SELECT a FROM t",
+			"SELECT DISTINCT a FROM t",
+			[]string{"changed Distinct from false to true"},
+		},
+		{
+			"SELECT a FROM t",
+			"DELETE FROM t",
+			[]string{"changed statement from SELECT a FROM t to DELETE FROM t"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.b, func(t *testing.T) {
+			changes := parser.Diff(parseSQL(t, tt.a), parseSQL(t, tt.b))
+			var got []string
+			for _, change := range changes {
+				got = append(got, change.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	same := [][]string{
+		{
+			"SELECT name FROM users WHERE id = 1 AND status = 'active'",
+			"select name  from users\n-- lookup\nwhere id = -42 and status = N'blocked'",
+			"SELECT name FROM users WHERE id = @id AND status = ?",
+		},
+		{
+			"SELECT TOP 10 id FROM orders WHERE region IN (1, 2) ORDER BY id OFFSET 5 ROWS FETCH NEXT 10 ROWS ONLY",
+			"SELECT TOP 50 id FROM orders WHERE region IN (3, 4, 5, @r) ORDER BY id OFFSET 0 ROWS FETCH NEXT 20 ROWS ONLY",
+		},
+		{
+			"INSERT INTO logs (level, message) VALUES (1, 'start')",
+			"INSERT INTO logs (level, message) VALUES (3, 'stop')",
+		},
+	}
+	for _, group := range same {
+		want := parser.Fingerprint(parseSQL(t, group[0]))
+		if len(want) != 16 {
+			t.Errorf("expected 16 hex digits, got %q", want)
+		}
+		for _, sql := range group[1:] {
+			if got := parser.Fingerprint(parseSQL(t, sql)); got != want {
+				t.Errorf("expected %q to share the fingerprint of %q", sql, group[0])
+			}
+		}
+	}
+
+	different := [][2]string{
+		{"SELECT name FROM users WHERE id = 1", "SELECT name FROM users WHERE id > 1"},
+		{"SELECT name FROM users WHERE id = 1", "SELECT email FROM users WHERE id = 1"},
+		{"SELECT name FROM users WHERE id = 1", "SELECT name FROM users WHERE id IS NULL"},
+		{"SELECT name FROM users WHERE id IN (1, 2)", "SELECT name FROM users WHERE id IN (1, other_id)"},
+		{"SELECT CAST(a AS VARCHAR(10)) FROM t", "SELECT CAST(a AS VARCHAR(20)) FROM t"},
+	}
+	for _, pair := range different {
+		if parser.Fingerprint(parseSQL(t, pair[0])) == parser.Fingerprint(parseSQL(t, pair[1])) {
+			t.Errorf("expected different fingerprints for %q and %q", pair[0], pair[1])
+		}
+	}
+
+	stmt := parseSQL(t, "SELECT name FROM users WHERE id = 1")
+	parser.Fingerprint(stmt)
+	if stmt.String() != "SELECT name FROM users WHERE (id = 1)" {
+		t.Errorf("Fingerprint modified the statement: %s", stmt.String())
+	}
+	if parser.Fingerprint(nil) != "" {
+		t.Errorf("expected an empty fingerprint for nil")
+	}
+}
@@ -0,0 +1,244 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/format"
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+func TestFormatSelect(t *testing.T) {
+	stmt := parseSQL(t, "select u.id, u.name, o.total from users u inner join orders o on u.id = o.user_id where u.active = 1 and o.total > 100 order by o.total desc")
+
+	want := `SELECT
+  u.id,
+  u.name,
+  o.total
+FROM users AS u
+INNER JOIN orders AS o ON u.id = o.user_id
+WHERE u.active = 1
+  AND o.total > 100
+ORDER BY o.total DESC`
+
+	if got := format.Format(stmt, format.DefaultFormatOptions()); got != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatOptions(t *testing.T) {
+	stmt := parseSQL(t, "SELECT id, name FROM users WHERE id IN (SELECT user_id FROM orders) OR (a = 1 AND b = 2)")
+
+	opts := format.FormatOptions{
+		IndentWidth:       4,
+		KeywordCase:       format.KeywordCaseLower,
+		ColumnsOnNewLines: false,
+	}
+
+	want := `select id, name
+from users
+where id in (
+    select user_id
+    from orders
+)
+    or a = 1 and b = 2`
+
+	if got := format.Format(stmt, opts); got != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	tests := []string{
+		"WITH recent AS (SELECT id FROM orders) SELECT id FROM recent UNION SELECT id FROM archive",
+		"SELECT d.id FROM (SELECT id FROM t WHERE (a + b) * 2 > 3) AS d WHERE NOT EXISTS (SELECT 1 FROM x)",
+		"INSERT INTO users (id, name) VALUES (1, 'a'), (2, 'b')",
+		"UPDATE users SET name = 'x', age = age + 1 WHERE id = 1",
+		"DELETE FROM users WHERE id BETWEEN 1 AND 5 OR name IS NULL",
+		"SELECT id FROM users WHERE (flags & (4 | 8)) = ~mask + 1",
+		"SELECT u.id FROM srv.sales.dbo.users AS u JOIN archive..users AS a ON a.id = u.id",
+		"SELECT name FROM users ORDER BY name COLLATE Latin1_General_CI_AS DESC, age NULLS LAST",
+		"SELECT (first + last) COLLATE Latin1_General_CS_AS, NOT a COLLATE Latin1_General_CS_AS = b FROM users",
+		"CREATE OR ALTER VIEW dbo.v (a, b) WITH SCHEMABINDING AS SELECT x, y FROM dbo.t WHERE x > 1",
+		"UPDATE users SET name = 'x' OUTPUT deleted.name AS old_name, inserted.name INTO @changes (old, new) WHERE id = 1",
+		"DELETE FROM users OUTPUT deleted.* WHERE id = 1",
+		"DELETE TOP (10) PERCENT FROM users WHERE id > 1",
+		"SELECT id, name INTO #active FROM users WHERE active = 1",
+		"SELECT p.[Jan] FROM (SELECT product, month, amt FROM sales) AS s PIVOT (SUM(amt) FOR month IN ([Jan], [2019])) AS p",
+		"SELECT u.amt FROM monthly AS m UNPIVOT (amt FOR month IN (Jan, Feb)) AS u",
+		"SELECT o.[Unit Price] AS [select] FROM [Order Details] AS o PIVOT (SUM(qty) FOR [year] IN ([2019], [2020])) AS [p v]",
+		"SELECT b.id FROM bigtable AS b TABLESAMPLE SYSTEM (10 PERCENT) REPEATABLE (42) WITH (NOLOCK)",
+		"SELECT v.id FROM (VALUES (1, 'a'), (2, 'b')) AS v(id, name) JOIN users AS u ON u.id = v.id",
+		"SELECT id FROM users WHERE name = @name OPTION (MAXDOP 4, RECOMPILE, OPTIMIZE FOR (@name = 'a'), LABEL = 'q')",
+		"INSERT INTO users (id) VALUES (1) OPTION (KEEP PLAN)",
+		"SELECT id, name FROM users ORDER BY id FOR JSON PATH, ROOT('users'), INCLUDE_NULL_VALUES OPTION (RECOMPILE)",
+		"SELECT id FROM orders WHERE (region, status) IN ((1, 'open'), (2, 'closed'))",
+		"SELECT region, SUM(amt) FROM sales GROUP BY region WITH ROLLUP",
+		"SELECT region, SUM(amt) FROM sales GROUP BY ALL region",
+		"MERGE INTO users AS t USING staging AS s ON t.id = s.id WHEN MATCHED AND s.deleted = 1 THEN DELETE WHEN MATCHED THEN UPDATE SET name = s.name WHEN NOT MATCHED THEN INSERT (id, name) VALUES (s.id, s.name)",
+		"SELECT id, go FROM t",
+	}
+
+	for _, sql := range tests {
+		t.Run(sql, func(t *testing.T) {
+			original := parseSQL(t, sql)
+			formatted := format.Format(original, format.DefaultFormatOptions())
+			if reparsed := parseSQL(t, formatted); reparsed.String() != original.String() {
+				t.Errorf("formatted SQL changed meaning:\n%s\n%s\n%s", formatted, original.String(), reparsed.String())
+			}
+		})
+	}
+}
+
+func TestFormatKeepsMultiLineLiterals(t *testing.T) {
+	sql := "SELECT a, 'line1\nline2' AS s FROM (SELECT [x\ny] AS a, 'it''s\nhere' AS b FROM t) AS d"
+	original := parseSQL(t, sql)
+	formatted := format.Format(original, format.DefaultFormatOptions())
+
+	for _, want := range []string{"'line1\nline2'", "[x\ny]", "'it''s\nhere'"} {
+		if !strings.Contains(formatted, want) {
+			t.Errorf("expected %q to be kept in:\n%s", want, formatted)
+		}
+	}
+	if reparsed := parseSQL(t, formatted); !parser.Equal(original, reparsed) {
+		t.Errorf("formatted SQL changed meaning:\n%s", formatted)
+	}
+}
+
+func TestFormatUnaryExpressions(t *testing.T) {
+	tests := []string{
+		"SELECT -(a + b), -(-c) FROM t WHERE (NOT a) = b AND NOT (c OR d)",
+		"SELECT id FROM t WHERE NOT a AND NOT b = 1",
+	}
+
+	for _, sql := range tests {
+		t.Run(sql, func(t *testing.T) {
+			original := parseSQL(t, sql)
+			formatted := format.Format(original, format.DefaultFormatOptions())
+			if reparsed := parseSQL(t, formatted); reparsed.String() != original.String() {
+				t.Errorf("formatted SQL changed meaning:\n%s\n%s\n%s", formatted, original.String(), reparsed.String())
+			}
+		})
+	}
+}
+
+func TestEmit(t *testing.T) {
+	tests := []struct {
+		sql     string
+		dialect format.Dialect
+		want    string
+	}{
+		{
+			"SELECT TOP 10 [order id], name FROM [order details] ORDER BY name",
+			format.DialectMySQL,
+			"SELECT `order id`, name FROM `order details` ORDER BY name ASC LIMIT 10",
+		},
+		{
+			"SELECT TOP 10 [order id], name FROM [order details] ORDER BY name",
+			format.DialectPostgreSQL,
+			`SELECT "order id", name FROM "order details" ORDER BY name ASC LIMIT 10`,
+		},
+		{
+			"SELECT ISNULL(nickname, name), GETDATE() FROM users",
+			format.DialectMySQL,
+			"SELECT IFNULL(nickname, name), NOW() FROM users",
+		},
+		{
+			"SELECT ISNULL(nickname, name), GETDATE() FROM users",
+			format.DialectPostgreSQL,
+			"SELECT COALESCE(nickname, name), NOW() FROM users",
+		},
+		{
+			"SELECT id FROM users ORDER BY id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY",
+			format.DialectMySQL,
+			"SELECT id FROM users ORDER BY id ASC LIMIT 10 OFFSET 20",
+		},
+		{
+			"SELECT [select].[from] FROM dbo.[select]",
+			format.DialectMySQL,
+			"SELECT `select`.`from` FROM dbo.`select`",
+		},
+		{
+			"SELECT region, SUM(amt) FROM sales GROUP BY region WITH ROLLUP",
+			format.DialectMySQL,
+			"SELECT region, SUM(amt) FROM sales GROUP BY region WITH ROLLUP",
+		},
+		{
+			"SELECT first + ' ' + last, id + 1 FROM users",
+			format.DialectPostgreSQL,
+			"SELECT ((first || ' ') || last), (id + 1) FROM users",
+		},
+		{
+			"SELECT d.id FROM (SELECT TOP 1 id FROM users) AS d UNION SELECT id FROM admins",
+			format.DialectPostgreSQL,
+			"SELECT d.id FROM (SELECT id FROM users LIMIT 1) AS d UNION SELECT id FROM admins",
+		},
+		{"SELECT a ^ b FROM t", format.DialectPostgreSQL, "SELECT (a # b) FROM t"},
+		{"SELECT a ^ b FROM t", format.DialectMySQL, "SELECT (a ^ b) FROM t"},
+		{`SELECT 'C:\new' FROM t`, format.DialectMySQL, `SELECT 'C:\\new' FROM t`},
+		{`SELECT 'C:\new' FROM t`, format.DialectPostgreSQL, `SELECT 'C:\new' FROM t`},
+		{
+			`SELECT id FROM t WHERE path LIKE 'x\_%'`,
+			format.DialectMySQL,
+			`SELECT id FROM t WHERE (path LIKE 'x\\_%' ESCAPE '!')`,
+		},
+		{
+			`SELECT id FROM t WHERE path LIKE 'x\!%'`,
+			format.DialectPostgreSQL,
+			`SELECT id FROM t WHERE (path LIKE 'x\!%' ESCAPE '#')`,
+		},
+		{
+			"SELECT analyze, [a]]b], x AS [a b] FROM t",
+			format.DialectPostgreSQL,
+			`SELECT "analyze", "a]b", x AS "a b" FROM t`,
+		},
+		{
+			`SELECT ["a"], [` + "`b`" + `] FROM t`,
+			format.DialectMySQL,
+			"SELECT `\"a\"`, ```b``` FROM t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.String()+" "+tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql)
+			original := stmt.String()
+			got, err := format.Emit(stmt, tt.dialect)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+			if stmt.String() != original {
+				t.Errorf("Emit modified the statement: %q", stmt.String())
+			}
+		})
+	}
+
+	errorTests := []struct {
+		sql     string
+		dialect format.Dialect
+		want    string
+	}{
+		{"SELECT TOP 10 PERCENT id FROM users", format.DialectMySQL, "TOP PERCENT has no MySQL equivalent"},
+		{"SELECT id FROM users WITH (NOLOCK)", format.DialectPostgreSQL, "table hint NOLOCK has no PostgreSQL equivalent"},
+		{"SELECT u.id FROM users u CROSS APPLY dbo.Roles(u.id) r", format.DialectPostgreSQL, "CROSS APPLY has no PostgreSQL equivalent"},
+		{"DELETE FROM users OUTPUT deleted.id WHERE id = 1", format.DialectMySQL, "OUTPUT has no MySQL equivalent"},
+		{"SELECT DATEADD(day, 1, created) FROM users", format.DialectMySQL, "DATEADD has no MySQL equivalent"},
+		{"SELECT region, SUM(amt) FROM sales GROUP BY region WITH ROLLUP", format.DialectPostgreSQL, "WITH ROLLUP has no PostgreSQL equivalent"},
+		{"SELECT region FROM sales GROUP BY ALL region", format.DialectMySQL, "GROUP BY ALL has no MySQL equivalent"},
+		{"SELECT a FROM t UNION SELECT TOP 1 b FROM u", format.DialectMySQL, "TOP in a branch of UNION has no MySQL equivalent"},
+		{"SELECT a FROM t UNION ALL SELECT b FROM u EXCEPT SELECT TOP 1 c FROM v", format.DialectPostgreSQL, "TOP in a branch of EXCEPT has no PostgreSQL equivalent"},
+		{"UPDATE t SET t.x = s.y FROM t JOIN s ON s.id = t.id", format.DialectPostgreSQL, "UPDATE ... FROM has no PostgreSQL equivalent"},
+		{"SELECT name + 'x' FROM users", format.DialectMySQL, "string concatenation with + has no MySQL equivalent"},
+		{`SELECT id FROM t WHERE path LIKE '\!#$~^|'`, format.DialectPostgreSQL, `LIKE pattern '\!#$~^|' has no PostgreSQL equivalent`},
+	}
+
+	for _, tt := range errorTests {
+		_, err := format.Emit(parseSQL(t, tt.sql), tt.dialect)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: expected error %q, got %v", tt.sql, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/dialect"
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+// FuzzParse checks that no input makes the parser panic. Run it with
+// go test ./tests -fuzz FuzzParse
+func FuzzParse(f *testing.F) {
+	seeds := []string{
+		"SELECT id, name FROM users WHERE id = 1",
+		"SELECT a.x, COUNT(*) FROM a JOIN b ON a.id = b.id GROUP BY a.x HAVING COUNT(*) > 1 ORDER BY 2 DESC",
+		"WITH c AS (SELECT 1 AS n) SELECT n FROM c UNION ALL SELECT 2",
+		"INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y')",
+		"UPDATE t SET a = a + 1 WHERE b LIKE 'x%' ESCAPE '!'",
+		"DELETE FROM t WHERE id IN (SELECT id FROM u)",
+		"MERGE INTO t USING s ON t.id = s.id WHEN MATCHED THEN DELETE",
+		"CREATE TABLE t (id INT PRIMARY KEY, name VARCHAR(50) NOT NULL)",
+		"SELECT CASE WHEN a & 4 = 4 THEN 0x1F ELSE .5e-3 END FROM t WITH (NOLOCK)",
+		"SELECT ROW_NUMBER() OVER (PARTITION BY a ORDER BY b) FROM t; SELECT",
+		"SELECT (((",
+		"SELECT [unterminated",
+		"/* comment",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, sql string) {
+		// A panic escaping either entry point fails the fuzz target
+		_, _ = parser.New(sql).ParseStatement()
+		_, _ = parser.New(sql).ParseProgram()
+
+		collecting := parser.New(sql)
+		collecting.SetCollectErrors(true)
+		_, _ = collecting.ParseProgram()
+	})
+}
+
+// panickingDialect panics when the lexer looks up the word BOOM
+type panickingDialect struct {
+	dialect.Dialect
+}
+
+func (d panickingDialect) IsReservedWord(word string) bool {
+	if word == "BOOM" {
+		panic("boom")
+	}
+	return d.Dialect.IsReservedWord(word)
+}
+
+func TestParseRecoversFromPanics(t *testing.T) {
+	d := panickingDialect{dialect.GetDialect("sqlserver")}
+
+	p := parser.NewWithDialect(context.Background(), "SELECT a FROM boom", d)
+	stmt, err := p.ParseStatement()
+	if stmt != nil {
+		t.Errorf("expected no statement, got %v", stmt)
+	}
+	var parseErr *parser.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected *parser.ParseError, got %T: %v", err, err)
+	}
+	if !strings.Contains(err.Error(), "internal parser error: boom") {
+		t.Errorf("unexpected error message: %v", err)
+	}
+
+	p = parser.NewWithDialect(context.Background(), "SELECT a FROM boom; SELECT b FROM t", d)
+	program, err := p.ParseProgram()
+	if err == nil {
+		t.Fatal("expected an error from ParseProgram")
+	}
+	if len(program.Statements) != 1 || program.Statements[0].String() != "SELECT b FROM t" {
+		t.Errorf("expected parsing to resume after the panic, got %v", program.Statements)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	deep := []string{
+		"SELECT " + strings.Repeat("(", 5000) + "1" + strings.Repeat(")", 5000),
+		"SELECT x FROM " + strings.Repeat("(SELECT x FROM ", 5000) + "t" + strings.Repeat(") d", 5000),
+	}
+	for _, sql := range deep {
+		_, err := parser.New(sql).ParseStatement()
+		if err == nil || err.Error() != "maximum nesting depth exceeded (1000 levels)" {
+			t.Errorf("expected the maximum depth error, got %.200v", err)
+		}
+	}
+
+	p := parser.New("SELECT ((((1)))); SELECT ((((((((((1))))))))))")
+	p.SetMaxDepth(8)
+	program, err := p.ParseProgram()
+	if err == nil || !strings.Contains(err.Error(), "maximum nesting depth exceeded (8 levels)") {
+		t.Errorf("expected the maximum depth error, got %v", err)
+	}
+	if len(program.Statements) != 1 {
+		t.Errorf("expected the shallow statement to parse, got %v", program.Statements)
+	}
+}
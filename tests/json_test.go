@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []string{
+		"SELECT DISTINCT TOP 5 u.id, COUNT(DISTINCT o.id) AS cnt FROM dbo.users AS u LEFT JOIN orders o ON o.user_id = u.id WHERE u.score > 1.5 AND u.name != 'x' AND u.active = TRUE GROUP BY u.id HAVING COUNT(*) > 2 ORDER BY cnt DESC",
+		"WITH r AS (SELECT id FROM a) SELECT id FROM r UNION ALL SELECT id FROM b",
+		"SELECT CASE WHEN x IS NULL THEN -1 ELSE x END, ROW_NUMBER() OVER (PARTITION BY d ORDER BY s) FROM t WHERE y NOT IN (1, 2) AND z BETWEEN @lo AND ? AND EXISTS (SELECT 1 FROM q)",
+		"SELECT d.id FROM (SELECT id FROM t) AS d CROSS APPLY dbo.Split(d.id) AS s ORDER BY d.id OFFSET 5 ROWS FETCH NEXT 10 ROWS ONLY",
+		"INSERT INTO users (id, name) VALUES (1, 'a'), (2, NULL)",
+		"UPDATE u SET name = s.name FROM users u JOIN staging s ON s.id = u.id WHERE u.id = 1",
+		"DELETE TOP (10) FROM logs WHERE created < 100",
+	}
+
+	for _, sql := range tests {
+		t.Run(sql, func(t *testing.T) {
+			original := parseSQL(t, sql)
+
+			data, err := parser.ToJSON(original)
+			if err != nil {
+				t.Fatalf("ToJSON failed: %v", err)
+			}
+			decoded, err := parser.FromJSON(data)
+			if err != nil {
+				t.Fatalf("FromJSON failed: %v\n%s", err, data)
+			}
+
+			if !reflect.DeepEqual(original, decoded) {
+				t.Errorf("round trip changed the AST:\n got %s\nwant %s", decoded.String(), original.String())
+			}
+		})
+	}
+}
+
+func TestJSONPreservesLiteralTypes(t *testing.T) {
+	stmt := parseSQL(t, "SELECT 1, 1.0, '1', TRUE, NULL, 0xFF FROM t")
+
+	data, err := parser.ToJSON(stmt)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+
+	var doc struct {
+		Type    string `json:"type"`
+		Columns []struct {
+			Type      string `json:"type"`
+			ValueType string `json:"value_type"`
+		} `json:"columns"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if doc.Type != "SelectStatement" {
+		t.Errorf("expected SelectStatement discriminator, got %q", doc.Type)
+	}
+
+	want := []string{"int", "float", "string", "bool", "null", "binary"}
+	for i, col := range doc.Columns {
+		if col.Type != "Literal" || col.ValueType != want[i] {
+			t.Errorf("column %d: expected %s literal, got %s/%s", i, want[i], col.Type, col.ValueType)
+		}
+	}
+
+	decoded, err := parser.FromJSON(data)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	values := decoded.(*parser.SelectStatement).Columns
+	if _, ok := values[0].(*parser.Literal).Value.(int64); !ok {
+		t.Errorf("expected int64, got %T", values[0].(*parser.Literal).Value)
+	}
+	if _, ok := values[1].(*parser.Literal).Value.(float64); !ok {
+		t.Errorf("expected float64, got %T", values[1].(*parser.Literal).Value)
+	}
+	if b, ok := values[5].(*parser.Literal).Value.([]byte); !ok || len(b) != 1 || b[0] != 0xFF {
+		t.Errorf("expected []byte{0xFF}, got %#v", values[5].(*parser.Literal).Value)
+	}
+}
+
+func TestJSONUnicodeString(t *testing.T) {
+	stmt := parseSQL(t, "SELECT N'abc', 'abc' FROM t")
+
+	data, err := parser.ToJSON(stmt)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	decoded, err := parser.FromJSON(data)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	if got := decoded.String(); got != "SELECT N'abc', 'abc' FROM t" {
+		t.Errorf("unexpected round trip: %s", got)
+	}
+	columns := decoded.(*parser.SelectStatement).Columns
+	if !columns[0].(*parser.Literal).Unicode || columns[1].(*parser.Literal).Unicode {
+		t.Error("expected only the first literal to be Unicode")
+	}
+}
+
+func TestFromJSONRejectsUnknownNodeType(t *testing.T) {
+	if _, err := parser.FromJSON([]byte(`{"type": "NoSuchStatement"}`)); err == nil {
+		t.Error("expected an error for unknown node type")
+	}
+}
@@ -1,8 +1,10 @@
 package tests
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/Chahine-tech/sql-parser-go/pkg/dialect"
 	"github.com/Chahine-tech/sql-parser-go/pkg/lexer"
 )
 
@@ -90,3 +92,482 @@ func TestStringLiterals(t *testing.T) {
 		t.Fatalf("expected 'hello world', got %q", tok.Literal)
 	}
 }
+
+func TestParameterTokens(t *testing.T) {
+	input := `WHERE id = @id AND v = @@ROWCOUNT AND x = ?`
+
+	expected := []struct {
+		tokenType lexer.TokenType
+		literal   string
+	}{
+		{lexer.WHERE, "WHERE"},
+		{lexer.IDENT, "id"},
+		{lexer.ASSIGN, "="},
+		{lexer.PARAMETER, "@id"},
+		{lexer.AND, "AND"},
+		{lexer.IDENT, "v"},
+		{lexer.ASSIGN, "="},
+		{lexer.SYSTEM_VARIABLE, "@@ROWCOUNT"},
+		{lexer.AND, "AND"},
+		{lexer.IDENT, "x"},
+		{lexer.ASSIGN, "="},
+		{lexer.PLACEHOLDER, "?"},
+		{lexer.EOF, ""},
+	}
+
+	l := lexer.New(input)
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt.tokenType || tok.Literal != tt.literal {
+			t.Fatalf("token[%d] expected %s %q, got %s %q", i, tt.tokenType, tt.literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestCommentsAreSkipped(t *testing.T) {
+	input := "SELECT -- pick columns\n  id /* outer /* nested */ still comment */ FROM users"
+
+	expectedTypes := []lexer.TokenType{lexer.SELECT, lexer.IDENT, lexer.FROM, lexer.IDENT, lexer.EOF}
+
+	l := lexer.New(input)
+	for i, expected := range expectedTypes {
+		if tok := l.NextToken(); tok.Type != expected {
+			t.Fatalf("token[%d] expected %s, got %s (%q)", i, expected, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestPreserveComments(t *testing.T) {
+	input := "SELECT /* multi\nline */ id -- trailing\nFROM users"
+
+	l := lexer.New(input)
+	l.SetPreserveComments(true)
+
+	expected := []struct {
+		tokenType lexer.TokenType
+		literal   string
+		line      int
+	}{
+		{lexer.SELECT, "SELECT", 1},
+		{lexer.COMMENT, "/* multi\nline */", 1},
+		{lexer.IDENT, "id", 2},
+		{lexer.COMMENT, "-- trailing", 2},
+		{lexer.FROM, "FROM", 3},
+		{lexer.IDENT, "users", 3},
+		{lexer.EOF, "", 3},
+	}
+
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt.tokenType || tok.Literal != tt.literal {
+			t.Fatalf("token[%d] expected %s %q, got %s %q", i, tt.tokenType, tt.literal, tok.Type, tok.Literal)
+		}
+		if tok.Line != tt.line {
+			t.Errorf("token[%d] expected line %d, got %d", i, tt.line, tok.Line)
+		}
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	l := lexer.New("SELECT /* never closed")
+	l.NextToken()
+
+	if tok := l.NextToken(); tok.Type != lexer.ILLEGAL {
+		t.Errorf("expected ILLEGAL token, got %s (%q)", tok.Type, tok.Literal)
+	}
+}
+
+func TestBracketedIdentifiers(t *testing.T) {
+	input := `SELECT [Order Details].[Unit Price],[select],[a]]b] FROM [Order Details]`
+
+	expected := []struct {
+		tokenType lexer.TokenType
+		literal   string
+	}{
+		{lexer.SELECT, "SELECT"},
+		{lexer.IDENT, "Order Details"},
+		{lexer.DOT, "."},
+		{lexer.IDENT, "Unit Price"},
+		{lexer.COMMA, ","},
+		{lexer.IDENT, "select"},
+		{lexer.COMMA, ","},
+		{lexer.IDENT, "a]b"},
+		{lexer.FROM, "FROM"},
+		{lexer.IDENT, "Order Details"},
+		{lexer.EOF, ""},
+	}
+
+	l := lexer.New(input)
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt.tokenType || tok.Literal != tt.literal {
+			t.Fatalf("token[%d] expected %s %q, got %s %q", i, tt.tokenType, tt.literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestUnterminatedBracketedIdentifier(t *testing.T) {
+	l := lexer.New("SELECT id\nFROM [Order Details")
+	for tok := l.NextToken(); tok.Type != lexer.EOF; tok = l.NextToken() {
+	}
+
+	errs := l.Errors()
+	if len(errs) != 1 || errs[0] != "unterminated bracketed identifier at line 2, column 6" {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestUnterminatedTokens(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT 'abc\nFROM t", "unterminated string literal at line 1, column 8"},
+		{"SELECT id FROM t\nWHERE name = N'Sm", "unterminated string literal at line 2, column 14"},
+		{"SELECT \"abc FROM t", "unterminated string literal at line 1, column 8"},
+		{"SELECT id /* note", "unterminated block comment at line 1, column 11"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		illegal := 0
+		for tok := l.NextToken(); tok.Type != lexer.EOF; tok = l.NextToken() {
+			if tok.Type == lexer.ILLEGAL {
+				illegal++
+			}
+		}
+		if illegal != 1 {
+			t.Errorf("%q: expected 1 ILLEGAL token, got %d", tt.input, illegal)
+		}
+		if errs := l.Errors(); len(errs) != 1 || errs[0] != tt.want {
+			t.Errorf("%q: expected %q, got %v", tt.input, tt.want, errs)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tokens, err := lexer.Tokenize("SELECT id -- key\nFROM t")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		tokenType lexer.TokenType
+		literal   string
+		line      int
+		column    int
+	}{
+		{lexer.SELECT, "SELECT", 1, 1},
+		{lexer.IDENT, "id", 1, 8},
+		{lexer.COMMENT, "-- key", 1, 11},
+		{lexer.FROM, "FROM", 2, 1},
+		{lexer.IDENT, "t", 2, 6},
+		{lexer.EOF, "", 2, 7},
+	}
+	if len(tokens) != len(expected) {
+		t.Fatalf("expected %d tokens, got %v", len(expected), tokens)
+	}
+	for i, tt := range expected {
+		tok := tokens[i]
+		if tok.Type != tt.tokenType || tok.Literal != tt.literal || tok.Line != tt.line || tok.Column != tt.column {
+			t.Errorf("token[%d] expected %s %q at %d:%d, got %s %q at %d:%d", i, tt.tokenType, tt.literal, tt.line, tt.column, tok.Type, tok.Literal, tok.Line, tok.Column)
+		}
+	}
+
+	tokens, err = lexer.Tokenize("SELECT 'abc FROM t")
+	if err == nil || err.Error() != "unterminated string literal at line 1, column 8" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if last := tokens[len(tokens)-1]; last.Type != lexer.EOF {
+		t.Errorf("expected tokens to end at EOF, got %s", last.Type)
+	}
+
+	if _, err := lexer.Tokenize("SELECT a $ b"); err == nil || err.Error() != `unexpected character "$" at line 1, column 10` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDoubleQuotedIdentifiers(t *testing.T) {
+	input := `SELECT "my column", "say ""hi""" FROM "my table"`
+
+	tests := []struct {
+		name           string
+		quoted         bool
+		quotedLiterals lexer.TokenType
+	}{
+		{"QUOTED_IDENTIFIER ON", true, lexer.IDENT},
+		{"QUOTED_IDENTIFIER OFF", false, lexer.STRING},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(input)
+			l.SetQuotedIdentifier(tt.quoted)
+
+			expected := []struct {
+				tokenType lexer.TokenType
+				literal   string
+			}{
+				{lexer.SELECT, "SELECT"},
+				{tt.quotedLiterals, "my column"},
+				{lexer.COMMA, ","},
+				{tt.quotedLiterals, `say "hi"`},
+				{lexer.FROM, "FROM"},
+				{tt.quotedLiterals, "my table"},
+				{lexer.EOF, ""},
+			}
+
+			for i, want := range expected {
+				tok := l.NextToken()
+				if tok.Type != want.tokenType || tok.Literal != want.literal {
+					t.Fatalf("token[%d] expected %s %q, got %s %q", i, want.tokenType, want.literal, tok.Type, tok.Literal)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenPositions(t *testing.T) {
+	input := "x >= 'ab'\n  42"
+
+	expected := []struct {
+		literal    string
+		start, end int
+		line, col  int
+	}{
+		{"x", 0, 1, 1, 1},
+		{">=", 2, 4, 1, 3},
+		{"ab", 5, 9, 1, 6},
+		{"42", 12, 14, 2, 3},
+	}
+
+	l := lexer.New(input)
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Literal != tt.literal {
+			t.Fatalf("token[%d] expected %q, got %q", i, tt.literal, tok.Literal)
+		}
+		if tok.Position != tt.start || tok.EndPosition != tt.end {
+			t.Errorf("token[%d] expected offsets %d-%d, got %d-%d", i, tt.start, tt.end, tok.Position, tok.EndPosition)
+		}
+		if tok.Line != tt.line || tok.Column != tt.col {
+			t.Errorf("token[%d] expected %d:%d, got %d:%d", i, tt.line, tt.col, tok.Line, tok.Column)
+		}
+	}
+}
+
+func TestNumericLiteralForms(t *testing.T) {
+	input := `1E3 2.5e-4 .25 6e+2 t.col 3 e`
+
+	expected := []struct {
+		tokenType lexer.TokenType
+		literal   string
+	}{
+		{lexer.NUMBER, "1E3"},
+		{lexer.NUMBER, "2.5e-4"},
+		{lexer.NUMBER, ".25"},
+		{lexer.NUMBER, "6e+2"},
+		{lexer.IDENT, "t"},
+		{lexer.DOT, "."},
+		{lexer.IDENT, "col"},
+		{lexer.NUMBER, "3"},
+		{lexer.IDENT, "e"},
+		{lexer.EOF, ""},
+	}
+
+	l := lexer.New(input)
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt.tokenType || tok.Literal != tt.literal {
+			t.Fatalf("token[%d] expected %s %q, got %s %q", i, tt.tokenType, tt.literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestBatchSeparator(t *testing.T) {
+	input := "SELECT go, 'GO' FROM t\nGO\n  go 3 -- run three times\nSELECT [GO]\nGO"
+
+	expected := []struct {
+		tokenType lexer.TokenType
+		literal   string
+	}{
+		{lexer.SELECT, "SELECT"},
+		{lexer.IDENT, "go"},
+		{lexer.COMMA, ","},
+		{lexer.STRING, "GO"},
+		{lexer.FROM, "FROM"},
+		{lexer.IDENT, "t"},
+		{lexer.GO, "GO"},
+		{lexer.GO, "go 3"},
+		{lexer.SELECT, "SELECT"},
+		{lexer.IDENT, "GO"},
+		{lexer.GO, "GO"},
+		{lexer.EOF, ""},
+	}
+
+	l := lexer.New(input)
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt.tokenType || tok.Literal != tt.literal {
+			t.Fatalf("token[%d] expected %s %q, got %s %q", i, tt.tokenType, tt.literal, tok.Type, tok.Literal)
+		}
+	}
+
+	// GO is only a separator in SQL Server scripts
+	l = lexer.NewWithDialect("SELECT 1\nGO", dialect.GetDialect("postgresql"))
+	for tok := l.NextToken(); tok.Type != lexer.EOF; tok = l.NextToken() {
+		if tok.Type == lexer.GO {
+			t.Fatal("unexpected GO token outside SQL Server")
+		}
+	}
+}
+
+func TestTempTableNames(t *testing.T) {
+	input := "SELECT * FROM #temp JOIN ##global_2 ON #temp.id = 1, @tvp"
+
+	expected := []struct {
+		tokenType lexer.TokenType
+		literal   string
+	}{
+		{lexer.SELECT, "SELECT"},
+		{lexer.ASTERISK, "*"},
+		{lexer.FROM, "FROM"},
+		{lexer.IDENT, "#temp"},
+		{lexer.JOIN, "JOIN"},
+		{lexer.IDENT, "##global_2"},
+		{lexer.ON, "ON"},
+		{lexer.IDENT, "#temp"},
+		{lexer.DOT, "."},
+		{lexer.IDENT, "id"},
+		{lexer.ASSIGN, "="},
+		{lexer.NUMBER, "1"},
+		{lexer.COMMA, ","},
+		{lexer.PARAMETER, "@tvp"},
+		{lexer.EOF, ""},
+	}
+
+	l := lexer.New(input)
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt.tokenType || tok.Literal != tt.literal {
+			t.Fatalf("token[%d] expected %s %q, got %s %q", i, tt.tokenType, tt.literal, tok.Type, tok.Literal)
+		}
+	}
+
+	// # has no meaning outside SQL Server
+	l = lexer.NewWithDialect("#temp", dialect.GetDialect("postgresql"))
+	if tok := l.NextToken(); tok.Type != lexer.ILLEGAL {
+		t.Errorf("expected ILLEGAL, got %s %q", tok.Type, tok.Literal)
+	}
+}
+
+func TestKeywordCase(t *testing.T) {
+	input := "select MyCol FROM t WHERE Select = SELECT"
+
+	tests := []struct {
+		opts     lexer.LexerOptions
+		expected []lexer.TokenType
+	}{
+		{
+			lexer.LexerOptions{},
+			[]lexer.TokenType{lexer.SELECT, lexer.IDENT, lexer.FROM, lexer.IDENT, lexer.WHERE, lexer.SELECT, lexer.ASSIGN, lexer.SELECT},
+		},
+		{
+			lexer.LexerOptions{CaseSensitiveKeywords: true},
+			[]lexer.TokenType{lexer.IDENT, lexer.IDENT, lexer.FROM, lexer.IDENT, lexer.WHERE, lexer.IDENT, lexer.ASSIGN, lexer.SELECT},
+		},
+	}
+
+	literals := strings.Fields(input)
+	for _, tt := range tests {
+		l := lexer.NewWithOptions(input, tt.opts)
+		for i, want := range tt.expected {
+			tok := l.NextToken()
+			if tok.Type != want || tok.Literal != literals[i] {
+				t.Fatalf("%+v token[%d] expected %s %q, got %s %q", tt.opts, i, want, literals[i], tok.Type, tok.Literal)
+			}
+		}
+	}
+
+	// The batch separator is a keyword too
+	l := lexer.NewWithOptions("SELECT 1\ngo\n", lexer.LexerOptions{CaseSensitiveKeywords: true})
+	for tok := l.NextToken(); tok.Type != lexer.EOF; tok = l.NextToken() {
+		if tok.Type == lexer.GO {
+			t.Errorf("expected lower-case go to be an identifier")
+		}
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		dialect string
+		input   string
+		want    string
+	}{
+		{"sqlserver", `'O''Brien'`, "O'Brien"},
+		{"sqlserver", `''`, ""},
+		{"sqlserver", `''''`, "'"},
+		{"sqlserver", `'it''s a ''quote'''`, "it's a 'quote'"},
+		{"sqlserver", `'SELECT * FROM t WHERE a = 1'`, "SELECT * FROM t WHERE a = 1"},
+		// A backslash is an ordinary character outside MySQL
+		{"sqlserver", `'C:\temp\'`, `C:\temp\`},
+		{"postgresql", `'a\nb'`, `a\nb`},
+		{"mysql", `'it\'s'`, "it's"},
+		{"mysql", `'a\nb\\c\%'`, "a\nb\\c%"},
+		{"mysql", `'O''Brien'`, "O'Brien"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewWithDialect(tt.input+" x", dialect.GetDialect(tt.dialect))
+		tok := l.NextToken()
+		if tok.Type != lexer.STRING || tok.Literal != tt.want {
+			t.Errorf("%s %s: expected STRING %q, got %s %q", tt.dialect, tt.input, tt.want, tok.Type, tok.Literal)
+		}
+		if next := l.NextToken(); next.Type != lexer.IDENT || next.Literal != "x" {
+			t.Errorf("%s %s: expected the string to end before x, got %s %q", tt.dialect, tt.input, next.Type, next.Literal)
+		}
+	}
+}
+
+func TestUnicodeStrings(t *testing.T) {
+	input := "SELECT N'abc', n'O''Brien', 'x', n, N FROM t WHERE nm = N''"
+
+	expected := []struct {
+		tokenType lexer.TokenType
+		literal   string
+		unicode   bool
+	}{
+		{lexer.SELECT, "SELECT", false},
+		{lexer.STRING, "abc", true},
+		{lexer.COMMA, ",", false},
+		{lexer.STRING, "O'Brien", true},
+		{lexer.COMMA, ",", false},
+		{lexer.STRING, "x", false},
+		{lexer.COMMA, ",", false},
+		{lexer.IDENT, "n", false},
+		{lexer.COMMA, ",", false},
+		{lexer.IDENT, "N", false},
+		{lexer.FROM, "FROM", false},
+		{lexer.IDENT, "t", false},
+		{lexer.WHERE, "WHERE", false},
+		{lexer.IDENT, "nm", false},
+		{lexer.ASSIGN, "=", false},
+		{lexer.STRING, "", true},
+		{lexer.EOF, "", false},
+	}
+
+	l := lexer.New(input)
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt.tokenType || tok.Literal != tt.literal || tok.Unicode != tt.unicode {
+			t.Fatalf("token[%d] expected %s %q (unicode %v), got %s %q (%v)", i, tt.tokenType, tt.literal, tt.unicode, tok.Type, tok.Literal, tok.Unicode)
+		}
+	}
+
+	l = lexer.New("x = N'abc'")
+	l.NextToken()
+	l.NextToken()
+	if tok := l.NextToken(); tok.Position != 4 || tok.EndPosition != 10 {
+		t.Errorf("expected N'abc' to span 4-10, got %d-%d", tok.Position, tok.EndPosition)
+	}
+}
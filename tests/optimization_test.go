@@ -2,6 +2,8 @@ package tests
 
 import (
 	"context"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/Chahine-tech/sql-parser-go/pkg/analyzer"
@@ -296,3 +298,164 @@ func getKeys(m map[string]bool) []string {
 	}
 	return keys
 }
+
+func TestSelectStarDetection(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []analyzer.StarUsage
+	}{
+		{"SELECT id, name FROM users", nil},
+		{"SELECT COUNT(*) FROM users", nil},
+		{"SELECT * FROM users u JOIN dbo.orders ON u.id = orders.user_id", []analyzer.StarUsage{
+			{Tables: []string{"u", "dbo.orders"}},
+		}},
+		{"SELECT u.*, o.total FROM users u JOIN orders o ON u.id = o.user_id", []analyzer.StarUsage{
+			{Qualified: true, Tables: []string{"u"}},
+		}},
+		{"SELECT d.id FROM (SELECT * FROM logs) AS d", []analyzer.StarUsage{
+			{Tables: []string{"logs"}},
+		}},
+		{"SELECT id FROM a UNION SELECT * FROM b", []analyzer.StarUsage{
+			{Tables: []string{"b"}},
+		}},
+		{"WITH c AS (SELECT * FROM t) SELECT id FROM c", []analyzer.StarUsage{
+			{Tables: []string{"t"}},
+		}},
+		// The select list of EXISTS is never returned
+		{"SELECT id FROM users u WHERE EXISTS (SELECT * FROM orders o WHERE o.user_id = u.id)", nil},
+		{"SELECT id FROM users WHERE id IN (SELECT * FROM admins)", []analyzer.StarUsage{
+			{Tables: []string{"admins"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql)
+			got := analyzer.SelectStars(stmt)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+			if analyzer.UsesSelectStar(stmt) != (len(tt.want) > 0) {
+				t.Errorf("UsesSelectStar disagrees with SelectStars")
+			}
+		})
+	}
+}
+
+func TestDetectCartesianProducts(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want [][]string // tables of each warning
+	}{
+		{"SELECT * FROM users u JOIN orders o ON u.id = o.user_id", nil},
+		{"SELECT * FROM users u, orders o WHERE u.id = o.user_id AND o.total > 10", nil},
+		{"SELECT * FROM users u, orders o", [][]string{{"u", "o"}}},
+		{"SELECT * FROM users u, orders o WHERE o.total > 10", [][]string{{"u", "o"}}},
+		{"SELECT * FROM a, b, c WHERE a.id = b.id", [][]string{{"a", "b", "c"}}},
+		// b is connected to a through c
+		{"SELECT * FROM a, b JOIN c ON c.a_id = a.id AND c.b_id = b.id", nil},
+		{"SELECT * FROM users u JOIN orders o ON o.total > 10", [][]string{{"u", "o"}}},
+		{"SELECT * FROM users u JOIN orders o ON 1 = 1", [][]string{{"u", "o"}}},
+		// Unaliased tables can be qualified by name
+		{"SELECT * FROM users JOIN orders ON users.id = orders.user_id", nil},
+		// Intentional cross joins and outer joins are not flagged
+		{"SELECT * FROM users u CROSS JOIN regions r", nil},
+		{"SELECT * FROM users u LEFT JOIN orders o ON o.total > 10", nil},
+		// Nested queries are checked too
+		{"SELECT * FROM (SELECT a.id FROM a, b) AS d", [][]string{{"a", "b"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, ok := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			if !ok {
+				t.Fatalf("expected SelectStatement")
+			}
+			var got [][]string
+			for _, w := range analyzer.DetectCartesianProducts(stmt) {
+				if w.Type != analyzer.WarningCartesianProduct {
+					t.Errorf("unexpected warning type %s", w.Type)
+				}
+				got = append(got, w.Tables)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSargability(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string // type and predicate of each warning
+	}{
+		{"SELECT id FROM orders WHERE order_date >= '2020-01-01' AND name LIKE 'abc%'", nil},
+		{"SELECT id FROM orders WHERE YEAR(order_date) = 2020", []string{"FUNCTION_ON_COLUMN (YEAR(order_date) = 2020)"}},
+		{"SELECT id FROM users WHERE status = 1 AND UPPER(name) = 'X'", []string{"FUNCTION_ON_COLUMN (UPPER(name) = 'X')"}},
+		{"SELECT id FROM users WHERE CAST(code AS INT) IN (1, 2)", []string{"FUNCTION_ON_COLUMN (CAST(code AS INT) IN (1, 2))"}},
+		{"SELECT id FROM users WHERE name LIKE '%son'", []string{"LEADING_WILDCARD (name LIKE '%son')"}},
+		{"SELECT id FROM users WHERE name NOT LIKE '%son'", nil},
+		{"SELECT id FROM users WHERE code = N'A1'", []string{"IMPLICIT_CONVERSION (code = N'A1')"}},
+		// Functions of constants alone are fine
+		{"SELECT id FROM orders WHERE order_date > DATEADD(day, -7, GETDATE())", nil},
+		// OR branches, join conditions and nested queries are checked too
+		{"SELECT id FROM users WHERE id = 1 OR LOWER(email) = @e", []string{"FUNCTION_ON_COLUMN (LOWER(email) = @e)"}},
+		{"SELECT u.id FROM users u JOIN orders o ON o.user_id = u.id AND ISNULL(o.flag, 0) = 1", []string{"FUNCTION_ON_COLUMN (ISNULL(o.flag, 0) = 1)"}},
+		{"SELECT id FROM users WHERE id IN (SELECT user_id FROM orders WHERE note LIKE '_x')", []string{"LEADING_WILDCARD (note LIKE '_x')"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, ok := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			if !ok {
+				t.Fatalf("expected SelectStatement")
+			}
+			var got []string
+			for _, w := range analyzer.AnalyzeSargability(stmt) {
+				got = append(got, w.Type+" "+w.Predicate)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDetectInjectionRisks(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string // spliced values of each warning
+	}{
+		{"SELECT 'SELECT * FROM users WHERE id = ' + @id", []string{"@id"}},
+		{"SELECT 'DELETE FROM ' + table_name + ' WHERE id = ' + @id FROM tables", []string{"table_name, @id"}},
+		{"SELECT CONCAT('SELECT name FROM ', @table) FROM t", []string{"@table"}},
+		{"INSERT INTO jobs (query) VALUES ('UPDATE users SET active = 0 WHERE name = ' + ?)", []string{"?"}},
+		{"SELECT id FROM t WHERE q = ' WHERE name LIKE ' + @name", []string{"@name"}},
+		{"SELECT id FROM t WHERE id IN (SELECT 'SELECT 1 FROM x' + @p FROM s)", []string{"@p"}},
+		// Concatenating constants or plain text is fine
+		{"SELECT 'SELECT * FROM ' + 'users'", nil},
+		{"SELECT name FROM products WHERE name LIKE '%' + @search + '%'", nil},
+		{"SELECT first + ' ' + last FROM users", nil},
+		{"SELECT 'Ordered from ' + store FROM orders", nil},
+		{"SELECT a + b FROM t WHERE total = price + @tax", nil},
+		{"SELECT @id FROM t WHERE q = 'SELECT * FROM users'", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			warnings := analyzer.DetectInjectionRisks(parseSQL(t, tt.sql))
+			if len(warnings) != len(tt.want) {
+				t.Fatalf("expected %d warnings, got %+v", len(tt.want), warnings)
+			}
+			for i, w := range warnings {
+				if w.Type != analyzer.WarningDynamicSQL {
+					t.Errorf("unexpected warning type %s", w.Type)
+				}
+				if !strings.Contains(w.Message, "builds SQL text from "+tt.want[i]+";") {
+					t.Errorf("expected values %s in %q", tt.want[i], w.Message)
+				}
+			}
+		})
+	}
+}
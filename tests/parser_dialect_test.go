@@ -399,3 +399,23 @@ func TestDialectDataTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestDoubleQuotedIdentifierReferences(t *testing.T) {
+	sql := `SELECT "o"."order id" FROM "sales"."order items" "o" WHERE "o"."qty" > 1`
+	p := parser.NewWithDialect(context.Background(), sql, dialect.GetDialect("postgresql"))
+
+	stmt, err := p.ParseStatement()
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	selectStmt := stmt.(*parser.SelectStatement)
+	col, ok := selectStmt.Columns[0].(*parser.ColumnReference)
+	if !ok || col.Table != "o" || col.Column != "order id" {
+		t.Errorf("unexpected column %+v", selectStmt.Columns[0])
+	}
+	table := selectStmt.From.Tables[0]
+	if table.Schema != "sales" || table.Name != "order items" || table.Alias != "o" {
+		t.Errorf("unexpected table %+v", table)
+	}
+}
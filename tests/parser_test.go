@@ -0,0 +1,3052 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/lexer"
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+func parseSQL(t *testing.T, sql string) parser.Statement {
+	t.Helper()
+
+	p := parser.New(sql)
+	stmt, err := p.ParseStatement()
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", sql, err)
+	}
+	return stmt
+}
+
+func TestInsertSelect(t *testing.T) {
+	sql := "INSERT INTO archive (id, name) SELECT u.id, u.name FROM users u JOIN roles r ON u.role_id = r.id WHERE u.active = 0"
+
+	stmt, ok := parseSQL(t, sql).(*parser.InsertStatement)
+	if !ok {
+		t.Fatalf("expected *parser.InsertStatement")
+	}
+
+	if stmt.Table.Name != "archive" {
+		t.Errorf("expected table archive, got %s", stmt.Table.Name)
+	}
+	if len(stmt.Columns) != 2 || stmt.Columns[0] != "id" || stmt.Columns[1] != "name" {
+		t.Errorf("unexpected column list: %v", stmt.Columns)
+	}
+	if stmt.Values != nil {
+		t.Errorf("expected no VALUES rows, got %d", len(stmt.Values))
+	}
+	if stmt.Source == nil {
+		t.Fatalf("expected SELECT source")
+	}
+	if len(stmt.Source.Joins) != 1 {
+		t.Errorf("expected 1 join in source, got %d", len(stmt.Source.Joins))
+	}
+	if stmt.Source.Where == nil {
+		t.Errorf("expected WHERE clause in source")
+	}
+}
+
+func TestInsertValues(t *testing.T) {
+	stmt, ok := parseSQL(t, "INSERT INTO users (id, name) VALUES (1, 'a'), (2, 'b')").(*parser.InsertStatement)
+	if !ok {
+		t.Fatalf("expected *parser.InsertStatement")
+	}
+
+	if stmt.Source != nil {
+		t.Errorf("expected no SELECT source")
+	}
+	if len(stmt.Values) != 2 || len(stmt.Values[1]) != 2 {
+		t.Errorf("unexpected VALUES rows: %v", stmt.Values)
+	}
+}
+
+func TestUpdateStatement(t *testing.T) {
+	stmt, ok := parseSQL(t, "UPDATE users u SET u.name = 'x', status = 1 WHERE u.id = 5").(*parser.UpdateStatement)
+	if !ok {
+		t.Fatalf("expected *parser.UpdateStatement")
+	}
+
+	if stmt.Table.Name != "users" || stmt.Table.Alias != "u" {
+		t.Errorf("unexpected target table: %+v", stmt.Table)
+	}
+	if len(stmt.Set) != 2 {
+		t.Fatalf("expected 2 assignments, got %d", len(stmt.Set))
+	}
+	if stmt.Set[0].Column.Table != "u" || stmt.Set[0].Column.Column != "name" {
+		t.Errorf("unexpected first assignment column: %s", stmt.Set[0].Column.String())
+	}
+	if stmt.Set[1].Column.Column != "status" {
+		t.Errorf("unexpected second assignment column: %s", stmt.Set[1].Column.String())
+	}
+	if stmt.Where == nil {
+		t.Errorf("expected WHERE clause")
+	}
+}
+
+func TestUpdateFromJoin(t *testing.T) {
+	sql := "UPDATE t SET t.x = s.y FROM t JOIN s ON t.id = s.id WHERE s.active = 1"
+
+	stmt, ok := parseSQL(t, sql).(*parser.UpdateStatement)
+	if !ok {
+		t.Fatalf("expected *parser.UpdateStatement")
+	}
+
+	if stmt.From == nil || len(stmt.From.Tables) != 1 || stmt.From.Tables[0].Name != "t" {
+		t.Fatalf("unexpected FROM clause: %+v", stmt.From)
+	}
+	if len(stmt.Joins) != 1 || stmt.Joins[0].Table.Name != "s" {
+		t.Fatalf("expected a single join on s, got %+v", stmt.Joins)
+	}
+	if stmt.Where == nil {
+		t.Errorf("expected WHERE clause after joins")
+	}
+}
+
+func TestDeleteStatement(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		table    string
+		topCount int
+		hasWhere bool
+	}{
+		{"with FROM", "DELETE FROM users WHERE id = 1", "users", 0, true},
+		{"T-SQL shorthand", "DELETE users WHERE id = 1", "users", 0, true},
+		{"without WHERE", "DELETE FROM logs", "logs", 0, false},
+		{"batched TOP", "DELETE TOP (1000) FROM audit WHERE created < '2020-01-01'", "audit", 1000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, ok := parseSQL(t, tt.sql).(*parser.DeleteStatement)
+			if !ok {
+				t.Fatalf("expected *parser.DeleteStatement")
+			}
+
+			if stmt.From.Name != tt.table {
+				t.Errorf("expected table %s, got %s", tt.table, stmt.From.Name)
+			}
+			if tt.topCount > 0 && (stmt.Top == nil || stmt.Top.Count != tt.topCount) {
+				t.Errorf("expected TOP %d, got %+v", tt.topCount, stmt.Top)
+			}
+			if (stmt.Where != nil) != tt.hasWhere {
+				t.Errorf("expected WHERE presence %v", tt.hasWhere)
+			}
+		})
+	}
+}
+
+func TestBetweenExpression(t *testing.T) {
+	stmt, ok := parseSQL(t, "SELECT name FROM users WHERE age BETWEEN 18 AND 65 AND status NOT BETWEEN 1 AND 3").(*parser.SelectStatement)
+	if !ok {
+		t.Fatalf("expected *parser.SelectStatement")
+	}
+
+	and, ok := stmt.Where.(*parser.BinaryExpression)
+	if !ok || and.Operator != "AND" {
+		t.Fatalf("expected top-level AND, got %s", stmt.Where.String())
+	}
+
+	left, ok := and.Left.(*parser.BetweenExpression)
+	if !ok {
+		t.Fatalf("expected BetweenExpression on the left, got %T", and.Left)
+	}
+	if left.Not || left.Lower.String() != "18" || left.Upper.String() != "65" {
+		t.Errorf("unexpected BETWEEN bounds: %s", left.String())
+	}
+
+	right, ok := and.Right.(*parser.BetweenExpression)
+	if !ok || !right.Not {
+		t.Fatalf("expected NOT BETWEEN on the right, got %s", and.Right.String())
+	}
+}
+
+func TestIsNullExpression(t *testing.T) {
+	stmt, ok := parseSQL(t, "SELECT id FROM users WHERE deleted_at IS NULL AND email IS NOT NULL").(*parser.SelectStatement)
+	if !ok {
+		t.Fatalf("expected *parser.SelectStatement")
+	}
+
+	and, ok := stmt.Where.(*parser.BinaryExpression)
+	if !ok || and.Operator != "AND" {
+		t.Fatalf("expected top-level AND, got %s", stmt.Where.String())
+	}
+
+	isNull, ok := and.Left.(*parser.IsNullExpression)
+	if !ok || isNull.Negated {
+		t.Errorf("expected IS NULL on the left, got %s", and.Left.String())
+	}
+
+	isNotNull, ok := and.Right.(*parser.IsNullExpression)
+	if !ok || !isNotNull.Negated {
+		t.Errorf("expected IS NOT NULL on the right, got %s", and.Right.String())
+	}
+}
+
+func TestNullLiteral(t *testing.T) {
+	stmt, ok := parseSQL(t, "UPDATE users SET deleted_at = NULL").(*parser.UpdateStatement)
+	if !ok {
+		t.Fatalf("expected *parser.UpdateStatement")
+	}
+
+	lit, ok := stmt.Set[0].Value.(*parser.Literal)
+	if !ok || lit.Value != parser.Null {
+		t.Errorf("expected NULL literal, got %s", stmt.Set[0].Value.String())
+	}
+}
+
+func TestBooleanLiterals(t *testing.T) {
+	stmt, ok := parseSQL(t, "SELECT id FROM users WHERE active = TRUE OR deleted = false").(*parser.SelectStatement)
+	if !ok {
+		t.Fatalf("expected *parser.SelectStatement")
+	}
+
+	where := stmt.Where.(*parser.BinaryExpression)
+	for i, want := range []bool{true, false} {
+		cond := where.Left
+		if i == 1 {
+			cond = where.Right
+		}
+		lit, ok := cond.(*parser.BinaryExpression).Right.(*parser.Literal)
+		if !ok || lit.Value != want {
+			t.Errorf("expected %v literal, got %s", want, cond.String())
+		}
+	}
+}
+
+func TestNullLiteralVersusBracketedColumn(t *testing.T) {
+	stmt, ok := parseSQL(t, "SELECT [null] FROM t WHERE col = NULL").(*parser.SelectStatement)
+	if !ok {
+		t.Fatalf("expected *parser.SelectStatement")
+	}
+
+	if _, ok := stmt.Columns[0].(*parser.ColumnReference); !ok {
+		t.Errorf("expected [null] to be a column reference, got %T", stmt.Columns[0])
+	}
+	lit, ok := stmt.Where.(*parser.BinaryExpression).Right.(*parser.Literal)
+	if !ok || !lit.IsNull() {
+		t.Errorf("expected NULL literal, got %s", stmt.Where.String())
+	}
+}
+
+func TestCaseExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		sql        string
+		hasOperand bool
+		whenCount  int
+		hasElse    bool
+	}{
+		{"searched CASE", "SELECT CASE WHEN x > 1 THEN 'a' WHEN x > 0 THEN 'b' ELSE 'c' END FROM t", false, 2, true},
+		{"simple CASE", "SELECT CASE status WHEN 1 THEN 'active' END FROM t", true, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, ok := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			if !ok {
+				t.Fatalf("expected *parser.SelectStatement")
+			}
+			if stmt.From == nil {
+				t.Fatalf("expected FROM clause after CASE expression")
+			}
+
+			caseExpr, ok := stmt.Columns[0].(*parser.CaseExpression)
+			if !ok {
+				t.Fatalf("expected CaseExpression, got %T", stmt.Columns[0])
+			}
+			if (caseExpr.Operand != nil) != tt.hasOperand {
+				t.Errorf("expected operand presence %v", tt.hasOperand)
+			}
+			if len(caseExpr.WhenClauses) != tt.whenCount {
+				t.Errorf("expected %d WHEN clauses, got %d", tt.whenCount, len(caseExpr.WhenClauses))
+			}
+			if (caseExpr.Else != nil) != tt.hasElse {
+				t.Errorf("expected ELSE presence %v", tt.hasElse)
+			}
+		})
+	}
+}
+
+func TestInValueList(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		not    bool
+		values int
+	}{
+		{"IN list", "SELECT id FROM users WHERE id IN (1, 2, 3)", false, 3},
+		{"NOT IN list", "SELECT id FROM users WHERE status NOT IN ('banned', 'deleted')", true, 2},
+		{"IN expressions", "SELECT id FROM users WHERE id IN (a + 1, b * 2)", false, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, ok := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			if !ok {
+				t.Fatalf("expected *parser.SelectStatement")
+			}
+
+			inExpr, ok := stmt.Where.(*parser.InExpression)
+			if !ok {
+				t.Fatalf("expected InExpression, got %T", stmt.Where)
+			}
+			if inExpr.Not != tt.not {
+				t.Errorf("expected Not=%v, got %v", tt.not, inExpr.Not)
+			}
+			if len(inExpr.Values) != tt.values {
+				t.Errorf("expected %d values, got %d", tt.values, len(inExpr.Values))
+			}
+		})
+	}
+}
+
+func TestRowValueIn(t *testing.T) {
+	sql := "SELECT id FROM orders WHERE (region, status) NOT IN ((1, 'open'), (2, @status)) AND (a, b) IN (SELECT x, y FROM t)"
+
+	stmt, ok := parseSQL(t, sql).(*parser.SelectStatement)
+	if !ok {
+		t.Fatalf("expected *parser.SelectStatement")
+	}
+	and, ok := stmt.Where.(*parser.BinaryExpression)
+	if !ok {
+		t.Fatalf("expected AND, got %T", stmt.Where)
+	}
+
+	inExpr, ok := and.Left.(*parser.InExpression)
+	if !ok || !inExpr.Not {
+		t.Fatalf("expected NOT IN, got %s", and.Left.String())
+	}
+	row, ok := inExpr.Expression.(*parser.RowValueExpression)
+	if !ok || len(row.Values) != 2 {
+		t.Fatalf("expected a row of 2 values, got %s", inExpr.Expression.String())
+	}
+	if len(inExpr.Values) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(inExpr.Values))
+	}
+	for _, value := range inExpr.Values {
+		if other, ok := value.(*parser.RowValueExpression); !ok || len(other.Values) != 2 {
+			t.Errorf("expected a row of 2 values, got %s", value.String())
+		}
+	}
+
+	subquery, ok := and.Right.(*parser.InExpression)
+	if !ok || subquery.Subquery == nil {
+		t.Fatalf("expected IN subquery, got %s", and.Right.String())
+	}
+	if _, ok := subquery.Expression.(*parser.RowValueExpression); !ok {
+		t.Errorf("expected a row on the left of IN, got %T", subquery.Expression)
+	}
+
+	want := "SELECT id FROM orders WHERE (((region, status) NOT IN ((1, 'open'), (2, @status))) AND ((a, b) IN (SELECT x, y FROM t)))"
+	if stmt.String() != want {
+		t.Errorf("expected %q, got %q", want, stmt.String())
+	}
+	if again := parseSQL(t, stmt.String()).String(); again != stmt.String() {
+		t.Errorf("round trip mismatch:\n%s\n%s", stmt.String(), again)
+	}
+
+	// A single parenthesized expression is still just a grouping
+	stmt = parseSQL(t, "SELECT id FROM t WHERE (a) IN (1, 2)").(*parser.SelectStatement)
+	if _, ok := stmt.Where.(*parser.InExpression).Expression.(*parser.ColumnReference); !ok {
+		t.Errorf("expected (a) to parse as a column, got %s", stmt.Where.String())
+	}
+
+	for _, sql := range []string{
+		"SELECT id FROM t WHERE (a, b) IN ((1, 2), (3))",
+		"SELECT id FROM t WHERE (a, b) IN ((1, 2, 3))",
+		"SELECT id FROM t WHERE (a, b) IN (1, 2)",
+		"SELECT id FROM t WHERE (a, ) IN ((1, 2))",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected error for %q", sql)
+		}
+	}
+}
+
+func TestRowValueComparison(t *testing.T) {
+	tests := []struct {
+		sql      string
+		operator string
+		expected string
+	}{
+		{"SELECT id FROM t WHERE (a, b) = (1, 2)", "=", "((a, b) = (1, 2))"},
+		{"SELECT id FROM t WHERE (a, b) > (@a, @b)", ">", "((a, b) > (@a, @b))"},
+		{"SELECT id FROM t WHERE (year, month) <= (2024, 6) AND id > 0", "AND", "(((year, month) <= (2024, 6)) AND (id > 0))"},
+		{"SELECT id FROM t WHERE (a, b) = (SELECT x, y FROM u)", "=", "((a, b) = (SELECT x, y FROM u))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			binary, ok := stmt.Where.(*parser.BinaryExpression)
+			if !ok || binary.Operator != tt.operator {
+				t.Fatalf("expected %s comparison, got %s", tt.operator, stmt.Where.String())
+			}
+			if stmt.Where.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, stmt.Where.String())
+			}
+		})
+	}
+
+	stmt := parseSQL(t, "SELECT id FROM t WHERE (a, b + 1) < (1, 2)").(*parser.SelectStatement)
+	row, ok := stmt.Where.(*parser.BinaryExpression).Left.(*parser.RowValueExpression)
+	if !ok || len(row.Values) != 2 || row.Values[1].String() != "(b + 1)" {
+		t.Errorf("unexpected row value %s", stmt.Where.String())
+	}
+
+	for _, sql := range []string{
+		"SELECT id FROM t WHERE (a, b) = (1, 2, 3)",
+		"SELECT id FROM t WHERE (a, b) = 1",
+		"SELECT id FROM t WHERE 1 < (a, b)",
+		"SELECT id FROM t WHERE (a, b) + (1, 2) = c",
+		"SELECT id FROM t WHERE x = 1 AND (a, b)",
+		"SELECT (1, 2) FROM t",
+		"SELECT id FROM t WHERE (a, b)",
+		"SELECT id FROM t ORDER BY (a, b)",
+		"SELECT COUNT((a, b)) FROM t",
+		"SELECT -(1, 2) FROM t",
+		"SELECT id FROM t WHERE (a, b) LIKE 'x'",
+		"SELECT id FROM t WHERE (a, b) NOT BETWEEN 1 AND 2",
+		"SELECT id FROM t WHERE a BETWEEN (1, 2) AND 3",
+		"SELECT id FROM t WHERE (a, b) IS NULL",
+		"SELECT id FROM t WHERE a IN ((1, 2))",
+		"UPDATE t SET a = (1, 2)",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected error for %q", sql)
+		}
+	}
+}
+
+func TestInSubquery(t *testing.T) {
+	sql := "SELECT name FROM users WHERE active = 1 AND id IN (SELECT user_id FROM orders WHERE total > 100) AND age > 18"
+
+	stmt, ok := parseSQL(t, sql).(*parser.SelectStatement)
+	if !ok {
+		t.Fatalf("expected *parser.SelectStatement")
+	}
+
+	// ((active = 1 AND id IN (...)) AND age > 18)
+	outer, ok := stmt.Where.(*parser.BinaryExpression)
+	if !ok || outer.Operator != "AND" {
+		t.Fatalf("expected top-level AND, got %s", stmt.Where.String())
+	}
+	if outer.Right.String() != "(age > 18)" {
+		t.Errorf("expected outer WHERE to continue after subquery, got %s", outer.Right.String())
+	}
+
+	inner, ok := outer.Left.(*parser.BinaryExpression)
+	if !ok {
+		t.Fatalf("expected nested AND, got %T", outer.Left)
+	}
+
+	inExpr, ok := inner.Right.(*parser.InExpression)
+	if !ok {
+		t.Fatalf("expected InExpression, got %T", inner.Right)
+	}
+	if inExpr.Subquery == nil {
+		t.Fatalf("expected subquery on IN expression")
+	}
+	if len(inExpr.Values) != 0 {
+		t.Errorf("expected no value list alongside subquery, got %d values", len(inExpr.Values))
+	}
+	if inExpr.Subquery.Where == nil {
+		t.Errorf("expected subquery WHERE clause")
+	}
+}
+
+func TestScalarSubquery(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+	}{
+		{"select list", "SELECT (SELECT COUNT(*) FROM orders WHERE user_id = u.id) AS cnt FROM users u"},
+		{"where clause", "SELECT id FROM users u WHERE (SELECT MAX(total) FROM orders o WHERE o.user_id = u.id) > 100"},
+		{"join condition", "SELECT u.id FROM users u JOIN plans p ON p.id = (SELECT MIN(id) FROM plans)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, ok := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			if !ok {
+				t.Fatalf("expected *parser.SelectStatement")
+			}
+			if stmt.From == nil || len(stmt.From.Tables) != 1 {
+				t.Fatalf("expected outer FROM clause to be parsed")
+			}
+		})
+	}
+
+	stmt := parseSQL(t, tests[0].sql).(*parser.SelectStatement)
+	aliased, ok := stmt.Columns[0].(*parser.AliasedExpression)
+	if !ok || aliased.Alias != "cnt" {
+		t.Fatalf("expected column aliased as cnt, got %s", stmt.Columns[0].String())
+	}
+	subquery, ok := aliased.Expression.(*parser.SubqueryExpression)
+	if !ok {
+		t.Fatalf("expected SubqueryExpression, got %T", aliased.Expression)
+	}
+	if subquery.Query.Where == nil {
+		t.Errorf("expected correlated WHERE clause in subquery")
+	}
+}
+
+func TestExistsExpression(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		not  bool
+	}{
+		{"EXISTS", "SELECT id FROM users u WHERE EXISTS (SELECT 1 FROM orders o WHERE o.uid = u.id)", false},
+		{"NOT EXISTS", "SELECT id FROM users u WHERE NOT EXISTS (SELECT 1 FROM orders o WHERE o.uid = u.id)", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, ok := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			if !ok {
+				t.Fatalf("expected *parser.SelectStatement")
+			}
+
+			exists, ok := stmt.Where.(*parser.ExistsExpression)
+			if !ok {
+				t.Fatalf("expected ExistsExpression, got %T", stmt.Where)
+			}
+			if exists.Not != tt.not {
+				t.Errorf("expected Not=%v, got %v", tt.not, exists.Not)
+			}
+			if exists.Subquery == nil || exists.Subquery.Where == nil {
+				t.Errorf("expected correlated subquery with WHERE clause")
+			}
+		})
+	}
+}
+
+func TestOffsetFetch(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		offset   int
+		fetch    int
+		hasFetch bool
+	}{
+		{"OFFSET and FETCH", "SELECT id FROM users ORDER BY id OFFSET 10 ROWS FETCH NEXT 20 ROWS ONLY", 10, 20, true},
+		{"OFFSET only", "SELECT id FROM users ORDER BY id DESC OFFSET 5 ROWS", 5, 0, false},
+		{"singular ROW and FIRST", "SELECT id FROM users ORDER BY id OFFSET 1 ROW FETCH FIRST 1 ROW ONLY", 1, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, ok := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			if !ok {
+				t.Fatalf("expected *parser.SelectStatement")
+			}
+			if stmt.Offset == nil {
+				t.Fatalf("expected OFFSET clause")
+			}
+			if stmt.Offset.Offset != tt.offset || stmt.Offset.Fetch != tt.fetch || stmt.Offset.HasFetch != tt.hasFetch {
+				t.Errorf("unexpected OFFSET/FETCH: %+v", stmt.Offset)
+			}
+		})
+	}
+}
+
+func TestWindowFunctions(t *testing.T) {
+	tests := []struct {
+		name        string
+		sql         string
+		partitionBy int
+		orderBy     int
+	}{
+		{"partition and order", "SELECT ROW_NUMBER() OVER (PARTITION BY dept ORDER BY salary DESC) AS rn FROM emp", 1, 1},
+		{"order only", "SELECT LAG(salary, 1) OVER (ORDER BY hired) FROM emp", 0, 1},
+		{"multiple partitions", "SELECT SUM(amount) OVER (PARTITION BY region, year) FROM sales", 2, 0},
+		{"empty OVER", "SELECT COUNT(*) OVER () FROM emp", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, ok := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			if !ok {
+				t.Fatalf("expected *parser.SelectStatement")
+			}
+
+			col := stmt.Columns[0]
+			if aliased, ok := col.(*parser.AliasedExpression); ok {
+				col = aliased.Expression
+			}
+
+			fn, ok := col.(*parser.FunctionCall)
+			if !ok || fn.Over == nil {
+				t.Fatalf("expected window function, got %s", col.String())
+			}
+			if len(fn.Over.PartitionBy) != tt.partitionBy {
+				t.Errorf("expected %d PARTITION BY expressions, got %d", tt.partitionBy, len(fn.Over.PartitionBy))
+			}
+			if len(fn.Over.OrderBy) != tt.orderBy {
+				t.Errorf("expected %d ORDER BY items, got %d", tt.orderBy, len(fn.Over.OrderBy))
+			}
+			if stmt.From == nil {
+				t.Errorf("expected FROM clause after window function")
+			}
+		})
+	}
+}
+
+func TestAggregateDistinct(t *testing.T) {
+	tests := []struct {
+		sql      string
+		distinct bool
+		argType  string
+	}{
+		{"SELECT COUNT(DISTINCT user_id) FROM orders", true, "ColumnReference"},
+		{"SELECT COUNT(*) FROM orders", false, "StarExpression"},
+		{"SELECT COUNT(user_id) FROM orders", false, "ColumnReference"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+
+			fn, ok := stmt.Columns[0].(*parser.FunctionCall)
+			if !ok {
+				t.Fatalf("expected FunctionCall, got %T", stmt.Columns[0])
+			}
+			if fn.Distinct != tt.distinct {
+				t.Errorf("expected Distinct=%v, got %v", tt.distinct, fn.Distinct)
+			}
+			if len(fn.Arguments) != 1 || fn.Arguments[0].Type() != tt.argType {
+				t.Errorf("expected single %s argument, got %v", tt.argType, fn.Arguments)
+			}
+		})
+	}
+}
+
+func TestCrossJoin(t *testing.T) {
+	sql := "SELECT * FROM a CROSS JOIN b INNER JOIN c ON c.id = a.id LEFT JOIN d ON d.id = c.id"
+
+	stmt, ok := parseSQL(t, sql).(*parser.SelectStatement)
+	if !ok {
+		t.Fatalf("expected *parser.SelectStatement")
+	}
+
+	expected := []struct {
+		joinType     string
+		table        string
+		hasCondition bool
+	}{
+		{"CROSS", "b", false},
+		{"INNER", "c", true},
+		{"LEFT", "d", true},
+	}
+
+	if len(stmt.Joins) != len(expected) {
+		t.Fatalf("expected %d joins, got %d", len(expected), len(stmt.Joins))
+	}
+
+	for i, exp := range expected {
+		join := stmt.Joins[i]
+		if join.JoinType != exp.joinType || join.Table.Name != exp.table {
+			t.Errorf("join[%d]: expected %s JOIN %s, got %s JOIN %s", i, exp.joinType, exp.table, join.JoinType, join.Table.Name)
+		}
+		if (join.Condition != nil) != exp.hasCondition {
+			t.Errorf("join[%d]: expected condition presence %v", i, exp.hasCondition)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name        string
+		sql         string
+		joinType    string
+		isFunction  bool
+		isSubquery  bool
+		expectAlias string
+	}{
+		{"CROSS APPLY function", "SELECT s.value FROM t CROSS APPLY dbo.SplitString(t.tags) AS s", "CROSS APPLY", true, false, "s"},
+		{"OUTER APPLY derived table", "SELECT o.total FROM users u OUTER APPLY (SELECT TOP 1 total FROM orders WHERE user_id = u.id) o", "OUTER APPLY", false, true, "o"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, ok := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			if !ok {
+				t.Fatalf("expected *parser.SelectStatement")
+			}
+			if len(stmt.Joins) != 1 {
+				t.Fatalf("expected 1 join, got %d", len(stmt.Joins))
+			}
+
+			join := stmt.Joins[0]
+			if join.JoinType != tt.joinType {
+				t.Errorf("expected %s, got %s", tt.joinType, join.JoinType)
+			}
+			if join.Condition != nil {
+				t.Errorf("expected no ON condition for APPLY")
+			}
+			if (join.Table.Function != nil) != tt.isFunction {
+				t.Errorf("expected function source %v", tt.isFunction)
+			}
+			if (join.Table.Subquery != nil) != tt.isSubquery {
+				t.Errorf("expected subquery source %v", tt.isSubquery)
+			}
+			if join.Table.Alias != tt.expectAlias {
+				t.Errorf("expected alias %s, got %s", tt.expectAlias, join.Table.Alias)
+			}
+		})
+	}
+}
+
+func TestLeftOuterJoin(t *testing.T) {
+	stmt := parseSQL(t, "SELECT * FROM a LEFT OUTER JOIN b ON a.id = b.id").(*parser.SelectStatement)
+
+	if len(stmt.Joins) != 1 || stmt.Joins[0].JoinType != "LEFT" {
+		t.Fatalf("expected a LEFT join, got %+v", stmt.Joins)
+	}
+}
+
+func TestDerivedTables(t *testing.T) {
+	sql := "SELECT t.id, s.total FROM (SELECT id FROM users WHERE active = 1) AS t JOIN (SELECT user_id, SUM(amount) AS total FROM orders) s ON s.user_id = t.id"
+
+	stmt, ok := parseSQL(t, sql).(*parser.SelectStatement)
+	if !ok {
+		t.Fatalf("expected *parser.SelectStatement")
+	}
+
+	from := stmt.From.Tables[0]
+	if from.Subquery == nil || from.Alias != "t" {
+		t.Errorf("expected derived table aliased t in FROM, got %+v", from)
+	}
+
+	if len(stmt.Joins) != 1 {
+		t.Fatalf("expected 1 join, got %d", len(stmt.Joins))
+	}
+	joined := stmt.Joins[0].Table
+	if joined.Subquery == nil || joined.Alias != "s" {
+		t.Errorf("expected derived table aliased s in JOIN, got %+v", joined)
+	}
+	if stmt.Joins[0].Condition == nil {
+		t.Errorf("expected ON condition after derived table")
+	}
+}
+
+func TestDerivedTableRequiresAlias(t *testing.T) {
+	p := parser.New("SELECT id FROM (SELECT id FROM users) WHERE id > 1")
+	_, err := p.ParseStatement()
+	if err == nil {
+		t.Fatalf("expected error for derived table without alias")
+	}
+	if !strings.Contains(err.Error(), "alias") {
+		t.Errorf("expected alias error, got %v", err)
+	}
+}
+
+func TestValuesTable(t *testing.T) {
+	sql := "SELECT t.id, t.name FROM (VALUES (1, 'a'), (2, 'b')) AS t(id, name) JOIN users u ON u.id = t.id"
+	stmt := parseSQL(t, sql).(*parser.SelectStatement)
+
+	table := stmt.From.Tables[0]
+	if table.Values == nil || table.Alias != "t" {
+		t.Fatalf("expected VALUES table aliased t, got %+v", table)
+	}
+	if !reflect.DeepEqual(table.Columns, []string{"id", "name"}) {
+		t.Errorf("expected columns [id name], got %v", table.Columns)
+	}
+	if len(table.Values.Rows) != 2 || table.Values.Rows[1][1].String() != "'b'" {
+		t.Errorf("unexpected rows %s", table.Values)
+	}
+	if got := stmt.String(); got != "SELECT t.id, t.name FROM (VALUES (1, 'a'), (2, 'b')) AS t(id, name) INNER JOIN users AS u ON (u.id = t.id)" {
+		t.Errorf("unexpected String(): %s", got)
+	}
+
+	// A derived table may name its columns too
+	derived := parseSQL(t, "SELECT d.n FROM (SELECT COUNT(*) FROM users) d(n)").(*parser.SelectStatement)
+	if !reflect.DeepEqual(derived.From.Tables[0].Columns, []string{"n"}) {
+		t.Errorf("expected derived table columns [n], got %v", derived.From.Tables[0].Columns)
+	}
+
+	for _, sql := range []string{
+		"SELECT * FROM (VALUES (1, 'a'))",
+		"SELECT * FROM (VALUES (1, 'a')) AS t",
+		"SELECT * FROM (VALUES (1, 'a'), (2)) AS t(id, name)",
+		"SELECT * FROM (VALUES (1, 'a') AS t(id, name)",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected an error for %q", sql)
+		}
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	sql := "SELECT id FROM a UNION SELECT id FROM b UNION ALL SELECT id FROM c EXCEPT SELECT id FROM d"
+
+	outer, ok := parseSQL(t, sql).(*parser.SetOperation)
+	if !ok {
+		t.Fatalf("expected *parser.SetOperation")
+	}
+
+	// (((a UNION b) UNION ALL c) EXCEPT d)
+	if outer.Operator != "EXCEPT" {
+		t.Errorf("expected outermost EXCEPT, got %s", outer.Operator)
+	}
+	if right, ok := outer.Right.(*parser.SelectStatement); !ok || right.From.Tables[0].Name != "d" {
+		t.Errorf("expected right side to select from d")
+	}
+
+	middle, ok := outer.Left.(*parser.SetOperation)
+	if !ok || middle.Operator != "UNION ALL" {
+		t.Fatalf("expected UNION ALL on the left, got %T", outer.Left)
+	}
+
+	inner, ok := middle.Left.(*parser.SetOperation)
+	if !ok || inner.Operator != "UNION" {
+		t.Fatalf("expected innermost UNION, got %T", middle.Left)
+	}
+	if _, ok := inner.Left.(*parser.SelectStatement); !ok {
+		t.Errorf("expected SELECT as innermost left operand")
+	}
+
+	intersect, ok := parseSQL(t, "SELECT id FROM a INTERSECT SELECT id FROM b").(*parser.SetOperation)
+	if !ok || intersect.Operator != "INTERSECT" {
+		t.Errorf("expected INTERSECT set operation")
+	}
+}
+
+func TestCommonTableExpressions(t *testing.T) {
+	sql := `WITH active (id, name) AS (SELECT id, name FROM users WHERE active = 1),
+		big_orders AS (SELECT user_id FROM orders WHERE total > 100)
+		SELECT * FROM active a JOIN big_orders b ON b.user_id = a.id`
+
+	stmt, ok := parseSQL(t, sql).(*parser.SelectStatement)
+	if !ok {
+		t.Fatalf("expected *parser.SelectStatement")
+	}
+	if stmt.With == nil || len(stmt.With.CTEs) != 2 {
+		t.Fatalf("expected 2 CTEs, got %+v", stmt.With)
+	}
+	if stmt.With.Recursive {
+		t.Errorf("expected non-recursive WITH clause")
+	}
+
+	first := stmt.With.CTEs[0]
+	if first.Name != "active" || len(first.Columns) != 2 {
+		t.Errorf("unexpected first CTE: %s %v", first.Name, first.Columns)
+	}
+	if stmt.With.CTEs[1].Name != "big_orders" {
+		t.Errorf("unexpected second CTE name: %s", stmt.With.CTEs[1].Name)
+	}
+	if len(stmt.Joins) != 1 {
+		t.Errorf("expected main query join to be parsed")
+	}
+}
+
+func TestRecursiveCTE(t *testing.T) {
+	sql := `WITH RECURSIVE tree AS (
+		SELECT id, parent_id FROM nodes WHERE parent_id IS NULL
+		UNION ALL
+		SELECT n.id, n.parent_id FROM nodes n JOIN tree t ON n.parent_id = t.id)
+		SELECT id FROM tree`
+
+	stmt, ok := parseSQL(t, sql).(*parser.SelectStatement)
+	if !ok {
+		t.Fatalf("expected *parser.SelectStatement")
+	}
+	if stmt.With == nil || !stmt.With.Recursive {
+		t.Fatalf("expected recursive WITH clause")
+	}
+	if _, ok := stmt.With.CTEs[0].Query.(*parser.SetOperation); !ok {
+		t.Errorf("expected UNION ALL body, got %T", stmt.With.CTEs[0].Query)
+	}
+}
+
+func TestCTEWithModificationStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+	}{
+		{"INSERT", "WITH src AS (SELECT id FROM staging) INSERT INTO target (id) SELECT id FROM src"},
+		{"UPDATE", "WITH src AS (SELECT id FROM staging) UPDATE target SET flag = 1 WHERE id IN (SELECT id FROM src)"},
+		{"DELETE", "WITH src AS (SELECT id FROM staging) DELETE FROM target WHERE id IN (SELECT id FROM src)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var with *parser.WithClause
+			switch s := parseSQL(t, tt.sql).(type) {
+			case *parser.InsertStatement:
+				with = s.With
+			case *parser.UpdateStatement:
+				with = s.With
+			case *parser.DeleteStatement:
+				with = s.With
+			default:
+				t.Fatalf("unexpected statement type %T", s)
+			}
+			if with == nil || len(with.CTEs) != 1 || with.CTEs[0].Name != "src" {
+				t.Errorf("expected WITH clause with CTE src, got %+v", with)
+			}
+		})
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	tests := []string{
+		"SELECT DISTINCT TOP 10 u.id, u.name AS username FROM users AS u INNER JOIN orders AS o ON u.id = o.user_id WHERE u.active = 1 AND o.total > 100 ORDER BY u.name DESC",
+		"SELECT name FROM users WHERE name = 'O\\'Brien' OR email IS NOT NULL",
+		"SELECT dept, COUNT(DISTINCT id) FROM employees GROUP BY dept HAVING COUNT(*) > 5",
+		"SELECT id, ROW_NUMBER() OVER (PARTITION BY dept ORDER BY salary DESC) FROM employees",
+		"SELECT id FROM orders WHERE status NOT IN ('closed', 'void') AND total BETWEEN 10 AND 20",
+		"SELECT id FROM users WHERE EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id)",
+		"SELECT CASE WHEN a > 1 THEN 'big' ELSE 'small' END FROM t",
+		"SELECT id FROM t ORDER BY id OFFSET 10 ROWS FETCH NEXT 5 ROWS ONLY",
+		"SELECT id FROM t LIMIT 5 OFFSET 10",
+		"SELECT d.id FROM (SELECT id FROM t) AS d CROSS JOIN other CROSS APPLY dbo.Split(d.id) AS s",
+		"SELECT id FROM a UNION ALL SELECT id FROM b",
+		"WITH recent (id) AS (SELECT id FROM orders) SELECT id FROM recent",
+		"INSERT INTO users (id, name) VALUES (1, 'a'), (2, 'b')",
+		"INSERT INTO archive SELECT id FROM users",
+		"UPDATE users SET name = 'x', age = age + 1 WHERE id = 1",
+		"DELETE TOP (5) FROM users WHERE id = 1",
+	}
+
+	for _, sql := range tests {
+		t.Run(sql, func(t *testing.T) {
+			first := parseSQL(t, sql).String()
+			second := parseSQL(t, first).String()
+			if first != second {
+				t.Errorf("round trip mismatch:\n first: %s\nsecond: %s", first, second)
+			}
+		})
+	}
+}
+
+func TestStringKeepsFloatsAndDeleteTopPercent(t *testing.T) {
+	stmt := parseSQL(t, "SELECT 1.0, 1e3, 2.5E-3 FROM t")
+	if want := "SELECT 1.0, 1000.0, 0.0025 FROM t"; stmt.String() != want {
+		t.Errorf("expected %q, got %q", want, stmt.String())
+	}
+	if reparsed := parseSQL(t, stmt.String()); !parser.Equal(stmt, reparsed) {
+		t.Errorf("round trip changed the statement: %q", reparsed.String())
+	}
+
+	sql := "DELETE TOP (10) PERCENT FROM t WHERE (id > 1)"
+	if got := parseSQL(t, sql).String(); got != sql {
+		t.Errorf("expected %q, got %q", sql, got)
+	}
+}
+
+func TestStringQuotesIdentifiers(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{
+			"SELECT [Unit Price], o.[select] AS [total]] due] FROM [Order Details] AS o",
+			"SELECT [Unit Price], o.[select] AS [total]] due] FROM [Order Details] AS o",
+		},
+		{
+			"SELECT [id], [name] FROM [dbo].[users] AS [u]",
+			"SELECT id, name FROM dbo.users AS u",
+		},
+		{
+			"WITH [my cte] ([from]) AS (SELECT 1) SELECT [from] FROM [my cte]",
+			"WITH [my cte] ([from]) AS (SELECT 1) SELECT [from] FROM [my cte]",
+		},
+		{
+			"INSERT INTO #tmp ([order], qty) VALUES (1, 2)",
+			"INSERT INTO #tmp ([order], qty) VALUES (1, 2)",
+		},
+		{
+			"CREATE INDEX [ix by date] ON t ([date col] DESC) INCLUDE ([where])",
+			"CREATE INDEX [ix by date] ON t ([date col] DESC) INCLUDE ([where])",
+		},
+		{
+			"SELECT [\"a\"], [`b`] FROM t",
+			"SELECT [\"a\"], [`b`] FROM t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql)
+			if got := stmt.String(); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+			if reparsed := parseSQL(t, stmt.String()); !parser.Equal(stmt, reparsed) {
+				t.Errorf("round trip changed the statement: %q", reparsed.String())
+			}
+		})
+	}
+}
+
+func TestQuotedIdentifierOption(t *testing.T) {
+	sql := `SELECT "my col", t."id" FROM "Order Details" AS t`
+	opts := lexer.LexerOptions{QuotedIdentifier: true}
+
+	stmt, err := parser.NewWithOptions(context.Background(), sql, opts).ParseStatement()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	selectStmt := stmt.(*parser.SelectStatement)
+	if column, ok := selectStmt.Columns[0].(*parser.ColumnReference); !ok || column.Column != "my col" {
+		t.Errorf("expected column my col, got %#v", selectStmt.Columns[0])
+	}
+	if table := selectStmt.From.Tables[0]; table.Name != "Order Details" || table.Alias != "t" {
+		t.Errorf("unexpected table %+v", table)
+	}
+	if want := "SELECT [my col], t.id FROM [Order Details] AS t"; stmt.String() != want {
+		t.Errorf("expected %q, got %q", want, stmt.String())
+	}
+
+	// Without the option "..." is a string literal in SQL Server
+	stmt = parseSQL(t, `SELECT "my col" FROM t`)
+	if _, ok := stmt.(*parser.SelectStatement).Columns[0].(*parser.Literal); !ok {
+		t.Errorf("expected a string literal, got %#v", stmt.(*parser.SelectStatement).Columns[0])
+	}
+}
+
+func TestStringQuotesLiteralsAndParenthesizesBinaryExpressions(t *testing.T) {
+	stmt := parseSQL(t, "SELECT id FROM users WHERE name = 'bob' AND (age > 1 OR age < 0)")
+	want := "SELECT id FROM users WHERE ((name = 'bob') AND ((age > 1) OR (age < 0)))"
+	if got := stmt.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestParseProgram(t *testing.T) {
+	sql := `SELECT id FROM users;
+		INSERT INTO logs (msg) VALUES ('hi');;
+		UPDATE users SET active = 0 WHERE id = 1;
+		DELETE FROM logs`
+
+	program, err := parser.New(sql).ParseProgram()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantTypes := []string{"SelectStatement", "InsertStatement", "UpdateStatement", "DeleteStatement"}
+	if len(program.Statements) != len(wantTypes) {
+		t.Fatalf("expected %d statements, got %d", len(wantTypes), len(program.Statements))
+	}
+	for i, want := range wantTypes {
+		if got := program.Statements[i].Type(); got != want {
+			t.Errorf("statement %d: expected %s, got %s", i, want, got)
+		}
+	}
+}
+
+func TestParseProgramRecoversFromErrors(t *testing.T) {
+	sql := "SELECT id FROM users; SELECT FROM WHERE; DROPP TABLE x; SELECT name FROM roles"
+
+	p := parser.New(sql)
+	program, err := p.ParseProgram()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("expected 2 valid statements, got %d", len(program.Statements))
+	}
+	if got := program.Statements[1].String(); got != "SELECT name FROM roles" {
+		t.Errorf("unexpected last statement %q", got)
+	}
+	if !strings.Contains(err.Error(), "unsupported statement type: DROPP") {
+		t.Errorf("expected error for DROPP statement, got %v", err)
+	}
+	if len(p.Errors()) < 2 {
+		t.Errorf("expected at least 2 recorded errors, got %v", p.Errors())
+	}
+}
+
+func TestStatementIterator(t *testing.T) {
+	sql := "SELECT id FROM users;; SELECT FROM WHERE; UPDATE users SET a = 1; DELETE FROM logs"
+
+	it := parser.New(sql).Statements()
+	var types []string
+	var errs []error
+	for {
+		stmt, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		types = append(types, stmt.Type())
+	}
+
+	want := []string{"SelectStatement", "UpdateStatement", "DeleteStatement"}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, types)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	var parseErr *parser.ParseError
+	if !errors.As(errs[0], &parseErr) {
+		t.Errorf("expected *parser.ParseError, got %T", errs[0])
+	}
+
+	// The iterator stays exhausted
+	if _, err := it.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF after the last statement, got %v", err)
+	}
+}
+
+func TestUnaryExpressions(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT -price FROM t", "SELECT (-price) FROM t"},
+		{"SELECT -a + b FROM t", "SELECT ((-a) + b) FROM t"},
+		{"SELECT +a * -b FROM t", "SELECT ((+a) * (-b)) FROM t"},
+		{"SELECT id FROM t WHERE NOT active", "SELECT id FROM t WHERE (NOT active)"},
+		{"SELECT id FROM t WHERE NOT a AND b", "SELECT id FROM t WHERE ((NOT a) AND b)"},
+		{"SELECT id FROM t WHERE NOT a = 1", "SELECT id FROM t WHERE (NOT (a = 1))"},
+		{"SELECT id FROM t WHERE NOT NOT x", "SELECT id FROM t WHERE (NOT (NOT x))"},
+		{"SELECT id FROM t ORDER BY -score", "SELECT id FROM t ORDER BY (-score) ASC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			if got := parseSQL(t, tt.sql).String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParameters(t *testing.T) {
+	stmt, ok := parseSQL(t, "SELECT id FROM users WHERE name = @name AND age > ? AND status IN (?, ?)").(*parser.SelectStatement)
+	if !ok {
+		t.Fatalf("expected *parser.SelectStatement")
+	}
+
+	var params []*parser.Parameter
+	var collect func(expr parser.Expression)
+	collect = func(expr parser.Expression) {
+		switch e := expr.(type) {
+		case *parser.Parameter:
+			params = append(params, e)
+		case *parser.BinaryExpression:
+			collect(e.Left)
+			collect(e.Right)
+		case *parser.InExpression:
+			for _, v := range e.Values {
+				collect(v)
+			}
+		}
+	}
+	collect(stmt.Where)
+
+	if len(params) != 4 {
+		t.Fatalf("expected 4 parameters, got %d", len(params))
+	}
+	if params[0].Name != "@name" || params[0].Position != 0 {
+		t.Errorf("expected named parameter @name, got %+v", params[0])
+	}
+	for i, param := range params[1:] {
+		if param.Name != "" || param.Position != i+1 {
+			t.Errorf("expected placeholder ordinal %d, got %+v", i+1, param)
+		}
+	}
+}
+
+func TestParseWithComments(t *testing.T) {
+	sql := `/* report query */
+		SELECT id, -- primary key
+		       name /* display /* nested */ name */
+		FROM users -- source table
+		WHERE active = 1`
+
+	if got := parseSQL(t, sql).String(); got != "SELECT id, name FROM users WHERE (active = 1)" {
+		t.Errorf("unexpected statement %q", got)
+	}
+}
+
+func TestBracketedIdentifierReferences(t *testing.T) {
+	stmt, ok := parseSQL(t, "SELECT [Order Details].[Unit Price] FROM [Order Details] WHERE [Order Details].[Quantity]>1").(*parser.SelectStatement)
+	if !ok {
+		t.Fatalf("expected *parser.SelectStatement")
+	}
+
+	col, ok := stmt.Columns[0].(*parser.ColumnReference)
+	if !ok || col.Table != "Order Details" || col.Column != "Unit Price" {
+		t.Errorf("unexpected column %+v", stmt.Columns[0])
+	}
+	if stmt.From.Tables[0].Name != "Order Details" {
+		t.Errorf("expected table Order Details, got %q", stmt.From.Tables[0].Name)
+	}
+	if stmt.Where == nil {
+		t.Errorf("expected WHERE clause")
+	}
+}
+
+func TestCastAndConvert(t *testing.T) {
+	tests := []struct {
+		sql      string
+		function string
+		dataType parser.DataType
+		hasStyle bool
+	}{
+		{"SELECT CAST(price AS INT) FROM t", "CAST", parser.DataType{Name: "INT"}, false},
+		{"SELECT cast(name AS varchar(50)) FROM t", "CAST", parser.DataType{Name: "VARCHAR", Length: 50}, false},
+		{"SELECT TRY_CAST(amount AS DECIMAL(10,2)) FROM t", "TRY_CAST", parser.DataType{Name: "DECIMAL", Precision: 10, Scale: 2}, false},
+		{"SELECT CONVERT(VARCHAR(10), created, 120) FROM t", "CONVERT", parser.DataType{Name: "VARCHAR", Length: 10}, true},
+		{"SELECT CONVERT(NVARCHAR(MAX), body) FROM t", "CONVERT", parser.DataType{Name: "NVARCHAR", Max: true}, false},
+		{"SELECT try_convert(DATE, created, 112) FROM t", "TRY_CONVERT", parser.DataType{Name: "DATE"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			cast, ok := stmt.Columns[0].(*parser.CastExpression)
+			if !ok {
+				t.Fatalf("expected *parser.CastExpression, got %T", stmt.Columns[0])
+			}
+			if cast.Function != tt.function {
+				t.Errorf("expected %s, got %s", tt.function, cast.Function)
+			}
+			if cast.Try != strings.HasPrefix(tt.function, "TRY_") {
+				t.Errorf("unexpected Try %v for %s", cast.Try, cast.Function)
+			}
+			got := *cast.DataType
+			if got.Name != tt.dataType.Name || got.Length != tt.dataType.Length || got.Max != tt.dataType.Max ||
+				got.Precision != tt.dataType.Precision || got.Scale != tt.dataType.Scale {
+				t.Errorf("expected data type %s, got %s", tt.dataType.String(), got.String())
+			}
+			if (cast.Style != nil) != tt.hasStyle {
+				t.Errorf("expected style %v, got %v", tt.hasStyle, cast.Style)
+			}
+
+			if again := parseSQL(t, stmt.String()).String(); again != stmt.String() {
+				t.Errorf("round trip mismatch: %q vs %q", stmt.String(), again)
+			}
+		})
+	}
+}
+
+func TestCastErrors(t *testing.T) {
+	for _, sql := range []string{
+		"SELECT CAST(price INT) FROM t",
+		"SELECT CAST(price AS VARCHAR(abc)) FROM t",
+		"SELECT CONVERT(INT price) FROM t",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected error for %q", sql)
+		}
+	}
+}
+
+func TestOptionClause(t *testing.T) {
+	sql := "SELECT id FROM users WHERE name = @name ORDER BY id " +
+		"OPTION (MAXDOP 4, recompile, OPTIMIZE FOR UNKNOWN, HASH JOIN, LABEL = 'nightly', " +
+		"OPTIMIZE FOR (@name = 'a', @id UNKNOWN), USE HINT ('DISABLE_OPTIMIZER_ROWGOAL'))"
+
+	stmt, ok := parseSQL(t, sql).(*parser.SelectStatement)
+	if !ok {
+		t.Fatalf("expected *parser.SelectStatement")
+	}
+	if stmt.Option == nil || len(stmt.Option.Hints) != 7 {
+		t.Fatalf("expected 7 query hints, got %v", stmt.Option)
+	}
+
+	expected := []string{
+		"MAXDOP 4",
+		"RECOMPILE",
+		"OPTIMIZE FOR UNKNOWN",
+		"HASH JOIN",
+		"LABEL = 'nightly'",
+		"OPTIMIZE FOR (@name = 'a', @id UNKNOWN)",
+		"USE HINT ('DISABLE_OPTIMIZER_ROWGOAL')",
+	}
+	for i, hint := range stmt.Option.Hints {
+		if hint.String() != expected[i] {
+			t.Errorf("hint %d: expected %q, got %q", i, expected[i], hint.String())
+		}
+	}
+	if maxdop := stmt.Option.Hints[0]; maxdop.Name != "MAXDOP" || maxdop.Value == nil || maxdop.Equals {
+		t.Errorf("unexpected MAXDOP hint %+v", maxdop)
+	}
+	if variables := stmt.Option.Hints[5].Variables; len(variables) != 2 || variables[1].Value != nil {
+		t.Errorf("unexpected OPTIMIZE FOR variables %v", variables)
+	}
+
+	for _, sql := range []string{
+		"INSERT INTO users (id) VALUES (1) OPTION (MAXRECURSION 0)",
+		"UPDATE users SET name = 'x' WHERE id = 1 OPTION (FAST 10)",
+		"DELETE FROM users WHERE id = 1 OPTION (LOOP JOIN, FORCE ORDER)",
+		"SELECT id FROM a UNION SELECT id FROM b OPTION (MERGE UNION)",
+		"SELECT 1 OPTION (RECOMPILE)",
+	} {
+		stmt := parseSQL(t, sql)
+		if !strings.HasSuffix(stmt.String(), sql[strings.Index(sql, " OPTION"):]) {
+			t.Errorf("expected %q to keep its OPTION clause, got %q", sql, stmt.String())
+		}
+	}
+
+	for _, sql := range []string{
+		"SELECT id FROM users OPTION ()",
+		"SELECT id FROM users OPTION (MAXDOP 4",
+		"SELECT id FROM users OPTION (OPTIMIZE FOR (name = 1))",
+		"SELECT id FROM users OPTION (OPTIMIZE FOR (@name))",
+		"SELECT id FROM users OPTION (USE HINT ('a')",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected error for %q", sql)
+		}
+	}
+}
+
+func TestForClause(t *testing.T) {
+	sql := "SELECT id, name FROM users u ORDER BY id OFFSET 10 ROWS FETCH NEXT 5 ROWS ONLY " +
+		"for json path, ROOT('users'), INCLUDE_NULL_VALUES OPTION (RECOMPILE)"
+
+	stmt, ok := parseSQL(t, sql).(*parser.SelectStatement)
+	if !ok {
+		t.Fatalf("expected *parser.SelectStatement")
+	}
+	if stmt.For == nil || stmt.For.Format != "JSON" || stmt.For.Mode != "PATH" || len(stmt.For.Options) != 2 {
+		t.Fatalf("unexpected FOR clause %v", stmt.For)
+	}
+	if root := stmt.For.Options[0]; root.Name != "ROOT" || root.Argument == nil {
+		t.Errorf("unexpected ROOT option %+v", root)
+	}
+	if stmt.Offset == nil || stmt.Option == nil {
+		t.Errorf("expected OFFSET and OPTION around the FOR clause")
+	}
+	if want := "FOR JSON PATH, ROOT('users'), INCLUDE_NULL_VALUES"; stmt.For.String() != want {
+		t.Errorf("expected %q, got %q", want, stmt.For.String())
+	}
+
+	for _, sql := range []string{
+		"SELECT id FROM users FOR JSON AUTO",
+		"SELECT id FROM users FOR JSON PATH, WITHOUT_ARRAY_WRAPPER",
+		"SELECT id FROM users FOR XML RAW('user'), ROOT('users'), ELEMENTS XSINIL",
+		"SELECT id FROM users FOR XML AUTO, TYPE, BINARY BASE64",
+		"SELECT 1 AS tag, NULL AS parent FOR XML EXPLICIT",
+		"SELECT STUFF((SELECT (',' + name) FROM users FOR XML PATH('')), 1, 1, '') AS names",
+	} {
+		stmt := parseSQL(t, sql)
+		if stmt.String() != sql {
+			t.Errorf("expected %q, got %q", sql, stmt.String())
+		}
+	}
+
+	for _, sql := range []string{
+		"SELECT id FROM users FOR JSON RAW",
+		"SELECT id FROM users FOR JSON PATH('user')",
+		"SELECT id FROM users FOR JSON PATH, ELEMENTS",
+		"SELECT id FROM users FOR XML AUTO('user')",
+		"SELECT id FROM users FOR XML PATH, ROOT('users'",
+		"SELECT id FROM users FOR XML PATH,",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected error for %q", sql)
+		}
+	}
+}
+
+func TestCreateTable(t *testing.T) {
+	sql := `CREATE TABLE dbo.orders (
+		id INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+		customer_id INT NOT NULL,
+		code VARCHAR(20) UNIQUE,
+		amount DECIMAL(10,2) DEFAULT 0,
+		note NVARCHAR(MAX) NULL,
+		created DATETIME DEFAULT GETDATE(),
+		CONSTRAINT fk_customer FOREIGN KEY (customer_id) REFERENCES dbo.customers (id),
+		UNIQUE (customer_id, code)
+	)`
+
+	stmt, ok := parseSQL(t, sql).(*parser.CreateTableStatement)
+	if !ok {
+		t.Fatalf("expected *parser.CreateTableStatement")
+	}
+
+	if stmt.Table.Schema != "dbo" || stmt.Table.Name != "orders" {
+		t.Errorf("unexpected table %s", stmt.Table.String())
+	}
+	if len(stmt.Columns) != 6 || len(stmt.Constraints) != 2 {
+		t.Fatalf("expected 6 columns and 2 constraints, got %d and %d", len(stmt.Columns), len(stmt.Constraints))
+	}
+
+	id := stmt.Columns[0]
+	if id.Identity == nil || !id.NotNull || !id.PrimaryKey || id.DataType.Name != "INT" {
+		t.Errorf("unexpected id column %s", id.String())
+	}
+	if !stmt.Columns[2].Unique || stmt.Columns[2].DataType.Length != 20 {
+		t.Errorf("unexpected code column %s", stmt.Columns[2].String())
+	}
+	if stmt.Columns[3].Default == nil || stmt.Columns[3].DataType.Scale != 2 {
+		t.Errorf("unexpected amount column %s", stmt.Columns[3].String())
+	}
+	if !stmt.Columns[4].Null || stmt.Columns[4].NotNull {
+		t.Errorf("expected explicit NULL on note, got %s", stmt.Columns[4].String())
+	}
+
+	fk := stmt.Constraints[0]
+	if fk.Name != "fk_customer" || fk.Kind != "FOREIGN KEY" || fk.References.Name != "customers" || fk.ReferencedColumns[0] != "id" {
+		t.Errorf("unexpected foreign key %s", fk.String())
+	}
+	if stmt.Constraints[1].Kind != "UNIQUE" || len(stmt.Constraints[1].Columns) != 2 {
+		t.Errorf("unexpected unique constraint %s", stmt.Constraints[1].String())
+	}
+
+	if again := parseSQL(t, stmt.String()).String(); again != stmt.String() {
+		t.Errorf("round trip mismatch:\n%s\n%s", stmt.String(), again)
+	}
+}
+
+func TestCreateTableConstraints(t *testing.T) {
+	sql := `CREATE TABLE dbo.order_lines (
+		id INT CONSTRAINT pk_lines PRIMARY KEY,
+		order_id INT NOT NULL REFERENCES dbo.orders (id) ON DELETE CASCADE,
+		qty INT CONSTRAINT df_qty DEFAULT 1 CHECK (qty > 0),
+		price DECIMAL(10,2) DEFAULT 0 NOT NULL,
+		CONSTRAINT ck_price CHECK (price >= 0 AND price < 10000),
+		CONSTRAINT fk_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE SET NULL ON UPDATE NO ACTION
+	)`
+
+	stmt, ok := parseSQL(t, sql).(*parser.CreateTableStatement)
+	if !ok {
+		t.Fatalf("expected *parser.CreateTableStatement")
+	}
+	if len(stmt.Columns) != 4 || len(stmt.Constraints) != 2 {
+		t.Fatalf("expected 4 columns and 2 constraints, got %d and %d", len(stmt.Columns), len(stmt.Constraints))
+	}
+
+	pk := stmt.Columns[0].Constraints
+	if len(pk) != 1 || pk[0].Name != "pk_lines" || pk[0].Kind != "PRIMARY KEY" {
+		t.Errorf("unexpected id constraints %s", stmt.Columns[0].String())
+	}
+	ref := stmt.Columns[1].Constraints
+	if len(ref) != 1 || ref[0].Kind != "FOREIGN KEY" || ref[0].References.Name != "orders" || ref[0].OnDelete != "CASCADE" || !stmt.Columns[1].NotNull {
+		t.Errorf("unexpected order_id column %s", stmt.Columns[1].String())
+	}
+	qty := stmt.Columns[2].Constraints
+	if len(qty) != 2 || qty[0].Kind != "DEFAULT" || qty[0].Name != "df_qty" || qty[1].Kind != "CHECK" {
+		t.Fatalf("unexpected qty column %s", stmt.Columns[2].String())
+	}
+	if _, ok := qty[1].Expr.(*parser.BinaryExpression); !ok {
+		t.Errorf("expected binary CHECK condition, got %T", qty[1].Expr)
+	}
+	if stmt.Columns[3].Default == nil || !stmt.Columns[3].NotNull {
+		t.Errorf("unexpected price column %s", stmt.Columns[3].String())
+	}
+
+	check := stmt.Constraints[0]
+	if check.Kind != "CHECK" || check.Name != "ck_price" || check.Expr == nil {
+		t.Errorf("unexpected check constraint %s", check.String())
+	}
+	fk := stmt.Constraints[1]
+	if fk.OnDelete != "SET NULL" || fk.OnUpdate != "NO ACTION" {
+		t.Errorf("unexpected foreign key actions %s", fk.String())
+	}
+
+	want := "qty INT CONSTRAINT df_qty DEFAULT 1 CHECK (qty > 0)"
+	if got := stmt.Columns[2].String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	want = "order_id INT NOT NULL REFERENCES dbo.orders (id) ON DELETE CASCADE"
+	if got := stmt.Columns[1].String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if again := parseSQL(t, stmt.String()).String(); again != stmt.String() {
+		t.Errorf("round trip mismatch:\n%s\n%s", stmt.String(), again)
+	}
+
+	for _, sql := range []string{
+		"CREATE TABLE t (id INT CHECK id > 0)",
+		"CREATE TABLE t (id INT CHECK (id > 0)",
+		"CREATE TABLE t (id INT REFERENCES u (id) ON DELETE NOTHING)",
+		"CREATE TABLE t (id INT REFERENCES u (id) ON INSERT CASCADE)",
+		"CREATE TABLE t (id INT CONSTRAINT c)",
+		"CREATE TABLE t (id INT, CONSTRAINT c DEFAULT 0)",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected error for %q", sql)
+		}
+	}
+}
+
+func TestIdentityColumn(t *testing.T) {
+	tests := []struct {
+		sql       string
+		seed      int
+		increment int
+		notForRep bool
+		expected  string
+	}{
+		{"CREATE TABLE t (id INT IDENTITY)", 1, 1, false, "id INT IDENTITY(1,1)"},
+		{"CREATE TABLE t (id INT IDENTITY (100, 5) PRIMARY KEY)", 100, 5, false, "id INT IDENTITY(100,5) PRIMARY KEY"},
+		{"CREATE TABLE t (id INT IDENTITY(-1,-1))", -1, -1, false, "id INT IDENTITY(-1,-1)"},
+		{"CREATE TABLE t (id BIGINT IDENTITY(1,1) NOT FOR REPLICATION NOT NULL)", 1, 1, true, "id BIGINT NOT NULL IDENTITY(1,1) NOT FOR REPLICATION"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, ok := parseSQL(t, tt.sql).(*parser.CreateTableStatement)
+			if !ok {
+				t.Fatalf("expected *parser.CreateTableStatement")
+			}
+			column := stmt.Columns[0]
+			if column.Identity == nil {
+				t.Fatalf("expected identity on %s", column.String())
+			}
+			if column.Identity.Seed != tt.seed || column.Identity.Increment != tt.increment || column.Identity.NotForReplication != tt.notForRep {
+				t.Errorf("unexpected identity %+v", *column.Identity)
+			}
+			if got := column.String(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	for _, sql := range []string{
+		"CREATE TABLE t (id INT IDENTITY(1))",
+		"CREATE TABLE t (id INT IDENTITY(1, x))",
+		"CREATE TABLE t (id INT IDENTITY(1, 1) NOT FOR UPDATE)",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected error for %q", sql)
+		}
+	}
+}
+
+func TestCreateTableErrors(t *testing.T) {
+	for _, sql := range []string{
+		"CREATE TABLE t (id)",
+		"CREATE TABLE t (id INT NOT)",
+		"CREATE TABLE t (id INT, FOREIGN KEY (id) customers (id))",
+		"CREATE PROCEDURE p",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected error for %q", sql)
+		}
+	}
+}
+
+func TestDropStatement(t *testing.T) {
+	tests := []struct {
+		sql        string
+		objectType string
+		ifExists   bool
+		objects    []string
+		onTable    string
+	}{
+		{"DROP TABLE users", "TABLE", false, []string{"users"}, ""},
+		{"DROP TABLE IF EXISTS dbo.a, b, c", "TABLE", true, []string{"dbo.a", "b", "c"}, ""},
+		{"DROP VIEW IF EXISTS reporting.v_sales", "VIEW", true, []string{"reporting.v_sales"}, ""},
+		{"DROP INDEX ix_users_email ON dbo.users", "INDEX", false, []string{"ix_users_email"}, "dbo.users"},
+		{"DROP PROC dbo.usp_cleanup", "PROCEDURE", false, []string{"dbo.usp_cleanup"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, ok := parseSQL(t, tt.sql).(*parser.DropStatement)
+			if !ok {
+				t.Fatalf("expected *parser.DropStatement")
+			}
+			if stmt.ObjectType != tt.objectType || stmt.IfExists != tt.ifExists {
+				t.Errorf("expected %s (if exists %v), got %s (%v)", tt.objectType, tt.ifExists, stmt.ObjectType, stmt.IfExists)
+			}
+			if len(stmt.Objects) != len(tt.objects) {
+				t.Fatalf("expected %d objects, got %d", len(tt.objects), len(stmt.Objects))
+			}
+			for i, name := range tt.objects {
+				if got := stmt.Objects[i].String(); got != name {
+					t.Errorf("object %d: expected %s, got %s", i, name, got)
+				}
+			}
+			if tt.onTable != "" && (stmt.Table == nil || stmt.Table.String() != tt.onTable) {
+				t.Errorf("expected ON %s, got %v", tt.onTable, stmt.Table)
+			}
+		})
+	}
+}
+
+func TestPermissionStatement(t *testing.T) {
+	tests := []struct {
+		sql         string
+		action      string
+		permissions []string
+		class       string
+		object      string
+		principals  []string
+		want        string
+	}{
+		{
+			"GRANT SELECT ON dbo.t TO role", "GRANT", []string{"SELECT"}, "", "dbo.t", []string{"role"},
+			"GRANT SELECT ON dbo.t TO role",
+		},
+		{
+			"grant select, insert, update ON orders TO app_role, [report user] WITH GRANT OPTION", "GRANT", []string{"SELECT", "INSERT", "UPDATE"}, "", "orders", []string{"app_role", "report user"},
+			"GRANT SELECT, INSERT, UPDATE ON orders TO app_role, [report user] WITH GRANT OPTION",
+		},
+		{
+			"GRANT EXECUTE, VIEW DEFINITION ON SCHEMA::Sales TO analysts", "GRANT", []string{"EXECUTE", "VIEW DEFINITION"}, "SCHEMA", "Sales", []string{"analysts"},
+			"GRANT EXECUTE, VIEW DEFINITION ON SCHEMA::Sales TO analysts",
+		},
+		{
+			"GRANT CREATE TABLE, ALTER ANY USER TO deployer", "GRANT", []string{"CREATE TABLE", "ALTER ANY USER"}, "", "", []string{"deployer"},
+			"GRANT CREATE TABLE, ALTER ANY USER TO deployer",
+		},
+		{
+			"DENY DELETE ON OBJECT::dbo.audit TO public CASCADE", "DENY", []string{"DELETE"}, "OBJECT", "dbo.audit", []string{"public"},
+			"DENY DELETE ON OBJECT::dbo.audit TO public CASCADE",
+		},
+		{
+			"REVOKE GRANT OPTION FOR SELECT ON dbo.t FROM app_role CASCADE", "REVOKE", []string{"SELECT"}, "", "dbo.t", []string{"app_role"},
+			"REVOKE GRANT OPTION FOR SELECT ON dbo.t FROM app_role CASCADE",
+		},
+		{
+			"REVOKE ALL PRIVILEGES ON dbo.t TO app_role", "REVOKE", []string{"ALL PRIVILEGES"}, "", "dbo.t", []string{"app_role"},
+			"REVOKE ALL PRIVILEGES ON dbo.t FROM app_role",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, ok := parseSQL(t, tt.sql).(*parser.PermissionStatement)
+			if !ok {
+				t.Fatalf("expected *parser.PermissionStatement")
+			}
+			if stmt.Action != tt.action || strings.Join(stmt.Permissions, ",") != strings.Join(tt.permissions, ",") {
+				t.Errorf("expected %s %v, got %s %v", tt.action, tt.permissions, stmt.Action, stmt.Permissions)
+			}
+			object := ""
+			if stmt.Object != nil {
+				object = stmt.Object.QualifiedName()
+			}
+			if stmt.ObjectClass != tt.class || object != tt.object {
+				t.Errorf("expected ON %s::%s, got %s::%s", tt.class, tt.object, stmt.ObjectClass, object)
+			}
+			if strings.Join(stmt.Principals, ",") != strings.Join(tt.principals, ",") {
+				t.Errorf("expected principals %v, got %v", tt.principals, stmt.Principals)
+			}
+			if got := stmt.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	for _, sql := range []string{
+		"GRANT ON dbo.t TO role",
+		"GRANT SELECT ON dbo.t",
+		"GRANT SELECT ON dbo.t FROM role",
+		"DENY SELECT ON dbo.t TO role WITH GRANT OPTION",
+		"GRANT SELECT ON dbo.t TO role WITH OPTION",
+	} {
+		if _, err := parser.New(sql).ParseProgram(); err == nil {
+			t.Errorf("expected an error for %q", sql)
+		}
+	}
+}
+
+func TestGroupByGroupingConstructs(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{
+			"SELECT a, b, SUM(x) FROM t GROUP BY ROLLUP(a, b)",
+			"GROUP BY ROLLUP(a, b)",
+		},
+		{
+			"SELECT a, b, SUM(x) FROM t GROUP BY CUBE(a, (b, c))",
+			"GROUP BY CUBE(a, (b, c))",
+		},
+		{
+			"SELECT a, b, SUM(x) FROM t GROUP BY GROUPING SETS((a, b), (a), ())",
+			"GROUP BY GROUPING SETS((a, b), a, ())",
+		},
+		{
+			"SELECT a, b, SUM(x) FROM t GROUP BY region, ROLLUP(a, b)",
+			"GROUP BY region, ROLLUP(a, b)",
+		},
+		{
+			"SELECT a, b, SUM(x) FROM t GROUP BY GROUPING SETS(ROLLUP(a, b), c)",
+			"GROUP BY GROUPING SETS(ROLLUP(a, b), c)",
+		},
+		{
+			"SELECT a, b, SUM(x) FROM t GROUP BY a, b with rollup",
+			"GROUP BY a, b WITH ROLLUP",
+		},
+		{
+			"SELECT a, b, SUM(x) FROM t GROUP BY a, b WITH CUBE HAVING SUM(x) > 1",
+			"GROUP BY a, b WITH CUBE",
+		},
+		{
+			"SELECT a, SUM(x) FROM t WHERE x > 0 GROUP BY ALL a",
+			"GROUP BY ALL a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			if stmt.GroupBy == nil {
+				t.Fatal("expected GROUP BY clause")
+			}
+			if got := stmt.GroupBy.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLegacyGroupBy(t *testing.T) {
+	stmt := parseSQL(t, "SELECT a, SUM(x) FROM t GROUP BY a WITH ROLLUP").(*parser.SelectStatement)
+	if !stmt.GroupBy.WithRollup || stmt.GroupBy.WithCube || stmt.GroupBy.All {
+		t.Errorf("unexpected GROUP BY flags %+v", stmt.GroupBy)
+	}
+
+	stmt = parseSQL(t, "SELECT a, SUM(x) FROM t GROUP BY ALL a").(*parser.SelectStatement)
+	if !stmt.GroupBy.All || len(stmt.GroupBy.Items) != 1 {
+		t.Errorf("unexpected GROUP BY ALL %+v", stmt.GroupBy)
+	}
+
+	if _, err := parser.New("SELECT a FROM t GROUP BY ALL a WITH CUBE").ParseStatement(); err == nil {
+		t.Error("expected error for GROUP BY ALL with WITH CUBE")
+	}
+}
+
+func TestGroupingSetsStructure(t *testing.T) {
+	stmt := parseSQL(t, "SELECT a FROM t GROUP BY GROUPING SETS((a, b), ())").(*parser.SelectStatement)
+
+	sets, ok := stmt.GroupBy.Items[0].(*parser.GroupingSetsExpression)
+	if !ok {
+		t.Fatalf("expected *parser.GroupingSetsExpression, got %T", stmt.GroupBy.Items[0])
+	}
+	if len(sets.Sets) != 2 || len(sets.Sets[0]) != 2 || len(sets.Sets[1]) != 0 {
+		t.Errorf("unexpected grouping sets %s", sets.String())
+	}
+}
+
+func TestContainsAggregate(t *testing.T) {
+	tests := []struct {
+		sql   string
+		want  bool
+		names []string
+	}{
+		{"SELECT COUNT(*) FROM t", true, []string{"COUNT"}},
+		{"SELECT sum(x) + Max(y) / count(z) FROM t", true, []string{"SUM", "MAX", "COUNT"}},
+		{"SELECT CASE WHEN AVG(x) > 1 THEN MIN(y) END FROM t", true, []string{"AVG", "MIN"}},
+		{"SELECT UPPER(name) FROM t", false, nil},
+		{"SELECT SUM(x) OVER (PARTITION BY y) FROM t", false, nil},
+		{"SELECT SUM(SUM(x)) OVER () FROM t", true, []string{"SUM"}},
+		{"SELECT (SELECT MAX(id) FROM t) FROM u", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			expr := stmt.Columns[0]
+			if got := parser.ContainsAggregate(expr); got != tt.want {
+				t.Errorf("expected ContainsAggregate %v, got %v", tt.want, got)
+			}
+			if got := parser.AggregateFunctions(expr); strings.Join(got, ",") != strings.Join(tt.names, ",") {
+				t.Errorf("expected aggregates %v, got %v", tt.names, got)
+			}
+		})
+	}
+}
+
+func TestToConjunctiveList(t *testing.T) {
+	tests := []struct {
+		where string
+		want  []string
+	}{
+		{"a = 1", []string{"(a = 1)"}},
+		{"a = 1 AND b > 2 and c LIKE 'x%'", []string{"(a = 1)", "(b > 2)", "(c LIKE 'x%')"}},
+		{"(a = 1 AND b = 2) AND (c = 3 AND (d = 4 AND e = 5))", []string{"(a = 1)", "(b = 2)", "(c = 3)", "(d = 4)", "(e = 5)"}},
+		// OR branches and negations stay whole
+		{"a = 1 AND (b = 2 OR c = 3)", []string{"(a = 1)", "((b = 2) OR (c = 3))"}},
+		{"a = 1 OR b = 2 AND c = 3", []string{"((a = 1) OR ((b = 2) AND (c = 3)))"}},
+		{"NOT (a = 1 AND b = 2) AND c IS NULL", []string{"(NOT ((a = 1) AND (b = 2)))", "(c IS NULL)"}},
+		{"x BETWEEN 1 AND 5 AND y IN (1, 2)", []string{"(x BETWEEN 1 AND 5)", "(y IN (1, 2))"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.where, func(t *testing.T) {
+			stmt := parseSQL(t, "SELECT * FROM t WHERE "+tt.where).(*parser.SelectStatement)
+			var got []string
+			for _, predicate := range parser.ToConjunctiveList(stmt.Where) {
+				got = append(got, predicate.String())
+			}
+			if strings.Join(got, "; ") != strings.Join(tt.want, "; ") {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if parser.ToConjunctiveList(nil) != nil {
+		t.Error("expected no predicates for a nil condition")
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	sql := "SELECT u.name, COUNT(*)\nFROM users u\nWHERE u.name = 'Bob'"
+	stmt := parseSQL(t, sql).(*parser.SelectStatement)
+
+	source := func(node parser.Node) string {
+		start, end := node.Span()
+		return sql[start.Offset:end.Offset]
+	}
+
+	tests := []struct {
+		node parser.Node
+		want string
+	}{
+		{stmt, sql},
+		{stmt.Columns[0], "u.name"},
+		{stmt.Columns[1], "COUNT(*)"},
+		{stmt.From, "FROM users u"},
+		{&stmt.From.Tables[0], "users u"},
+		{stmt.Where, "u.name = 'Bob'"},
+		{stmt.Where.(*parser.BinaryExpression).Right, "'Bob'"},
+	}
+	for _, tt := range tests {
+		if got := source(tt.node); got != tt.want {
+			t.Errorf("%s: expected span %q, got %q", tt.node.Type(), tt.want, got)
+		}
+	}
+
+	start, end := stmt.Where.Span()
+	if start.Line != 3 || start.Column != 7 {
+		t.Errorf("expected WHERE condition to start at 3:7, got %s", start)
+	}
+	if end.Line != 3 || end.Column != 21 {
+		t.Errorf("expected WHERE condition to end at 3:21, got %s", end)
+	}
+}
+
+func TestErrorWithContext(t *testing.T) {
+	src := "SELECT id FROM users;\nSELECT FROM t"
+	_, err := parser.New(src).ParseProgram()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	want := "parse error at line 2, column 8: unexpected token in expression: FROM (near 'FROM')\n" +
+		"  2 | SELECT FROM t\n" +
+		"    |        ^^^^"
+	if got := parser.ErrorsWithContext(err, src); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+
+	// ParseStatement errors carry a position too; the carets count characters
+	src = "SELECT 'héllo', FROM t"
+	_, err = parser.New(src).ParseStatement()
+	want = "parse error at line 1, column 18: unexpected token in expression: FROM (near 'FROM')\n" +
+		"  1 | SELECT 'héllo', FROM t\n" +
+		"    |                 ^^^^"
+	if got := parser.ErrorsWithContext(err, src); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestLexicalErrors(t *testing.T) {
+	tests := []struct {
+		sql     string
+		message string
+		line    int
+		column  int
+	}{
+		{"SELECT name FROM users\nWHERE name = 'Smith", "unterminated string literal", 2, 14},
+		{"SELECT id FROM [Order Details", "unterminated bracketed identifier", 1, 16},
+		{"SELECT [] FROM t", "empty bracketed identifier", 1, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			p := parser.New(tt.sql)
+			_, err := p.ParseStatement()
+			var parseErr *parser.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected a *parser.ParseError, got %v", err)
+			}
+			if parseErr.Message != tt.message || parseErr.Line != tt.line || parseErr.Column != tt.column {
+				t.Errorf("expected %q at %d:%d, got %q at %d:%d", tt.message, tt.line, tt.column, parseErr.Message, parseErr.Line, parseErr.Column)
+			}
+
+			// Errors includes what the lexer reported
+			found := false
+			for _, msg := range p.Errors() {
+				found = found || strings.HasPrefix(msg, tt.message)
+			}
+			if !found {
+				t.Errorf("expected Errors to include %q, got %v", tt.message, p.Errors())
+			}
+		})
+	}
+
+	// ParseProgram reports the error once, at the start of the token
+	_, err := parser.New("SELECT 1;\nSELECT 'oops").ParseProgram()
+	want := "parse error at line 2, column 8: unterminated string literal (near 'oops')"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("expected %q, got %v", want, err)
+	}
+}
+
+func TestSyntaxErrorWithContext(t *testing.T) {
+	err := parser.NewSyntaxError("')'", "FROM", 1, 17)
+
+	want := "syntax error at line 1, column 17: expected ')', found FROM\n" +
+		"  1 | SELECT COUNT(id FROM t\n" +
+		"    |                 ^^^^"
+	if got := err.ErrorWithContext("SELECT COUNT(id FROM t"); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestLikeExpression(t *testing.T) {
+	tests := []struct {
+		sql    string
+		not    bool
+		escape string
+		want   string
+	}{
+		{"SELECT * FROM t WHERE name LIKE 'a%'", false, "", "(name LIKE 'a%')"},
+		{"SELECT * FROM t WHERE name NOT LIKE 'a%'", true, "", "(name NOT LIKE 'a%')"},
+		{"SELECT * FROM t WHERE rate LIKE '%50!%%' ESCAPE '!'", false, "!", "(rate LIKE '%50!%%' ESCAPE '!')"},
+		{"SELECT * FROM t WHERE code NOT LIKE 'x_%' ESCAPE 'x'", true, "x", "(code NOT LIKE 'x_%' ESCAPE 'x')"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			like, ok := stmt.Where.(*parser.LikeExpression)
+			if !ok {
+				t.Fatalf("expected *parser.LikeExpression, got %T", stmt.Where)
+			}
+			if like.Not != tt.not {
+				t.Errorf("expected Not %v, got %v", tt.not, like.Not)
+			}
+			if tt.escape == "" && like.Escape != nil {
+				t.Errorf("expected no escape, got %s", like.Escape)
+			}
+			if tt.escape != "" {
+				lit, ok := like.Escape.(*parser.Literal)
+				if !ok || lit.Value != tt.escape {
+					t.Errorf("expected escape %q, got %v", tt.escape, like.Escape)
+				}
+			}
+			if got := like.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLikeBindsTighterThanAnd(t *testing.T) {
+	stmt := parseSQL(t, "SELECT * FROM t WHERE a LIKE 'x' ESCAPE '!' AND b NOT LIKE 'y'").(*parser.SelectStatement)
+
+	want := "((a LIKE 'x' ESCAPE '!') AND (b NOT LIKE 'y'))"
+	if got := stmt.Where.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestFullTextPredicates(t *testing.T) {
+	tests := []struct {
+		sql      string
+		function string
+		columns  int
+		language bool
+		want     string
+	}{
+		{"SELECT * FROM docs WHERE CONTAINS(body, 'bike')", "CONTAINS", 1, false, "CONTAINS(body, 'bike')"},
+		{"SELECT * FROM docs WHERE freetext(d.body, @phrase)", "FREETEXT", 1, false, "FREETEXT(d.body, @phrase)"},
+		{"SELECT * FROM docs WHERE CONTAINS((title, body), '\"bike*\"')", "CONTAINS", 2, false, "CONTAINS((title, body), '\"bike*\"')"},
+		{"SELECT * FROM docs WHERE FREETEXT(*, 'red bike', LANGUAGE 1033)", "FREETEXT", 1, true, "FREETEXT(*, 'red bike', LANGUAGE 1033)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			pred, ok := stmt.Where.(*parser.FullTextPredicate)
+			if !ok {
+				t.Fatalf("expected *parser.FullTextPredicate, got %T", stmt.Where)
+			}
+			if pred.Function != tt.function {
+				t.Errorf("expected %s, got %s", tt.function, pred.Function)
+			}
+			if len(pred.Columns) != tt.columns {
+				t.Errorf("expected %d columns, got %d", tt.columns, len(pred.Columns))
+			}
+			if (pred.Language != nil) != tt.language {
+				t.Errorf("expected language %v, got %v", tt.language, pred.Language)
+			}
+			if got := pred.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	stmt := parseSQL(t, "SELECT * FROM docs WHERE CONTAINS(body, 'bike') AND id > 10").(*parser.SelectStatement)
+	want := "(CONTAINS(body, 'bike') AND (id > 10))"
+	if got := stmt.Where.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	for _, sql := range []string{
+		"SELECT * FROM docs WHERE CONTAINS(body)",
+		"SELECT * FROM docs WHERE CONTAINS((title, body 'bike')",
+		"SELECT * FROM docs WHERE FREETEXT(body, 'bike', 1033)",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected an error for %q", sql)
+		}
+	}
+}
+
+func TestTableHints(t *testing.T) {
+	tests := []struct {
+		sql   string
+		hints []string
+		want  string
+	}{
+		{
+			"SELECT * FROM orders WITH (NOLOCK)",
+			[]string{"NOLOCK"},
+			"orders WITH (NOLOCK)",
+		},
+		{
+			"SELECT * FROM dbo.orders o WITH (nolock, INDEX(ix_orders_date), FORCESEEK)",
+			[]string{"NOLOCK", "INDEX(ix_orders_date)", "FORCESEEK"},
+			"dbo.orders AS o WITH (NOLOCK, INDEX(ix_orders_date), FORCESEEK)",
+		},
+		{
+			"SELECT * FROM orders AS o WITH (FORCESEEK(ix_orders(customer_id, order_date)))",
+			[]string{"FORCESEEK(ix_orders(customer_id, order_date))"},
+			"orders AS o WITH (FORCESEEK(ix_orders(customer_id, order_date)))",
+		},
+		{
+			"SELECT * FROM orders WITH (INDEX = ix_orders_date)",
+			[]string{"INDEX = ix_orders_date"},
+			"orders WITH (INDEX = ix_orders_date)",
+		},
+		{
+			"SELECT * FROM orders WITH (INDEX([ix by date], 2), INDEX = [select])",
+			[]string{"INDEX([ix by date], 2)", "INDEX = [select]"},
+			"orders WITH (INDEX([ix by date], 2), INDEX = [select])",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			table := &stmt.From.Tables[0]
+			if strings.Join(table.Hints, "|") != strings.Join(tt.hints, "|") {
+				t.Errorf("expected hints %v, got %v", tt.hints, table.Hints)
+			}
+			if got := table.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	for _, sql := range []string{
+		"SELECT * FROM orders WITH (INDEX(ix_a ix_b))",
+		"SELECT * FROM orders WITH (INDEX(1 2))",
+		"SELECT * FROM orders WITH (INDEX(ix_a,))",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected an error for %q", sql)
+		}
+	}
+}
+
+func TestWhereCurrentOf(t *testing.T) {
+	tests := []struct {
+		sql    string
+		cursor string
+		global bool
+	}{
+		{"UPDATE employees SET salary = salary * 1.1 WHERE CURRENT OF emp_cursor", "emp_cursor", false},
+		{"DELETE FROM employees WHERE CURRENT OF GLOBAL emp_cursor", "emp_cursor", true},
+		{"DELETE FROM employees WHERE current of @cur", "@cur", false},
+		{"DELETE FROM employees WHERE CURRENT OF global", "global", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			var where parser.Expression
+			switch stmt := parseSQL(t, tt.sql).(type) {
+			case *parser.UpdateStatement:
+				where = stmt.Where
+			case *parser.DeleteStatement:
+				where = stmt.Where
+			}
+			clause, ok := where.(*parser.CurrentOfClause)
+			if !ok {
+				t.Fatalf("expected *parser.CurrentOfClause, got %T", where)
+			}
+			if clause.Cursor != tt.cursor || clause.Global != tt.global {
+				t.Errorf("expected cursor %s (global %v), got %s (global %v)", tt.cursor, tt.global, clause.Cursor, clause.Global)
+			}
+		})
+	}
+
+	stmt := parseSQL(t, "DELETE FROM employees WHERE CURRENT OF GLOBAL emp_cursor")
+	want := "DELETE FROM employees WHERE CURRENT OF GLOBAL emp_cursor"
+	if got := stmt.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	for _, sql := range []string{
+		"DELETE FROM employees WHERE CURRENT OF",
+		"DELETE FROM employees WHERE CURRENT OF GLOBAL @cur",
+		"UPDATE employees SET x = 1 WHERE CURRENT OF 1",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected an error for %q", sql)
+		}
+	}
+}
+
+func TestTableHintsOnJoinAndUpdate(t *testing.T) {
+	stmt := parseSQL(t, "SELECT o.id FROM orders o WITH (NOLOCK) JOIN customers c WITH (NOLOCK) ON c.id = o.customer_id").(*parser.SelectStatement)
+	if len(stmt.Joins) != 1 || len(stmt.Joins[0].Table.Hints) != 1 {
+		t.Fatalf("expected hints on the joined table, got %s", stmt.String())
+	}
+
+	update := parseSQL(t, "UPDATE orders WITH (ROWLOCK) SET status = 1 WHERE id = 2").(*parser.UpdateStatement)
+	if len(update.Table.Hints) != 1 || update.Table.Hints[0] != "ROWLOCK" {
+		t.Errorf("expected ROWLOCK hint, got %v", update.Table.Hints)
+	}
+}
+
+func TestMergeStatement(t *testing.T) {
+	sql := `MERGE INTO dbo.customers AS t
+		USING (SELECT id, name FROM staging) AS s
+		ON t.id = s.id
+		WHEN MATCHED AND t.name != s.name THEN UPDATE SET name = s.name, updated = 1
+		WHEN NOT MATCHED BY TARGET THEN INSERT (id, name) VALUES (s.id, s.name)
+		WHEN NOT MATCHED BY SOURCE THEN DELETE;`
+
+	stmt, ok := parseSQL(t, sql).(*parser.MergeStatement)
+	if !ok {
+		t.Fatalf("expected *parser.MergeStatement, got %T", parseSQL(t, sql))
+	}
+	if stmt.Target.Name != "customers" || stmt.Target.Alias != "t" {
+		t.Errorf("unexpected target %s", stmt.Target.String())
+	}
+	if stmt.Source.Subquery == nil || stmt.Source.Alias != "s" {
+		t.Errorf("expected derived table source, got %s", stmt.Source.String())
+	}
+	if len(stmt.Clauses) != 3 {
+		t.Fatalf("expected 3 WHEN clauses, got %d", len(stmt.Clauses))
+	}
+
+	matched := stmt.Clauses[0]
+	if !matched.Matched || matched.Action != "UPDATE" || len(matched.Set) != 2 || matched.Condition == nil {
+		t.Errorf("unexpected WHEN MATCHED clause %s", matched.String())
+	}
+	insert := stmt.Clauses[1]
+	if insert.Matched || insert.BySource || insert.Action != "INSERT" || len(insert.Columns) != 2 || len(insert.Values) != 2 {
+		t.Errorf("unexpected WHEN NOT MATCHED clause %s", insert.String())
+	}
+	bySource := stmt.Clauses[2]
+	if bySource.Matched || !bySource.BySource || bySource.Action != "DELETE" {
+		t.Errorf("unexpected WHEN NOT MATCHED BY SOURCE clause %s", bySource.String())
+	}
+
+	want := "MERGE INTO dbo.customers AS t USING (SELECT id, name FROM staging) AS s ON (t.id = s.id) " +
+		"WHEN MATCHED AND (t.name != s.name) THEN UPDATE SET name = s.name, updated = 1 " +
+		"WHEN NOT MATCHED THEN INSERT (id, name) VALUES (s.id, s.name) " +
+		"WHEN NOT MATCHED BY SOURCE THEN DELETE"
+	if got := stmt.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestMergeStatementErrors(t *testing.T) {
+	tests := []string{
+		"MERGE t USING s ON t.id = s.id",
+		"MERGE t USING s ON t.id = s.id WHEN NOT MATCHED THEN DELETE",
+		"MERGE t USING s ON t.id = s.id WHEN MATCHED THEN INSERT (id) VALUES (1)",
+		"MERGE t s ON t.id = s.id WHEN MATCHED THEN DELETE",
+	}
+
+	for _, sql := range tests {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected an error for %q", sql)
+		}
+	}
+}
+
+func TestModuloAndConcatenation(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT id FROM t WHERE a % b = 0", "((a % b) = 0)"},
+		{"SELECT id FROM t WHERE a + b % 2 * c = 1", "((a + ((b % 2) * c)) = 1)"},
+		{"SELECT first + ' ' + last FROM people", "((first + ' ') + last)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			expr := stmt.Where
+			if expr == nil {
+				expr = stmt.Columns[0]
+			}
+			if got := expr.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBitwiseOperators(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT id FROM t WHERE permissions & 4 = 4", "((permissions & 4) = 4)"},
+		{"SELECT id FROM t WHERE a | b ^ c = 0", "(((a | b) ^ c) = 0)"},
+		{"SELECT id FROM t WHERE a & b + 1 = 0", "((a & (b + 1)) = 0)"},
+		{"SELECT id FROM t WHERE ~flags & 1 = 0", "(((~flags) & 1) = 0)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			if got := stmt.Where.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScientificAndLeadingDotNumbers(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want float64
+	}{
+		{"SELECT id FROM t WHERE x = 1E3", 1000},
+		{"SELECT id FROM t WHERE x = 2.5e-4", 0.00025},
+		{"SELECT id FROM t WHERE x = .25", 0.25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			cmp, ok := stmt.Where.(*parser.BinaryExpression)
+			if !ok {
+				t.Fatalf("expected BinaryExpression, got %T", stmt.Where)
+			}
+			lit, ok := cmp.Right.(*parser.Literal)
+			if !ok {
+				t.Fatalf("expected Literal, got %T", cmp.Right)
+			}
+			if lit.Value != tt.want {
+				t.Errorf("expected %v, got %v (%T)", tt.want, lit.Value, lit.Value)
+			}
+		})
+	}
+
+	// A leading minus is a unary operator applied to the literal
+	stmt := parseSQL(t, "SELECT id FROM t WHERE x > -1.5e2").(*parser.SelectStatement)
+	if got, want := stmt.Where.String(), "(x > (-150.0))"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestHexLiterals(t *testing.T) {
+	tests := []struct {
+		sql        string
+		wantBytes  []byte
+		wantString string
+	}{
+		{"SELECT id FROM t WHERE v = 0x1A2B", []byte{0x1A, 0x2B}, "0x1A2B"},
+		{"SELECT id FROM t WHERE v = 0xabc", []byte{0x0A, 0xBC}, "0x0ABC"},
+		{"SELECT id FROM t WHERE v = 0x", []byte{}, "0x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			lit, ok := stmt.Where.(*parser.BinaryExpression).Right.(*parser.Literal)
+			if !ok {
+				t.Fatalf("expected Literal, got %T", stmt.Where.(*parser.BinaryExpression).Right)
+			}
+			got, ok := lit.Value.([]byte)
+			if !ok || !bytes.Equal(got, tt.wantBytes) {
+				t.Errorf("expected %#v, got %#v", tt.wantBytes, lit.Value)
+			}
+			if lit.String() != tt.wantString {
+				t.Errorf("expected %q, got %q", tt.wantString, lit.String())
+			}
+		})
+	}
+}
+
+func TestParserReset(t *testing.T) {
+	p := parser.New("SELECT FROM")
+	if _, err := p.ParseStatement(); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	p.Reset("SELECT a FROM t\nWHERE b = ? AND c = ?")
+	stmt, err := p.ParseStatement()
+	if err != nil {
+		t.Fatalf("unexpected error after reset: %v", err)
+	}
+	if len(p.Errors()) != 0 {
+		t.Errorf("expected errors to be cleared, got %v", p.Errors())
+	}
+
+	var ordinals []int
+	parser.Inspect(stmt, func(node parser.Node) bool {
+		if param, ok := node.(*parser.Parameter); ok {
+			ordinals = append(ordinals, param.Position)
+		}
+		return true
+	})
+	if len(ordinals) != 2 || ordinals[0] != 1 || ordinals[1] != 2 {
+		t.Errorf("expected placeholders numbered from 1, got %v", ordinals)
+	}
+	where := stmt.(*parser.SelectStatement).Where
+	if pos, _ := where.Span(); pos.Line != 2 || pos.Column != 7 {
+		t.Errorf("expected WHERE condition at 2:7, got %s", pos)
+	}
+}
+
+func TestMultiPartTableNames(t *testing.T) {
+	tests := []struct {
+		sql                             string
+		server, database, schema, table string
+		want                            string
+	}{
+		{"SELECT a FROM users", "", "", "", "users", "users"},
+		{"SELECT a FROM dbo.users", "", "", "dbo", "users", "dbo.users"},
+		{"SELECT a FROM sales.dbo.users", "", "sales", "dbo", "users", "sales.dbo.users"},
+		{"SELECT a FROM srv1.sales.dbo.users", "srv1", "sales", "dbo", "users", "srv1.sales.dbo.users"},
+		{"SELECT a FROM sales..users", "", "sales", "", "users", "sales..users"},
+		{"SELECT a FROM srv1.sales..users AS u", "srv1", "sales", "", "users", "srv1.sales..users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			table := stmt.From.Tables[0]
+			if table.Server != tt.server || table.Database != tt.database || table.Schema != tt.schema || table.Name != tt.table {
+				t.Errorf("unexpected parts %q %q %q %q", table.Server, table.Database, table.Schema, table.Name)
+			}
+			if got := table.QualifiedName(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	for _, sql := range []string{"SELECT a FROM a.b.c.d.e", "SELECT a FROM db.."} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected an error for %q", sql)
+		}
+	}
+}
+
+func TestTempTablesAndTableVariables(t *testing.T) {
+	tests := []struct {
+		sql  string
+		kind parser.TableKind
+		name string
+		want string
+	}{
+		{"SELECT * FROM #temp", parser.TableLocalTemp, "#temp", "SELECT * FROM #temp"},
+		{"SELECT * FROM ##global_1 g", parser.TableGlobalTemp, "##global_1", "SELECT * FROM ##global_1 AS g"},
+		{"SELECT r.id FROM @results AS r", parser.TableVariable, "@results", "SELECT r.id FROM @results AS r"},
+		{"SELECT * FROM tempdb..#temp", parser.TableLocalTemp, "#temp", "SELECT * FROM tempdb..#temp"},
+		{"SELECT * FROM users", "", "users", "SELECT * FROM users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			table := stmt.From.Tables[0]
+			if table.Kind != tt.kind || table.Name != tt.name {
+				t.Errorf("expected %s %q, got %s %q", tt.kind, tt.name, table.Kind, table.Name)
+			}
+			if got := stmt.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	// Temp tables and table variables appear wherever a table name does
+	for _, sql := range []string{
+		"SELECT u.id FROM users u JOIN #ids i ON i.id = u.id",
+		"INSERT INTO #temp (id) VALUES (1)",
+		"INSERT INTO @results (id) SELECT id FROM users",
+		"UPDATE #temp SET name = 'x' WHERE #temp.id = 1",
+		"DELETE FROM ##global WHERE id = 1",
+		"CREATE TABLE #temp (id INT)",
+		"DROP TABLE #temp",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err != nil {
+			t.Errorf("%s: %v", sql, err)
+		}
+	}
+
+	// @@ names are system functions, not table variables
+	if _, err := parser.New("SELECT * FROM @@ROWCOUNT").ParseStatement(); err == nil {
+		t.Error("expected an error for @@ROWCOUNT as a table")
+	}
+}
+
+func TestCollectErrors(t *testing.T) {
+	tests := []struct {
+		sql    string
+		errors []string // tokens the errors are reported at
+	}{
+		{"SELECT a, FROM t WHERE a = = 1 GROUP BY ORDER BY a", []string{"FROM", "=", "ORDER"}},
+		{"SELECT a FROM t JOIN ON x = 1 WHERE (b =) AND c = 1", []string{"ON", ")"}},
+		// A subquery recovers up to its closing parenthesis
+		{"SELECT a FROM (SELECT FROM s WHERE) AS d WHERE a IN (1,", []string{"FROM", ")", ""}},
+		{"SELECT a FROM t WHERE a = 1", nil},
+		// OFFSET ... FETCH is still checked after an error in ORDER BY
+		{"SELECT a FROM t ORDER BY a, OFFSET 5 ROWS FETCH NEXT x ROWS ONLY", []string{"OFFSET", "x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			p := parser.New(tt.sql)
+			p.SetCollectErrors(true)
+			stmt, err := p.ParseStatement()
+
+			var got []string
+			if err != nil {
+				errs := []error{err}
+				if joined, ok := err.(interface{ Unwrap() []error }); ok {
+					errs = joined.Unwrap()
+				}
+				for _, e := range errs {
+					var parseErr *parser.ParseError
+					if !errors.As(e, &parseErr) {
+						t.Fatalf("expected *ParseError, got %T", e)
+					}
+					got = append(got, parseErr.Token)
+				}
+			}
+			if strings.Join(got, " ") != strings.Join(tt.errors, " ") {
+				t.Errorf("expected errors at %q, got %q (%v)", tt.errors, got, err)
+			}
+			if stmt == nil {
+				t.Error("expected the partial statement")
+			}
+		})
+	}
+
+	// The rest of the statement is still parsed
+	p := parser.New("SELECT a, FROM t WHERE a = = 1 ORDER BY a DESC")
+	p.SetCollectErrors(true)
+	stmt, _ := p.ParseStatement()
+	if s := stmt.(*parser.SelectStatement); s.From == nil || len(s.OrderBy) != 1 {
+		t.Errorf("expected FROM and ORDER BY to be parsed, got %s", s)
+	}
+
+	p = parser.New("SELECT a FROM t ORDER BY a, OFFSET 5 ROWS FETCH NEXT 2 ROWS ONLY")
+	p.SetCollectErrors(true)
+	stmt, _ = p.ParseStatement()
+	if s := stmt.(*parser.SelectStatement); s.Offset == nil || s.Offset.Offset != 5 || s.Offset.Fetch != 2 {
+		t.Errorf("expected OFFSET and FETCH to be parsed, got %s", s)
+	}
+
+	// ParseProgram reports every error and goes on with the next statement
+	p = parser.New("SELECT a, FROM t WHERE = 1; SELECT b FROM u")
+	p.SetCollectErrors(true)
+	program, err := p.ParseProgram()
+	if err == nil || len(program.Statements) != 1 || len(p.Errors()) != 2 {
+		t.Errorf("expected 2 errors and 1 statement, got %d errors and %d statements: %v", len(p.Errors()), len(program.Statements), err)
+	}
+
+	// Without the option only the first error is reported
+	p = parser.New("SELECT a, FROM t WHERE = 1")
+	if _, err := p.ParseStatement(); err == nil {
+		t.Error("expected an error")
+	} else if _, ok := err.(interface{ Unwrap() []error }); ok {
+		t.Errorf("expected a single error, got %v", err)
+	}
+}
+
+func TestStringLiteralEscapes(t *testing.T) {
+	stmt := parseSQL(t, "SELECT name FROM users WHERE last = 'O''Brien' AND note = '' AND q = 'DELETE FROM x'").(*parser.SelectStatement)
+
+	var values []interface{}
+	parser.Inspect(stmt.Where, func(node parser.Node) bool {
+		if lit, ok := node.(*parser.Literal); ok {
+			values = append(values, lit.Value)
+		}
+		return true
+	})
+	if !reflect.DeepEqual(values, []interface{}{"O'Brien", "", "DELETE FROM x"}) {
+		t.Errorf("unexpected values %q", values)
+	}
+
+	// String re-escapes embedded quotes
+	want := "SELECT name FROM users WHERE (((last = 'O''Brien') AND (note = '')) AND (q = 'DELETE FROM x'))"
+	if got := stmt.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if !parser.Equal(stmt, parseSQL(t, stmt.String())) {
+		t.Error("reparsing gave a different tree")
+	}
+}
+
+func TestFromlessSelect(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT 1 + 1", "SELECT (1 + 1)"},
+		{"SELECT GETDATE()", "SELECT GETDATE()"},
+		{"SELECT @@VERSION", "SELECT @@VERSION"},
+		{"SELECT @@ROWCOUNT AS affected, @@SPID, @id", "SELECT @@ROWCOUNT AS affected, @@SPID, @id"},
+		{"SELECT CASE WHEN @@TRANCOUNT > 0 THEN 'open' ELSE 'none' END", "SELECT CASE WHEN (@@TRANCOUNT > 0) THEN 'open' ELSE 'none' END"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			if stmt.From != nil {
+				t.Errorf("expected no FROM clause")
+			}
+			if got := stmt.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	stmt := parseSQL(t, "SELECT @@ROWCOUNT AS affected").(*parser.SelectStatement)
+	aliased := stmt.Columns[0].(*parser.AliasedExpression)
+	if variable, ok := aliased.Expression.(*parser.SystemVariable); !ok || variable.Name != "@@ROWCOUNT" {
+		t.Errorf("expected SystemVariable @@ROWCOUNT, got %#v", aliased.Expression)
+	}
+
+	if _, err := parser.New("SELECT @@").ParseStatement(); err == nil {
+		t.Error("expected an error for @@ without a name")
+	}
+}
+
+func TestOutputClause(t *testing.T) {
+	tests := []struct {
+		sql         string
+		columns     int
+		into        string
+		intoColumns []string
+		want        string
+	}{
+		{
+			"INSERT INTO users (name) OUTPUT inserted.id, inserted.name INTO @ids (id, name) VALUES ('a')",
+			2, "@ids", []string{"id", "name"},
+			"INSERT INTO users (name) OUTPUT inserted.id, inserted.name INTO @ids (id, name) VALUES ('a')",
+		},
+		{
+			"INSERT INTO archive (id) OUTPUT inserted.id SELECT id FROM users",
+			1, "", nil,
+			"INSERT INTO archive (id) OUTPUT inserted.id SELECT id FROM users",
+		},
+		{
+			"UPDATE users SET name = 'x' OUTPUT deleted.name AS old_name, inserted.name AS new_name INTO #audit FROM users u JOIN roles r ON r.id = u.role_id WHERE r.name = 'admin'",
+			2, "#audit", nil,
+			"UPDATE users SET name = 'x' OUTPUT deleted.name AS old_name, inserted.name AS new_name INTO #audit FROM users AS u INNER JOIN roles AS r ON (r.id = u.role_id) WHERE (r.name = 'admin')",
+		},
+		{
+			"DELETE FROM sessions OUTPUT deleted.* WHERE expires < GETDATE()",
+			1, "", nil,
+			"DELETE FROM sessions OUTPUT deleted.* WHERE (expires < GETDATE())",
+		},
+		{
+			"DELETE TOP (100) FROM queue OUTPUT deleted.id, deleted.payload INTO dbo.processed",
+			2, "dbo.processed", nil,
+			"DELETE TOP (100) FROM queue OUTPUT deleted.id, deleted.payload INTO dbo.processed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql)
+
+			var output *parser.OutputClause
+			switch s := stmt.(type) {
+			case *parser.InsertStatement:
+				output = s.Output
+			case *parser.UpdateStatement:
+				output = s.Output
+			case *parser.DeleteStatement:
+				output = s.Output
+			}
+			if output == nil {
+				t.Fatal("expected an OUTPUT clause")
+			}
+			if len(output.Columns) != tt.columns {
+				t.Errorf("expected %d columns, got %d", tt.columns, len(output.Columns))
+			}
+			into := ""
+			if output.Into != nil {
+				into = output.Into.QualifiedName()
+			}
+			if into != tt.into || strings.Join(output.IntoColumns, ",") != strings.Join(tt.intoColumns, ",") {
+				t.Errorf("expected INTO %s %v, got %s %v", tt.into, tt.intoColumns, into, output.IntoColumns)
+			}
+			if got := stmt.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	// OUTPUT is not taken as an alias of the DELETE target
+	stmt := parseSQL(t, "DELETE FROM t OUTPUT deleted.id").(*parser.DeleteStatement)
+	if stmt.From.Alias != "" || stmt.Output == nil {
+		t.Errorf("expected OUTPUT clause, got alias %q", stmt.From.Alias)
+	}
+}
+
+func TestSelectInto(t *testing.T) {
+	tests := []struct {
+		sql  string
+		into string
+		kind parser.TableKind
+		want string
+	}{
+		{"SELECT id, name INTO #active FROM users WHERE active = 1", "#active", parser.TableLocalTemp, "SELECT id, name INTO #active FROM users WHERE (active = 1)"},
+		{"SELECT * INTO archive.dbo.orders_2020 FROM orders", "archive.dbo.orders_2020", "", "SELECT * INTO archive.dbo.orders_2020 FROM orders"},
+		{"SELECT TOP 10 id INTO ##top FROM t ORDER BY id OFFSET 5 ROWS", "##top", parser.TableGlobalTemp, "SELECT TOP 10 id INTO ##top FROM t ORDER BY id ASC OFFSET 5 ROWS"},
+		{"SELECT 1 AS n INTO t", "t", "", "SELECT 1 AS n INTO t"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			if stmt.Into == nil {
+				t.Fatal("expected an INTO target")
+			}
+			if got := stmt.Into.QualifiedName(); got != tt.into || stmt.Into.Kind != tt.kind {
+				t.Errorf("expected INTO %s (%q), got %s (%q)", tt.into, tt.kind, got, stmt.Into.Kind)
+			}
+			if got := stmt.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	// INTO is only valid between the select list and FROM
+	for _, sql := range []string{
+		"SELECT id FROM users INTO #t",
+		"SELECT id FROM users WHERE id = 1 INTO #t",
+		"SELECT id INTO FROM users",
+	} {
+		if _, err := parser.New(sql).ParseProgram(); err == nil {
+			t.Errorf("expected an error for %q", sql)
+		}
+	}
+}
+
+func TestTableSample(t *testing.T) {
+	tests := []struct {
+		sql        string
+		system     bool
+		size       string
+		unit       string
+		repeatable string
+		want       string
+	}{
+		{"SELECT * FROM bigtable TABLESAMPLE (10 PERCENT)", false, "10", "PERCENT", "", "SELECT * FROM bigtable TABLESAMPLE (10 PERCENT)"},
+		{"SELECT * FROM bigtable TABLESAMPLE SYSTEM (1000 ROWS) REPEATABLE (123)", true, "1000", "ROWS", "123", "SELECT * FROM bigtable TABLESAMPLE SYSTEM (1000 ROWS) REPEATABLE (123)"},
+		{"SELECT b.id FROM dbo.bigtable AS b tablesample (2.5 percent) WITH (NOLOCK)", false, "2.5", "PERCENT", "", "SELECT b.id FROM dbo.bigtable AS b TABLESAMPLE (2.5 PERCENT) WITH (NOLOCK)"},
+		{"SELECT * FROM bigtable b TABLESAMPLE (@n ROWS) JOIN other o ON o.id = b.id", false, "@n", "ROWS", "", "SELECT * FROM bigtable AS b TABLESAMPLE (@n ROWS) INNER JOIN other AS o ON (o.id = b.id)"},
+		{"SELECT * FROM bigtable TABLESAMPLE (10)", false, "10", "", "", "SELECT * FROM bigtable TABLESAMPLE (10)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			table := stmt.From.Tables[0]
+			sample := table.TableSample
+			if sample == nil {
+				t.Fatalf("expected TABLESAMPLE, got alias %q", table.Alias)
+			}
+			repeatable := ""
+			if sample.Repeatable != nil {
+				repeatable = sample.Repeatable.String()
+			}
+			if sample.System != tt.system || sample.Size.String() != tt.size || sample.Unit != tt.unit || repeatable != tt.repeatable {
+				t.Errorf("unexpected sample %+v", sample)
+			}
+			if got := stmt.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	for _, sql := range []string{
+		"SELECT * FROM t TABLESAMPLE 10 PERCENT",
+		"SELECT * FROM t TABLESAMPLE (10 PERCENT",
+		"SELECT * FROM t TABLESAMPLE (10 PERCENT) REPEATABLE 5",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected an error for %q", sql)
+		}
+	}
+}
+
+func TestPivotAndUnpivot(t *testing.T) {
+	sql := "SELECT p.product, p.[Jan], p.[Feb] FROM (SELECT product, month, amt FROM sales) AS s PIVOT (SUM(amt) FOR month IN ([Jan], [Feb], [2019])) AS p"
+	stmt := parseSQL(t, sql).(*parser.SelectStatement)
+
+	source := stmt.From.Tables[0]
+	pivot := source.Pivot
+	if source.Alias != "s" || pivot == nil {
+		t.Fatalf("expected PIVOT on derived table s, got alias %q", source.Alias)
+	}
+	if pivot.Aggregate.String() != "SUM(amt)" || pivot.Column.String() != "month" || pivot.Alias != "p" {
+		t.Errorf("unexpected pivot %s", pivot)
+	}
+	if strings.Join(pivot.Values, ",") != "Jan,Feb,2019" {
+		t.Errorf("unexpected pivot values %v", pivot.Values)
+	}
+	want := "SELECT p.product, p.Jan, p.Feb FROM (SELECT product, month, amt FROM sales) AS s PIVOT (SUM(amt) FOR month IN (Jan, Feb, [2019])) AS p"
+	if got := stmt.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	sql = "SELECT u.product, u.month, u.amt FROM monthly m UNPIVOT (amt FOR month IN (Jan, Feb, Mar)) u WHERE u.amt > 0"
+	stmt = parseSQL(t, sql).(*parser.SelectStatement)
+	unpivot := stmt.From.Tables[0].Unpivot
+	if unpivot == nil {
+		t.Fatal("expected UNPIVOT")
+	}
+	if unpivot.ValueColumn != "amt" || unpivot.NameColumn != "month" || strings.Join(unpivot.Columns, ",") != "Jan,Feb,Mar" || unpivot.Alias != "u" {
+		t.Errorf("unexpected unpivot %+v", unpivot)
+	}
+	want = "SELECT u.product, u.month, u.amt FROM monthly AS m UNPIVOT (amt FOR month IN (Jan, Feb, Mar)) AS u WHERE (u.amt > 0)"
+	if got := stmt.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	// Without an alias on the source, PIVOT is not taken as its alias
+	stmt = parseSQL(t, "SELECT * FROM sales PIVOT (MAX(amt) FOR s.month IN ([Jan])) AS p JOIN x ON x.id = p.id").(*parser.SelectStatement)
+	if stmt.From.Tables[0].Alias != "" || stmt.From.Tables[0].Pivot == nil || len(stmt.Joins) != 1 {
+		t.Errorf("unexpected parse %s", stmt)
+	}
+
+	for _, sql := range []string{
+		"SELECT * FROM sales PIVOT (SUM(amt) FOR month IN ([Jan]))",
+		"SELECT * FROM sales PIVOT (amt FOR month IN ([Jan])) AS p",
+		"SELECT * FROM sales PIVOT (SUM(amt) month IN ([Jan])) AS p",
+		"SELECT * FROM sales UNPIVOT (amt FOR month IN ()) AS u",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected an error for %q", sql)
+		}
+	}
+}
+
+func TestOrderByCollationAndNulls(t *testing.T) {
+	tests := []struct {
+		sql       string
+		collation string
+		direction string
+		nulls     string
+		want      string
+	}{
+		{"SELECT name FROM t ORDER BY name COLLATE Latin1_General_CI_AS DESC", "Latin1_General_CI_AS", "DESC", "", "name COLLATE Latin1_General_CI_AS DESC"},
+		{"SELECT name FROM t ORDER BY name COLLATE SQL_Latin1_General_CP1_CS_AS", "SQL_Latin1_General_CP1_CS_AS", "ASC", "", "name COLLATE SQL_Latin1_General_CP1_CS_AS ASC"},
+		{"SELECT name FROM t ORDER BY score DESC NULLS LAST", "", "DESC", "LAST", "score DESC NULLS LAST"},
+		{"SELECT name FROM t ORDER BY name COLLATE Finnish_Swedish_CI_AS nulls first", "Finnish_Swedish_CI_AS", "ASC", "FIRST", "name COLLATE Finnish_Swedish_CI_AS ASC NULLS FIRST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			item := stmt.OrderBy[0]
+			if item.Collation != tt.collation || item.Direction != tt.direction || item.Nulls != tt.nulls {
+				t.Errorf("unexpected item: collation=%q direction=%q nulls=%q", item.Collation, item.Direction, item.Nulls)
+			}
+			if got := item.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := parser.New("SELECT a FROM t ORDER BY a NULLS MIDDLE").ParseStatement(); err == nil {
+		t.Error("expected an error for NULLS MIDDLE")
+	}
+}
+
+func TestCollateExpression(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT * FROM t WHERE name COLLATE Latin1_General_CS_AS = 'Smith'", "(name COLLATE Latin1_General_CS_AS = 'Smith')"},
+		{"SELECT * FROM t WHERE name = 'Smith' COLLATE Latin1_General_CS_AS", "(name = 'Smith' COLLATE Latin1_General_CS_AS)"},
+		{"SELECT * FROM t WHERE first + last COLLATE Latin1_General_BIN LIKE 'a%'", "((first + last COLLATE Latin1_General_BIN) LIKE 'a%')"},
+		{"SELECT * FROM t WHERE (first + last) COLLATE Latin1_General_BIN LIKE 'a%'", "((first + last) COLLATE Latin1_General_BIN LIKE 'a%')"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseSQL(t, tt.sql).(*parser.SelectStatement)
+			if got := stmt.Where.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	stmt := parseSQL(t, "SELECT * FROM t WHERE name COLLATE Latin1_General_CS_AS = 'Smith'").(*parser.SelectStatement)
+	cond := stmt.Where.(*parser.BinaryExpression)
+	collate, ok := cond.Left.(*parser.CollateExpression)
+	if !ok {
+		t.Fatalf("expected *parser.CollateExpression on the left, got %T", cond.Left)
+	}
+	if col, ok := collate.Expr.(*parser.ColumnReference); !ok || col.Column != "name" || collate.Collation != "Latin1_General_CS_AS" {
+		t.Errorf("unexpected collate expression %s", collate)
+	}
+
+	if _, err := parser.New("SELECT * FROM t WHERE name COLLATE = 'x'").ParseStatement(); err == nil {
+		t.Error("expected an error for a missing collation name")
+	}
+}
+
+func TestOrdinalReferences(t *testing.T) {
+	stmt := parseSQL(t, "SELECT dept, COUNT(*) FROM emp GROUP BY 1 ORDER BY 2 DESC, dept, 1.5").(*parser.SelectStatement)
+
+	group, ok := stmt.GroupBy.Items[0].(*parser.OrdinalReference)
+	if !ok || group.Index != 1 {
+		t.Fatalf("expected GROUP BY ordinal 1, got %#v", stmt.GroupBy.Items[0])
+	}
+	if got := group.Resolve(stmt).String(); got != "dept" {
+		t.Errorf("expected ordinal 1 to resolve to dept, got %s", got)
+	}
+
+	order, ok := stmt.OrderBy[0].Expression.(*parser.OrdinalReference)
+	if !ok || order.Index != 2 {
+		t.Fatalf("expected ORDER BY ordinal 2, got %#v", stmt.OrderBy[0].Expression)
+	}
+	if got := order.Resolve(stmt).String(); got != "COUNT(*)" {
+		t.Errorf("expected ordinal 2 to resolve to COUNT(*), got %s", got)
+	}
+	if pos, end := order.Span(); pos.Column != 52 || end.Column != 53 {
+		t.Errorf("expected ordinal span 52-53, got %s-%s", pos, end)
+	}
+
+	// Only bare integers are ordinals
+	if _, ok := stmt.OrderBy[1].Expression.(*parser.ColumnReference); !ok {
+		t.Errorf("expected column reference, got %T", stmt.OrderBy[1].Expression)
+	}
+	if _, ok := stmt.OrderBy[2].Expression.(*parser.Literal); !ok {
+		t.Errorf("expected literal, got %T", stmt.OrderBy[2].Expression)
+	}
+	if (&parser.OrdinalReference{Index: 3}).Resolve(stmt) != nil {
+		t.Error("expected out-of-range ordinal to resolve to nil")
+	}
+
+	// Window ORDER BY keeps literals as-is
+	stmt = parseSQL(t, "SELECT ROW_NUMBER() OVER (ORDER BY 1) FROM t").(*parser.SelectStatement)
+	fn := stmt.Columns[0].(*parser.FunctionCall)
+	if _, ok := fn.Over.OrderBy[0].Expression.(*parser.Literal); !ok {
+		t.Errorf("expected literal in window ORDER BY, got %T", fn.Over.OrderBy[0].Expression)
+	}
+}
+
+func TestCreateViewStatement(t *testing.T) {
+	tests := []struct {
+		sql           string
+		orAlter       bool
+		view          string
+		columns       []string
+		schemaBinding bool
+		want          string
+	}{
+		{
+			sql:  "CREATE VIEW dbo.active_users AS SELECT id, name FROM users WHERE active = 1",
+			view: "dbo.active_users",
+			want: "CREATE VIEW dbo.active_users AS SELECT id, name FROM users WHERE (active = 1)",
+		},
+		{
+			sql:           "CREATE OR ALTER VIEW sales.totals (customer, total) WITH SCHEMABINDING AS SELECT customer_id, SUM(amount) FROM dbo.orders GROUP BY customer_id",
+			orAlter:       true,
+			view:          "sales.totals",
+			columns:       []string{"customer", "total"},
+			schemaBinding: true,
+			want:          "CREATE OR ALTER VIEW sales.totals (customer, total) WITH SCHEMABINDING AS SELECT customer_id, SUM(amount) FROM dbo.orders GROUP BY customer_id",
+		},
+		{
+			sql:  "CREATE VIEW everyone AS SELECT name FROM staff UNION SELECT name FROM contractors",
+			view: "everyone",
+			want: "CREATE VIEW everyone AS SELECT name FROM staff UNION SELECT name FROM contractors",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, ok := parseSQL(t, tt.sql).(*parser.CreateViewStatement)
+			if !ok {
+				t.Fatalf("expected CreateViewStatement")
+			}
+			if stmt.OrAlter != tt.orAlter || stmt.SchemaBinding != tt.schemaBinding {
+				t.Errorf("unexpected flags: or alter=%v schemabinding=%v", stmt.OrAlter, stmt.SchemaBinding)
+			}
+			if got := stmt.View.QualifiedName(); got != tt.view {
+				t.Errorf("expected view %q, got %q", tt.view, got)
+			}
+			if strings.Join(stmt.Columns, ",") != strings.Join(tt.columns, ",") {
+				t.Errorf("expected columns %v, got %v", tt.columns, stmt.Columns)
+			}
+			if got := stmt.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	for _, sql := range []string{
+		"CREATE VIEW v SELECT 1",
+		"CREATE VIEW v AS DELETE FROM t",
+		"CREATE VIEW v WITH ENCRYPTION AS SELECT 1",
+		"CREATE OR REPLACE VIEW v AS SELECT 1",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected an error for %q", sql)
+		}
+	}
+}
+
+func TestCreateIndexStatement(t *testing.T) {
+	sql := "CREATE UNIQUE NONCLUSTERED INDEX ix_orders_customer ON sales.orders (customer_id, order_date DESC) INCLUDE (total, status) WHERE status = 'open'"
+
+	stmt, ok := parseSQL(t, sql).(*parser.CreateIndexStatement)
+	if !ok {
+		t.Fatalf("expected CreateIndexStatement")
+	}
+	if !stmt.Unique || stmt.Clustered != "NONCLUSTERED" || stmt.Name != "ix_orders_customer" {
+		t.Errorf("unexpected header: unique=%v clustered=%q name=%q", stmt.Unique, stmt.Clustered, stmt.Name)
+	}
+	if stmt.Table.QualifiedName() != "sales.orders" {
+		t.Errorf("unexpected table %q", stmt.Table.QualifiedName())
+	}
+	if len(stmt.Columns) != 2 || stmt.Columns[0].String() != "customer_id ASC" || stmt.Columns[1].String() != "order_date DESC" {
+		t.Errorf("unexpected key columns %v", stmt.Columns)
+	}
+	if strings.Join(stmt.Include, ",") != "total,status" {
+		t.Errorf("unexpected included columns %v", stmt.Include)
+	}
+	if stmt.Where == nil || stmt.Where.String() != "(status = 'open')" {
+		t.Errorf("unexpected filter %v", stmt.Where)
+	}
+
+	want := "CREATE UNIQUE NONCLUSTERED INDEX ix_orders_customer ON sales.orders (customer_id ASC, order_date DESC) INCLUDE (total, status) WHERE (status = 'open')"
+	if got := stmt.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	simple, ok := parseSQL(t, "CREATE INDEX ix_name ON users (name)").(*parser.CreateIndexStatement)
+	if !ok || simple.Unique || simple.Clustered != "" || simple.Include != nil || simple.Where != nil {
+		t.Errorf("unexpected simple index %#v", simple)
+	}
+
+	for _, sql := range []string{
+		"CREATE INDEX ON users (name)",
+		"CREATE INDEX ix ON users",
+		"CREATE UNIQUE TABLE t (id INT)",
+		"CREATE INDEX ix ON users (name) INCLUDE name",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected an error for %q", sql)
+		}
+	}
+}
+
+func TestAlterTableStatement(t *testing.T) {
+	tests := []struct {
+		sql    string
+		action string
+		want   string
+	}{
+		{"ALTER TABLE dbo.users ADD email VARCHAR(255) NOT NULL", "ADD COLUMN", "ALTER TABLE dbo.users ADD email VARCHAR(255) NOT NULL"},
+		{"ALTER TABLE users ADD COLUMN age INT DEFAULT 0", "ADD COLUMN", "ALTER TABLE users ADD age INT DEFAULT 0"},
+		{"ALTER TABLE users DROP COLUMN age", "DROP COLUMN", "ALTER TABLE users DROP COLUMN age"},
+		{"ALTER TABLE users ALTER COLUMN name NVARCHAR(100) NULL", "ALTER COLUMN", "ALTER TABLE users ALTER COLUMN name NVARCHAR(100) NULL"},
+		{"ALTER TABLE orders ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)", "ADD CONSTRAINT", "ALTER TABLE orders ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)"},
+		{"ALTER TABLE orders ADD PRIMARY KEY (id)", "ADD CONSTRAINT", "ALTER TABLE orders ADD PRIMARY KEY (id)"},
+		{"ALTER TABLE orders DROP CONSTRAINT fk_user", "DROP CONSTRAINT", "ALTER TABLE orders DROP CONSTRAINT fk_user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, ok := parseSQL(t, tt.sql).(*parser.AlterTableStatement)
+			if !ok {
+				t.Fatalf("expected AlterTableStatement")
+			}
+			if stmt.Action != tt.action {
+				t.Errorf("expected action %q, got %q", tt.action, stmt.Action)
+			}
+			if got := stmt.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	for _, sql := range []string{
+		"ALTER VIEW v AS SELECT 1",
+		"ALTER TABLE users RENAME TO people",
+		"ALTER TABLE users DROP age",
+		"ALTER TABLE users ALTER name INT",
+	} {
+		if _, err := parser.New(sql).ParseStatement(); err == nil {
+			t.Errorf("expected an error for %q", sql)
+		}
+	}
+}
+
+func TestParseBatches(t *testing.T) {
+	sql := `CREATE TABLE t (id INT);
+GO
+INSERT INTO t (id) VALUES (1);
+INSERT INTO t (id) VALUES (2);
+GO 5
+SELECT FROM
+GO
+SELECT id FROM t
+`
+
+	batches, err := parser.New(sql).ParseBatches()
+	if err == nil || !strings.Contains(err.Error(), "line 6") {
+		t.Errorf("expected an error for the third batch, got %v", err)
+	}
+
+	wantCounts := []int{1, 5, 1, 1}
+	wantStatements := []int{1, 2, 0, 1}
+	if len(batches) != len(wantCounts) {
+		t.Fatalf("expected %d batches, got %d", len(wantCounts), len(batches))
+	}
+	for i, batch := range batches {
+		if batch.Count != wantCounts[i] || len(batch.Statements) != wantStatements[i] {
+			t.Errorf("batch %d: expected count %d with %d statements, got count %d with %d", i, wantCounts[i], wantStatements[i], batch.Count, len(batch.Statements))
+		}
+	}
+
+	// ParseProgram treats GO as a statement separator
+	program, err := parser.New("SELECT 1\nGO\nSELECT 2").ParseProgram()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(program.Statements) != 2 {
+		t.Errorf("expected 2 statements, got %d", len(program.Statements))
+	}
+}
@@ -2,7 +2,9 @@ package tests
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"runtime"
 	"strings"
 	"testing"
@@ -57,6 +59,7 @@ func BenchmarkParser(b *testing.B) {
 			  GROUP BY u.id, u.name, u.email 
 			  ORDER BY order_count DESC`
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		p := parser.New(input)
@@ -67,6 +70,29 @@ func BenchmarkParser(b *testing.B) {
 	}
 }
 
+// BenchmarkParserReset reuses one parser across iterations; compare its
+// allocations with BenchmarkParser, which creates a new parser each time
+func BenchmarkParserReset(b *testing.B) {
+	input := `SELECT u.name, u.email, COUNT(o.id) as order_count 
+			  FROM users u 
+			  LEFT JOIN orders o ON u.id = o.user_id 
+			  WHERE u.status = 'active' 
+			  GROUP BY u.id, u.name, u.email 
+			  ORDER BY order_count DESC`
+
+	p := parser.New(input)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Reset(input)
+		stmt, err := p.ParseStatement()
+		if err != nil {
+			b.Fatal(err)
+		}
+		parser.PutSelectStatement(stmt.(*parser.SelectStatement))
+	}
+}
+
 func BenchmarkAnalyzer(b *testing.B) {
 	input := `SELECT u.name, u.email, COUNT(o.id) as order_count 
 			  FROM users u 
@@ -228,6 +254,81 @@ func TestParserTimeout(t *testing.T) {
 	}
 }
 
+func TestParseWithTimeout(t *testing.T) {
+	stmt, err := parser.ParseWithTimeout("SELECT id FROM users", time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := stmt.(*parser.SelectStatement); !ok {
+		t.Errorf("expected *parser.SelectStatement, got %T", stmt)
+	}
+}
+
+func TestParserTimeoutReturnsCancelledError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	p := parser.NewWithContext(ctx, "SELECT a, b, c FROM t WHERE a = 1 AND b IN (1, 2, 3)")
+	_, err := p.ParseStatement()
+
+	var cancelled *parser.CancelledError
+	if !errors.As(err, &cancelled) {
+		t.Fatalf("expected *parser.CancelledError, got %T: %v", err, err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error to wrap context.DeadlineExceeded, got %v", err)
+	}
+	// Cancellation is recorded once rather than for every remaining token
+	if n := len(p.Errors()); n != 1 {
+		t.Errorf("expected 1 parser error, got %d: %v", n, p.Errors())
+	}
+
+	_, err = p.ParseProgram()
+	if !errors.As(err, &cancelled) {
+		t.Errorf("expected ParseProgram to report *parser.CancelledError, got %v", err)
+	}
+}
+
+// countdownContext reports cancellation once it has been polled n times
+type countdownContext struct {
+	context.Context
+	n int
+}
+
+func (c *countdownContext) Err() error {
+	if c.n--; c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestParserCancelledMidParse(t *testing.T) {
+	var builder strings.Builder
+	builder.WriteString("SELECT ")
+	for i := 0; i < 500; i++ {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		fmt.Fprintf(&builder, "col%d + %d", i, i)
+	}
+	builder.WriteString(" FROM t WHERE a = 1")
+
+	ctx := &countdownContext{Context: context.Background(), n: 100}
+	p := parser.NewWithContext(ctx, builder.String())
+
+	stmt, err := p.ParseStatement()
+	if stmt != nil {
+		t.Errorf("expected no statement, got %T", stmt)
+	}
+	if !errors.Is(err, parser.ErrParseCancelled) {
+		t.Fatalf("expected ErrParseCancelled, got %v", err)
+	}
+	if errs := p.Errors(); len(errs) != 1 || errs[0] != err.Error() {
+		t.Errorf("expected only the cancellation error, got %v", errs)
+	}
+}
+
 func TestLargeQuery(t *testing.T) {
 	// Generate a large query
 	var builder strings.Builder
@@ -286,3 +387,61 @@ func TestLargeQuery(t *testing.T) {
 		t.Errorf("Expected 20 tables, got %d", len(analysis.Tables))
 	}
 }
+
+// BenchmarkStatementIterator compares the heap retained while parsing a large
+// script with ParseProgram against processing it one statement at a time
+func BenchmarkStatementIterator(b *testing.B) {
+	script := strings.Repeat("SELECT u.id, u.name FROM users u JOIN orders o ON u.id = o.user_id WHERE o.total > 100;\n", 5000)
+
+	liveHeap := func(parse func() interface{}) uint64 {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		result := parse()
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		runtime.KeepAlive(result)
+		if after.HeapAlloc < before.HeapAlloc {
+			return 0
+		}
+		return after.HeapAlloc - before.HeapAlloc
+	}
+
+	b.Run("ParseProgram", func(b *testing.B) {
+		b.ReportAllocs()
+		var live uint64
+		for i := 0; i < b.N; i++ {
+			live += liveHeap(func() interface{} {
+				program, err := parser.New(script).ParseProgram()
+				if err != nil {
+					b.Fatal(err)
+				}
+				return program
+			})
+		}
+		b.ReportMetric(float64(live)/float64(b.N), "live-B/op")
+	})
+
+	b.Run("Iterator", func(b *testing.B) {
+		b.ReportAllocs()
+		var live uint64
+		for i := 0; i < b.N; i++ {
+			live += liveHeap(func() interface{} {
+				it := parser.New(script).Statements()
+				var last parser.Statement
+				for {
+					stmt, err := it.Next()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						b.Fatal(err)
+					}
+					last = stmt
+				}
+				return last
+			})
+		}
+		b.ReportMetric(float64(live)/float64(b.N), "live-B/op")
+	})
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Chahine-tech/sql-parser-go/pkg/parser"
+)
+
+// columnCollector is a Visitor that records every column reference it sees
+type columnCollector struct {
+	columns []string
+}
+
+func (c *columnCollector) Visit(node parser.Node) parser.Visitor {
+	if col, ok := node.(*parser.ColumnReference); ok {
+		c.columns = append(c.columns, col.String())
+	}
+	return c
+}
+
+func ExampleWalk() {
+	stmt, _ := parser.New("SELECT u.name FROM users u WHERE u.id IN (SELECT o.user_id FROM orders o WHERE o.total > 10)").ParseStatement()
+
+	collector := &columnCollector{}
+	parser.Walk(stmt, collector)
+
+	fmt.Println(strings.Join(collector.columns, ", "))
+	// Output: u.name, u.id, o.user_id, o.total
+}
+
+func TestWalkVisitsAllStatementParts(t *testing.T) {
+	sql := `WITH recent AS (SELECT user_id FROM orders WHERE created > @since)
+		UPDATE users SET score = CASE WHEN a.bonus IS NULL THEN 0 ELSE a.bonus END
+		FROM users JOIN awards a ON a.user_id = users.id
+		WHERE EXISTS (SELECT 1 FROM recent r WHERE r.user_id = users.id) AND users.age BETWEEN 18 AND 65`
+
+	collector := &columnCollector{}
+	parser.Walk(parseSQL(t, sql), collector)
+
+	want := "user_id, created, score, a.bonus, a.bonus, a.user_id, users.id, r.user_id, users.id, users.age"
+	if got := strings.Join(collector.columns, ", "); got != want {
+		t.Errorf("expected columns %q, got %q", want, got)
+	}
+}
+
+func TestInspectSkipsChildren(t *testing.T) {
+	stmt := parseSQL(t, "SELECT id FROM users WHERE id IN (SELECT user_id FROM orders)")
+
+	var tables []string
+	parser.Inspect(stmt, func(node parser.Node) bool {
+		if _, ok := node.(*parser.InExpression); ok {
+			return false // don't descend into the subquery
+		}
+		if table, ok := node.(*parser.TableReference); ok {
+			tables = append(tables, table.Name)
+		}
+		return true
+	})
+
+	if len(tables) != 1 || tables[0] != "users" {
+		t.Errorf("expected only users table, got %v", tables)
+	}
+}
+
+func ExampleToDOT() {
+	stmt, _ := parser.New("SELECT name FROM users WHERE id = 1").ParseStatement()
+	fmt.Print(parser.ToDOT(stmt))
+	// Output:
+	// digraph AST {
+	//   node [shape=box, fontname="Helvetica"];
+	//   n0 [label="SelectStatement"];
+	//   n1 [label="ColumnReference\nColumn: name"];
+	//   n0 -> n1;
+	//   n2 [label="FromClause"];
+	//   n0 -> n2;
+	//   n3 [label="TableReference\nName: users"];
+	//   n2 -> n3;
+	//   n4 [label="BinaryExpression\nOperator: ="];
+	//   n0 -> n4;
+	//   n5 [label="ColumnReference\nColumn: id"];
+	//   n4 -> n5;
+	//   n6 [label="Literal\n1"];
+	//   n4 -> n6;
+	// }
+}
+
+func TestToDOT(t *testing.T) {
+	stmt := parseSQL(t, `SELECT u.name AS "full name" FROM users u WHERE u.name LIKE 'a\b%'`)
+	dot := parser.ToDOT(stmt)
+
+	// One DOT node per AST node, each but the root linked to its parent
+	nodes := 0
+	parser.Inspect(stmt, func(node parser.Node) bool {
+		if node != nil {
+			nodes++
+		}
+		return true
+	})
+	if got := strings.Count(dot, "[label="); got != nodes {
+		t.Errorf("expected %d nodes, got %d", nodes, got)
+	}
+	if got := strings.Count(dot, " -> "); got != nodes-1 {
+		t.Errorf("expected %d edges, got %d", nodes-1, got)
+	}
+
+	for _, want := range []string{
+		`[label="AliasedExpression\nAlias: full name"]`,
+		`[label="ColumnReference\nTable: u\nColumn: name"]`,
+		`[label="TableReference\nName: users\nAlias: u"]`,
+		`[label="LikeExpression"]`,
+		`[label="Literal\n'a\\b%'"]`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("expected %s in:\n%s", want, dot)
+		}
+	}
+
+	if got := parser.ToDOT(nil); got != "digraph AST {\n  node [shape=box, fontname=\"Helvetica\"];\n}\n" {
+		t.Errorf("unexpected output for nil: %q", got)
+	}
+}
+
+func TestExtractParameters(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected []string
+	}{
+		{"SELECT * FROM users WHERE id = ? AND status = ?", []string{"?1", "?2"}},
+		{"SELECT * FROM users WHERE name = @name OR nick = @Name AND age > @minAge", []string{"@name", "@minAge"}},
+		{"UPDATE users SET name = @name, score = ? WHERE id = @id AND @name IS NOT NULL", []string{"@name", "?1", "@id"}},
+		{"WITH recent AS (SELECT id FROM orders WHERE created > @since) SELECT id FROM recent WHERE id > @n", []string{"@since", "@n"}},
+		{"SELECT 1", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			var got []string
+			for _, param := range parser.ExtractParameters(parseSQL(t, tt.sql)) {
+				if param.Name != "" {
+					got = append(got, param.Name)
+				} else {
+					got = append(got, fmt.Sprintf("?%d", param.Position))
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}